@@ -20,13 +20,20 @@ type StatusResponse struct {
 	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
-// OverviewResponse is returned on an overview request.
+// OverviewResponse is returned on an overview request. The counts are of the tasks and
+// checklists in the active workspace, which is named if one is selected; assigned is
+// the number of open tasks that are assigned to the user and views are the number of
+// tasks that match each of the saved views of the user by name.
 type OverviewResponse struct {
-	Success    bool   `json:"success"`
-	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
-	User       string `json:"user"`
-	Tasks      int    `json:"tasks"`
-	Checklists int    `json:"checklists"`
+	Success    bool           `json:"success"`
+	Error      string         `json:"error,omitempty" yaml:"error,omitempty"`
+	User       string         `json:"user"`
+	Tasks      int            `json:"tasks"`
+	Assigned   int            `json:"assigned"`
+	Workspace  string         `json:"workspace,omitempty"`
+	Checklists int            `json:"checklists"`
+	OpenEffort uint           `json:"open_effort"`
+	Views      map[string]int `json:"views,omitempty" yaml:"views,omitempty"`
 }
 
 //===========================================================================
@@ -48,11 +55,13 @@ type RegisterResponse struct {
 	Username string `json:"username"`
 }
 
-// LoginRequest to authenticate a user with the service and return tokens
+// LoginRequest to authenticate a user with the service and return tokens. If a
+// workspace is specified, the tokens select it as the active workspace.
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	NoCookie bool   `json:"no_cookie"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Workspace uint   `json:"workspace,omitempty"`
+	NoCookie  bool   `json:"no_cookie"`
 }
 
 // LoginResponse is returned on a successful login
@@ -79,20 +88,50 @@ type RefreshRequest struct {
 // Tasks RESTful API
 //===========================================================================
 
-// ListTasksRequest fetches tasks with specific filters.
+// ListTasksRequest fetches tasks with specific filters. If tags are specified, only
+// tasks that are labeled with all of the named tags are returned. Tasks that are
+// deferred until a future start date are only returned if deferred is true. If
+// assigned is true, only the tasks assigned to the user are returned. Fields filter the
+// tasks by the values of their custom fields, each specified as name=value. If
+// completed or archived are specified, only the tasks with that status are returned.
+// Overdue tasks are incomplete tasks whose deadline has passed. The before and after
+// filters are exclusive RFC3339 timestamps and title matches tasks whose title contains
+// the text, ignoring case. Sort orders the tasks by one or more of deadline, created,
+// updated, title, priority, or position; keys prefixed with - are sorted descending.
+// Tasks are paginated either by page number or by the cursor returned with the
+// previous page.
 type ListTasksRequest struct {
-	Checklist uint `json:"checklist,omitempty"`
-	Page      int  `json:"page,omitempty"`
-	PerPage   int  `json:"per_page,omitempty"`
+	Checklist      uint       `json:"checklist,omitempty" form:"checklist"`
+	Tags           []string   `json:"tags,omitempty" form:"tag"`
+	Deferred       bool       `json:"deferred,omitempty" form:"deferred"`
+	Assigned       bool       `json:"assigned,omitempty" form:"assigned"`
+	Fields         []string   `json:"fields,omitempty" form:"field"`
+	Completed      *bool      `json:"completed,omitempty" form:"completed"`
+	Archived       *bool      `json:"archived,omitempty" form:"archived"`
+	Overdue        bool       `json:"overdue,omitempty" form:"overdue"`
+	DeadlineBefore *time.Time `json:"deadline_before,omitempty" form:"deadline_before"`
+	DeadlineAfter  *time.Time `json:"deadline_after,omitempty" form:"deadline_after"`
+	CreatedBefore  *time.Time `json:"created_before,omitempty" form:"created_before"`
+	CreatedAfter   *time.Time `json:"created_after,omitempty" form:"created_after"`
+	UpdatedBefore  *time.Time `json:"updated_before,omitempty" form:"updated_before"`
+	UpdatedAfter   *time.Time `json:"updated_after,omitempty" form:"updated_after"`
+	Title          string     `json:"title,omitempty" form:"title"`
+	Sort           []string   `json:"sort,omitempty" form:"sort"`
+	Page           int        `json:"page,omitempty" form:"page"`
+	PerPage        int        `json:"per_page,omitempty" form:"per_page"`
+	Cursor         string     `json:"cursor,omitempty" form:"cursor"`
 }
 
-// ListTasksResponse returns the tasks, and response info such as pagination.
+// ListTasksResponse returns the tasks, and response info such as pagination. The page
+// and number of pages are only returned when paginating by page number; the next cursor
+// is returned if there are more tasks after this page.
 type ListTasksResponse struct {
-	Success  bool   `json:"success"`
-	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
-	Tasks    []Task `json:"tasks,omitempty"`
-	Page     int    `json:"page,omitempty"`
-	NumPages int    `json:"num_pages,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+	Tasks      []Task `json:"tasks,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	NumPages   int    `json:"num_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
 }
 
 // CreateTaskResponse returns the information about the created task. Currently the
@@ -111,11 +150,18 @@ type DetailTaskResponse struct {
 	Task    Task   `json:"task"`
 }
 
-// UpdateTaskResponse returns information about the update call. Currently there is no
-// UpdateTaskRequest, because it is simply the task object itself.
+// UpdateTaskRequest is a JSON merge patch (RFC 7396) of the task. Only the fields in the
+// request are modified and null clears the value of a field, e.g. the deadline or the
+// checklist of the task. Fields that cannot be modified are rejected, however the
+// read-only fields of a task are ignored so that a task can be sent back as a patch.
+type UpdateTaskRequest map[string]interface{}
+
+// UpdateTaskResponse returns information about the update call. If completing the task
+// created the next occurrence of a repeating task, its id is returned.
 type UpdateTaskResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+	NextTaskID uint   `json:"next_task,omitempty"`
 }
 
 // DeleteTaskResponse returns information about the delete call. Currently there is no
@@ -125,23 +171,142 @@ type DeleteTaskResponse struct {
 	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// ListDependenciesResponse returns the tasks that block the task specified in the URL.
+type ListDependenciesResponse struct {
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+	Blockers []Task `json:"blockers,omitempty"`
+}
+
+// CreateDependencyRequest specifies the task that blocks the task in the URL.
+type CreateDependencyRequest struct {
+	BlockerID uint `json:"blocker" binding:"required"`
+}
+
+// CreateDependencyResponse returns information about the create dependency call.
+type CreateDependencyResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteDependencyResponse returns information about the delete dependency call.
+// Currently there is no DeleteDependencyRequest, because the request is in the URL.
+type DeleteDependencyResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ListCommentsResponse returns the comments on the task specified in the URL. Currently
+// there is no ListCommentsRequest, the request is in the URL.
+type ListCommentsResponse struct {
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Comments []Comment `json:"comments"`
+}
+
+// CreateCommentResponse returns the information about the created comment. Currently the
+// CreateCommentRequest is simply the comment object itself.
+type CreateCommentResponse struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+	CommentID uint   `json:"comment,omitempty"`
+}
+
+// UpdateCommentResponse returns information about the update comment call. Currently
+// there is no UpdateCommentRequest, because it is simply the comment object itself.
+type UpdateCommentResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteCommentResponse returns information about the delete comment call. Currently
+// there is no DeleteCommentRequest, because the request is in the URL.
+type DeleteCommentResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ListAttachmentsResponse returns the files attached to the task specified in the URL.
+// Currently there is no ListAttachmentsRequest, the request is in the URL.
+type ListAttachmentsResponse struct {
+	Success     bool         `json:"success"`
+	Error       string       `json:"error,omitempty" yaml:"error,omitempty"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// CreateAttachmentResponse returns the metadata of the uploaded file. The request is a
+// multipart form with the contents of the file in the "file" field.
+type CreateAttachmentResponse struct {
+	Success    bool       `json:"success"`
+	Error      string     `json:"error,omitempty" yaml:"error,omitempty"`
+	Attachment Attachment `json:"attachment"`
+}
+
+// DeleteAttachmentResponse returns information about the delete attachment call.
+// Currently there is no DeleteAttachmentRequest, because the request is in the URL.
+type DeleteAttachmentResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// HistoryResponse returns the history of changes to the task or checklist specified in
+// the URL, oldest first. Currently there is no HistoryRequest, the request is in the URL.
+type HistoryResponse struct {
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty" yaml:"error,omitempty"`
+	History []History `json:"history"`
+}
+
+// MoveTaskRequest places the task in the URL immediately before or after another task
+// in the same checklist. Exactly one of before or after must be specified.
+type MoveTaskRequest struct {
+	Before uint `json:"before,omitempty"`
+	After  uint `json:"after,omitempty"`
+}
+
+// MoveTaskResponse returns the new position of the task in its checklist.
+type MoveTaskResponse struct {
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+	Position int64  `json:"position"`
+}
+
+// SnoozeTaskRequest defers the task in the URL by a duration such as 3d, 1w, or 4h.
+type SnoozeTaskRequest struct {
+	For string `json:"for" binding:"required"`
+}
+
+// SnoozeTaskResponse returns the new start date of the snoozed task.
+type SnoozeTaskResponse struct {
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty" yaml:"error,omitempty"`
+	StartAt time.Time `json:"start"`
+}
+
 //===========================================================================
 // Checklist RESTful API
 //===========================================================================
 
-// ListChecklistsRequest fetches checklists with specific filters.
+// ListChecklistsRequest fetches checklists with specific filters. If a project is
+// specified, only the checklists in the project are returned. Checklists are paginated
+// either by page number or by the cursor returned with the previous page.
 type ListChecklistsRequest struct {
-	Page    int `json:"page,omitempty"`
-	PerPage int `json:"per_page,omitempty"`
+	Project uint   `json:"project,omitempty" form:"project"`
+	Page    int    `json:"page,omitempty" form:"page"`
+	PerPage int    `json:"per_page,omitempty" form:"per_page"`
+	Cursor  string `json:"cursor,omitempty" form:"cursor"`
 }
 
 // ListChecklistsResponse returns the checklists, and response info such as pagination.
+// The page and number of pages are only returned when paginating by page number; the
+// next cursor is returned if there are more checklists after this page.
 type ListChecklistsResponse struct {
 	Success    bool        `json:"success"`
 	Error      string      `json:"error,omitempty" yaml:"error,omitempty"`
 	Checklists []Checklist `json:"checklists,omitempty"`
 	Page       int         `json:"page,omitempty"`
 	NumPages   int         `json:"num_pages,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty" yaml:"next_cursor,omitempty"`
 }
 
 // CreateChecklistResponse returns the information about the created checklist.
@@ -160,16 +325,450 @@ type DetailChecklistResponse struct {
 	Checklist Checklist `json:"checklist"`
 }
 
-// UpdateChecklistResponse returns information about the update call. Currently there is
-// no UpdateChecklistRequest, because it is simply the checklist object itself.
+// UpdateChecklistRequest is a JSON merge patch (RFC 7396) of the checklist with the same
+// semantics as the UpdateTaskRequest.
+type UpdateChecklistRequest map[string]interface{}
+
+// UpdateChecklistResponse returns information about the update call.
 type UpdateChecklistResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// ReorderChecklistRequest specifies the complete order of the tasks in the checklist in
+// the URL. It must contain the id of every task in the checklist exactly once.
+type ReorderChecklistRequest struct {
+	Tasks []uint `json:"tasks" binding:"required"`
+}
+
+// ReorderChecklistResponse returns information about the reorder checklist call.
+type ReorderChecklistResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
 // DeleteChecklistResponse returns information about the delete call. Currently there is
 // no DeleteChecklistRequest, because the request is in the URL.
 type DeleteChecklistResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
 }
+
+//===========================================================================
+// Checklist Sharing RESTful API
+//===========================================================================
+
+// ListSharesResponse returns the users the checklist in the URL is shared with.
+type ListSharesResponse struct {
+	Success bool             `json:"success"`
+	Error   string           `json:"error,omitempty" yaml:"error,omitempty"`
+	Shares  []ChecklistShare `json:"shares"`
+}
+
+// ShareChecklistRequest shares the checklist in the URL with the user as a viewer or an
+// editor, replacing their current role if the checklist is already shared with them.
+type ShareChecklistRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// ShareChecklistResponse returns information about the share call.
+type ShareChecklistResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// UnshareChecklistResponse returns information about the unshare call. Currently there
+// is no UnshareChecklistRequest, because the request is in the URL.
+type UnshareChecklistResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Checklist Custom Fields RESTful API
+//===========================================================================
+
+// ListFieldsResponse returns the custom fields defined by the checklist in the URL.
+type ListFieldsResponse struct {
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty" yaml:"error,omitempty"`
+	Fields  []CustomField `json:"fields"`
+}
+
+// CreateFieldResponse returns the information about the created custom field. Currently
+// the CreateFieldRequest is simply the custom field object itself.
+type CreateFieldResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	FieldID uint   `json:"field,omitempty"`
+}
+
+// UpdateFieldResponse returns information about the update call. Currently there is no
+// UpdateFieldRequest, because it is simply the custom field object itself.
+type UpdateFieldResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteFieldResponse returns information about the delete call. Currently there is no
+// DeleteFieldRequest, because the request is in the URL.
+type DeleteFieldResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Workspaces RESTful API
+//===========================================================================
+
+// ListWorkspacesResponse returns the workspaces the user is a member of along with
+// their role in each. Currently there is no ListWorkspacesRequest.
+type ListWorkspacesResponse struct {
+	Success    bool        `json:"success"`
+	Error      string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Workspaces []Workspace `json:"workspaces,omitempty"`
+}
+
+// CreateWorkspaceResponse returns the information about the created workspace.
+// Currently the CreateWorkspaceRequest is simply the workspace object itself.
+type CreateWorkspaceResponse struct {
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+	WorkspaceID uint   `json:"workspace,omitempty"`
+}
+
+// DetailWorkspaceResponse returns the workspace with its members. Currently there is
+// no DetailWorkspaceRequest, the request is in the URL.
+type DetailWorkspaceResponse struct {
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Workspace Workspace `json:"workspace"`
+}
+
+// UpdateWorkspaceResponse returns information about the update call. Currently there
+// is no UpdateWorkspaceRequest, because it is simply the workspace object itself.
+type UpdateWorkspaceResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteWorkspaceResponse returns information about the delete call. Currently there
+// is no DeleteWorkspaceRequest, because the request is in the URL.
+type DeleteWorkspaceResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// AddMemberRequest adds the user to the workspace in the URL as an owner or a member,
+// replacing their current role if they are already a member of the workspace.
+type AddMemberRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// AddMemberResponse returns information about the add member call.
+type AddMemberResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RemoveMemberResponse returns information about the remove member call. Currently
+// there is no RemoveMemberRequest, because the request is in the URL.
+type RemoveMemberResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Projects RESTful API
+//===========================================================================
+
+// ListProjectsResponse returns the projects of the user along with their progress.
+// Currently there is no ListProjectsRequest since projects are not paginated.
+type ListProjectsResponse struct {
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Projects []Project `json:"projects,omitempty"`
+}
+
+// CreateProjectResponse returns the information about the created project. Currently
+// the CreateProjectRequest is simply the project object itself.
+type CreateProjectResponse struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+	ProjectID uint   `json:"project,omitempty"`
+}
+
+// DetailProjectResponse returns the project with its checklists. Currently there is no
+// DetailProjectRequest, the request is in the URL.
+type DetailProjectResponse struct {
+	Success bool    `json:"success"`
+	Error   string  `json:"error,omitempty" yaml:"error,omitempty"`
+	Project Project `json:"project"`
+}
+
+// UpdateProjectResponse returns information about the update call. Currently there is
+// no UpdateProjectRequest, because it is simply the project object itself.
+type UpdateProjectResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteProjectResponse returns information about the delete call. Currently there is
+// no DeleteProjectRequest, because the request is in the URL.
+type DeleteProjectResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Checklist Templates RESTful API
+//===========================================================================
+
+// SaveTemplateRequest saves the checklist in the URL as a template. If the title is
+// omitted, the title of the checklist is used. Deadlines are stored relative to the
+// anchor, which defaults to the deadline of the checklist or when it was created.
+type SaveTemplateRequest struct {
+	Title  string     `json:"title,omitempty"`
+	Anchor *time.Time `json:"anchor,omitempty"`
+}
+
+// ListTemplatesResponse returns the checklist templates of the user without their
+// tasks. Currently there is no ListTemplatesRequest since templates are not paginated.
+type ListTemplatesResponse struct {
+	Success   bool                `json:"success"`
+	Error     string              `json:"error,omitempty" yaml:"error,omitempty"`
+	Templates []ChecklistTemplate `json:"templates,omitempty"`
+}
+
+// CreateTemplateResponse returns the id of the template saved from a checklist.
+type CreateTemplateResponse struct {
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+	TemplateID uint   `json:"template,omitempty"`
+}
+
+// DetailTemplateResponse returns the template along with its tasks in order. Currently
+// there is no DetailTemplateRequest, the request is in the URL.
+type DetailTemplateResponse struct {
+	Success  bool              `json:"success"`
+	Error    string            `json:"error,omitempty" yaml:"error,omitempty"`
+	Template ChecklistTemplate `json:"template"`
+}
+
+// InstantiateTemplateRequest creates a new checklist from the template in the URL. The
+// deadlines of the checklist and its tasks are computed from the anchor, which defaults
+// to now. If the title is omitted, the title of the template is used.
+type InstantiateTemplateRequest struct {
+	Title  string     `json:"title,omitempty"`
+	Anchor *time.Time `json:"anchor,omitempty"`
+}
+
+// DeleteTemplateResponse returns information about the delete call. Currently there is
+// no DeleteTemplateRequest, because the request is in the URL.
+type DeleteTemplateResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Time Tracking RESTful API
+//===========================================================================
+
+// ListTimeEntriesRequest fetches the time entries started in the time range [from, to),
+// optionally filtered by task or checklist. Times must be formatted as RFC3339.
+type ListTimeEntriesRequest struct {
+	Task      uint      `json:"task,omitempty" form:"task"`
+	Checklist uint      `json:"checklist,omitempty" form:"checklist"`
+	From      time.Time `json:"from,omitempty" form:"from"`
+	To        time.Time `json:"to,omitempty" form:"to"`
+}
+
+// ListTimeEntriesResponse returns the time entries and the total number of seconds
+// tracked by them, including the time elapsed on a running timer.
+type ListTimeEntriesResponse struct {
+	Success     bool        `json:"success"`
+	Error       string      `json:"error,omitempty" yaml:"error,omitempty"`
+	TimeEntries []TimeEntry `json:"time_entries"`
+	Total       int64       `json:"total"`
+}
+
+// TimeEntryResponse returns a single time entry, e.g. a manually created entry, the
+// running timer, or the timer that was just stopped. Currently the request to create a
+// time entry is simply the time entry object itself.
+type TimeEntryResponse struct {
+	Success   bool       `json:"success"`
+	Error     string     `json:"error,omitempty" yaml:"error,omitempty"`
+	TimeEntry *TimeEntry `json:"time_entry,omitempty"`
+}
+
+// DeleteTimeEntryResponse returns information about the delete time entry call.
+// Currently there is no DeleteTimeEntryRequest, because the request is in the URL.
+type DeleteTimeEntryResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// StartTimerRequest starts a timer on the specified task.
+type StartTimerRequest struct {
+	TaskID uint   `json:"task" binding:"required"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// StartTimerResponse returns the running timer along with the timer that was stopped
+// in order to start it, if one was running.
+type StartTimerResponse struct {
+	Success   bool       `json:"success"`
+	Error     string     `json:"error,omitempty" yaml:"error,omitempty"`
+	TimeEntry TimeEntry  `json:"time_entry"`
+	Stopped   *TimeEntry `json:"stopped,omitempty"`
+}
+
+//===========================================================================
+// Tags RESTful API
+//===========================================================================
+
+// ListTagsResponse returns all of the tags defined by the user. Currently there is no
+// ListTagsRequest since tags are not paginated.
+type ListTagsResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	Tags    []Tag  `json:"tags,omitempty"`
+}
+
+// CreateTagResponse returns the information about the created tag. Currently the
+// CreateTagRequest is simply the tag object itself.
+type CreateTagResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	TagID   uint   `json:"tag,omitempty"`
+}
+
+// DetailTagResponse returns the detailed information about the tag. Currently there is
+// no DetailTagRequest, the request is in the URL.
+type DetailTagResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	Tag     Tag    `json:"tag"`
+}
+
+// UpdateTagResponse returns information about the update call. Currently there is no
+// UpdateTagRequest, because it is simply the tag object itself.
+type UpdateTagResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteTagResponse returns information about the delete call. Currently there is no
+// DeleteTagRequest, because the request is in the URL.
+type DeleteTagResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Trash RESTful API
+//===========================================================================
+
+// ListTrashResponse returns the tasks and checklists of the user that are in the trash
+// of the active workspace. Tasks that were moved to the trash along with their parent
+// or checklist are not listed since they are restored with it. Currently there is no
+// ListTrashRequest since the trash is not paginated.
+type ListTrashResponse struct {
+	Success    bool        `json:"success"`
+	Error      string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Tasks      []Task      `json:"tasks,omitempty"`
+	Checklists []Checklist `json:"checklists,omitempty"`
+}
+
+// RestoreTrashResponse returns information about the restore call. Currently there is
+// no RestoreTrashRequest, because the request is in the URL.
+type RestoreTrashResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// EmptyTrashResponse returns the number of tasks and checklists that were permanently
+// deleted. Currently there is no EmptyTrashRequest, the trash of the active workspace
+// is emptied.
+type EmptyTrashResponse struct {
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+	Tasks      int    `json:"tasks"`
+	Checklists int    `json:"checklists"`
+}
+
+//===========================================================================
+// Saved Views RESTful API
+//===========================================================================
+
+// ListViewsResponse returns all of the saved views of the user with the number of tasks
+// that match each view. Currently there is no ListViewsRequest since views are not
+// paginated.
+type ListViewsResponse struct {
+	Success bool        `json:"success"`
+	Error   string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Views   []SavedView `json:"views,omitempty"`
+}
+
+// CreateViewResponse returns the information about the created view. Currently the
+// CreateViewRequest is simply the saved view object itself.
+type CreateViewResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+	ViewID  uint   `json:"view,omitempty"`
+}
+
+// DetailViewResponse returns the detailed information about the view. Currently there
+// is no DetailViewRequest, the request is in the URL. The tasks of the view are returned
+// in a ListTasksResponse when the view is evaluated.
+type DetailViewResponse struct {
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty" yaml:"error,omitempty"`
+	View    SavedView `json:"view"`
+}
+
+// UpdateViewResponse returns information about the update call. Currently there is no
+// UpdateViewRequest, because it is simply the saved view object itself.
+type UpdateViewResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// DeleteViewResponse returns information about the delete call. Currently there is no
+// DeleteViewRequest, because the request is in the URL.
+type DeleteViewResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+//===========================================================================
+// Search RESTful API
+//===========================================================================
+
+// SearchRequest finds tasks and checklists by the words in their title or details. The
+// type limits the results to tasks or checklists, otherwise both are searched.
+type SearchRequest struct {
+	Query string `json:"q" form:"q" binding:"required"`
+	Type  string `json:"type,omitempty" form:"type"`
+	Limit int    `json:"limit,omitempty" form:"limit"`
+}
+
+// SearchResult is a task or checklist that matches the search query. The snippet is an
+// excerpt of the title and details with the matching words highlighted by <mark> tags.
+type SearchResult struct {
+	Type        string  `json:"type"`
+	ID          uint    `json:"id"`
+	Title       string  `json:"title"`
+	ChecklistID *uint   `json:"checklist,omitempty" yaml:"checklist,omitempty"`
+	Rank        float64 `json:"rank"`
+	Snippet     string  `json:"snippet,omitempty" yaml:"snippet,omitempty"`
+}
+
+// SearchResponse returns the search results ordered by rank, most relevant first.
+type SearchResponse struct {
+	Success bool           `json:"success"`
+	Error   string         `json:"error,omitempty" yaml:"error,omitempty"`
+	Results []SearchResult `json:"results"`
+}
@@ -0,0 +1,102 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	errInvalidAssignee = errors.New("assignee must be a username or null")
+	errAssigneeAccess  = errors.New("tasks can only be assigned to users who can see them")
+)
+
+//===========================================================================
+// Assignee Helpers
+//===========================================================================
+
+// parseAssignee validates the assignee of a task update, null unassigns the task.
+func parseAssignee(val interface{}) (username string, err error) {
+	if val == nil {
+		return "", nil
+	}
+
+	var ok bool
+	if username, ok = val.(string); !ok {
+		return "", errInvalidAssignee
+	}
+	return username, nil
+}
+
+// assignTask assigns the task to the user with the username or unassigns the task if
+// the username is empty. The assignee must be able to see the task, e.g. its owner or
+// a user the checklist of the task is shared with. If the assignee cannot be looked up
+// the error is logged and errInternal is returned.
+func assignTask(db *gorm.DB, task *Task, username string) (err error) {
+	if username == "" {
+		task.AssigneeID, task.Assignee = nil, ""
+		return nil
+	}
+
+	var assignee User
+	if err = db.Select("id, username").Where("username = ?", username).First(&assignee).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("user %q does not exist", username)
+		}
+		logger.Printf("could not find assignee: %s", err)
+		return errInternal
+	}
+
+	var role string
+	if role, err = taskRole(db, assignee.ID, *task); err != nil {
+		logger.Printf("could not check assignee access: %s", err)
+		return errInternal
+	}
+
+	if role == "" {
+		return errAssigneeAccess
+	}
+
+	task.AssigneeID, task.Assignee = &assignee.ID, assignee.Username
+	return nil
+}
+
+// loadAssignees sets the username of the assignee of each of the tasks.
+func loadAssignees(db *gorm.DB, tasks []Task) (err error) {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		if task.AssigneeID != nil {
+			ids = append(ids, *task.AssigneeID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var users []User
+	if err = db.Select("id, username").Where("id IN (?)", ids).Find(&users).Error; err != nil {
+		return err
+	}
+
+	usernames := make(map[uint]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	for i := range tasks {
+		if tasks[i].AssigneeID != nil {
+			tasks[i].Assignee = usernames[*tasks[i].AssigneeID]
+		}
+	}
+	return nil
+}
+
+// assignedTasks counts the open tasks in the workspace that are assigned to the user.
+func assignedTasks(db *gorm.DB, userID uint, workspace *uint) (count int, err error) {
+	err = inWorkspace(db, "tasks", workspace).Model(&Task{}).
+		Where("assignee_id = ? AND completed = ? AND archived = ?", userID, false, false).
+		Count(&count).Error
+	return count, err
+}
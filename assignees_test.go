@@ -0,0 +1,70 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestAssignees() {
+	// Ensure the admin user exists so that tasks can be assigned to them
+	s.Login(true)
+
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "release"}, &list))
+
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "changelog", ChecklistID: &list.ChecklistID}, &task))
+	taskPath := fmt.Sprintf("/v1/tasks/%d", task.TaskID)
+
+	// Tasks can only be assigned to existing users who can see them
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, taskPath, map[string]interface{}{"assignee": "nobody"}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, taskPath, map[string]interface{}{"assignee": "admin"}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, taskPath, map[string]interface{}{"assignee": 42}, nil))
+
+	sharesPath := fmt.Sprintf("/v1/lists/%d/shares", list.ChecklistID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "admin", Role: RoleEditor}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, taskPath, map[string]interface{}{"assignee": "admin"}, nil))
+
+	// The task is still owned by its creator
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, taskPath, nil, &detail))
+	require.Equal(s.T(), "admin", detail.Task.Assignee)
+
+	var mine CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "tag", Assignee: "jane", ChecklistID: &list.ChecklistID}, &mine))
+
+	// Tasks can be filtered by those assigned to the user
+	var assigned ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/tasks?assigned=true", nil, &assigned))
+	require.Equal(s.T(), []uint{task.TaskID}, taskIDs(assigned.Tasks))
+	require.Equal(s.T(), "admin", assigned.Tasks[0].Assignee)
+
+	assigned = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?assigned=true", nil, &assigned))
+	require.Equal(s.T(), []uint{mine.TaskID}, taskIDs(assigned.Tasks))
+
+	// The overview counts the open tasks assigned to the user
+	var overview OverviewResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/", nil, &overview))
+	require.Equal(s.T(), 1, overview.Assigned)
+
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodPut, taskPath, map[string]interface{}{"completed": true}, nil))
+
+	overview = OverviewResponse{}
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/", nil, &overview))
+	require.Equal(s.T(), 0, overview.Assigned)
+
+	// Null unassigns the task
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, taskPath, map[string]interface{}{"assignee": nil}, nil))
+	detail = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, taskPath, nil, &detail))
+	require.Empty(s.T(), detail.Task.Assignee)
+
+	// Clean up so that other tests are not affected by the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, taskPath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", mine.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+}
@@ -0,0 +1,249 @@
+package todos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	// The maximum size of an attachment if not otherwise specified by the settings.
+	defaultMaxUploadSize int64 = 10 * 1024 * 1024
+
+	// Additional bytes allowed in the request body for the multipart encoding.
+	multipartOverhead int64 = 64 * 1024
+
+	// The number of bytes required by http.DetectContentType to sniff the content.
+	sniffLength = 512
+
+	// The name of the multipart form field that contains the uploaded file.
+	attachmentField = "file"
+)
+
+var (
+	errNoAttachment      = fmt.Errorf("a file must be uploaded in the %q form field", attachmentField)
+	errEmptyAttachment   = errors.New("cannot attach an empty file")
+	errAttachmentTooBig  = errors.New("attachment exceeds the maximum upload size")
+	errAttachmentMissing = errors.New("attachment contents are missing from storage")
+)
+
+//===========================================================================
+// Viewset for Attachment objects
+//===========================================================================
+
+// ListAttachments returns the metadata of all of the files attached to the task.
+func (s *API) ListAttachments(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	var attachments []Attachment
+	if err := s.db.Where("task_id = ?", task.ID).Order("created_at").Order("id").Find(&attachments).Error; err != nil {
+		logger.Printf("could not fetch attachments: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListAttachmentsResponse{Success: true, Attachments: attachments})
+}
+
+// CreateAttachment uploads a file to the task from a multipart form. The size of the
+// file is limited by the server settings and the content type is detected from the
+// contents of the file rather than from the request.
+func (s *API) CreateAttachment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Limit the size of the request before the multipart form is parsed
+	limit := s.maxUploadSize()
+	if c.Request.ContentLength > limit+multipartOverhead {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(errAttachmentTooBig))
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartOverhead)
+
+	header, err := c.FormFile(attachmentField)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errNoAttachment))
+		return
+	}
+
+	if header.Size > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(errAttachmentTooBig))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	// Sniff the content type from the first bytes of the file
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if n == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errEmptyAttachment))
+		return
+	}
+	head = head[:n]
+
+	attachment := Attachment{
+		TaskID:      task.ID,
+		UserID:      user.ID,
+		Filename:    attachmentFilename(header.Filename),
+		ContentType: http.DetectContentType(head),
+		Key:         fmt.Sprintf("%d/%s", user.ID, uuid.New()),
+	}
+
+	// Store the contents, reading at most one byte more than the limit to detect
+	// files whose size was misreported in the multipart header.
+	contents := io.LimitReader(io.MultiReader(bytes.NewReader(head), file), limit+1)
+	if attachment.Size, err = s.storage.Put(attachment.Key, contents); err != nil {
+		logger.Printf("could not store attachment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if attachment.Size > limit {
+		s.deleteBlobs(attachment.Key)
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(errAttachmentTooBig))
+		return
+	}
+
+	if err = s.db.Create(&attachment).Error; err != nil {
+		s.deleteBlobs(attachment.Key)
+		logger.Printf("could not create attachment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAttachmentResponse{Success: true, Attachment: attachment})
+}
+
+// DownloadAttachment streams the contents of the attached file. The file is always
+// served as a download so that browsers do not render uploaded content inline.
+func (s *API) DownloadAttachment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	attachment, ok := s.taskAttachment(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	blob, err := s.storage.Get(attachment.Key)
+	if err != nil {
+		if err == ErrBlobNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse(errAttachmentMissing))
+			return
+		}
+		logger.Printf("could not read attachment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+	defer blob.Close()
+
+	headers := map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, blob, headers)
+}
+
+// DeleteAttachment removes the attachment from the task and deletes its contents.
+func (s *API) DeleteAttachment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	attachment, ok := s.taskAttachment(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Delete(&attachment).Error; err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	s.deleteBlobs(attachment.Key)
+	c.JSON(http.StatusOK, DeleteAttachmentResponse{Success: true})
+}
+
+// taskAttachment fetches the attachment specified by the attachment url parameter on
+// the task specified by the id url parameter if the user has the required role. If the
+// attachment cannot be found, the error response is written and false is returned.
+func (s *API) taskAttachment(c *gin.Context, user User, required string) (attachment Attachment, ok bool) {
+	var task Task
+	if task, ok = s.userTask(c, user, required); !ok {
+		return attachment, false
+	}
+
+	if err := s.db.Where("id = ? AND task_id = ?", c.Param("attachment"), task.ID).First(&attachment).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return attachment, false
+		}
+		logger.Printf("could not find attachment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return attachment, false
+	}
+	return attachment, true
+}
+
+//===========================================================================
+// Attachment Helpers
+//===========================================================================
+
+// maxUploadSize returns the configured maximum size of an attachment in bytes.
+func (s *API) maxUploadSize() int64 {
+	if s.conf.MaxUploadSize > 0 {
+		return s.conf.MaxUploadSize
+	}
+	return defaultMaxUploadSize
+}
+
+// deleteBlobs removes the contents of attachments from storage after their metadata
+// has been deleted from the database. Errors are logged rather than returned since the
+// attachments no longer exist from the perspective of the user.
+func (s *API) deleteBlobs(keys ...string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(key); err != nil {
+			logger.Printf("could not delete attachment %s from storage: %s", key, err)
+		}
+	}
+}
+
+// attachmentFilename strips any directories from the uploaded filename and ensures
+// that it fits in the database.
+func attachmentFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+
+	if runes := []rune(name); len(runes) > 255 {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) > 16 {
+			ext = nil
+		}
+		name = string(runes[:255-len(ext)]) + string(ext)
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package todos_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader is enough of a PNG file for its content type to be sniffed.
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func (s *TodosTestSuite) TestAttachments() {
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "screenshot"}, &task))
+	path := fmt.Sprintf("/v1/tasks/%d/attachments", task.TaskID)
+
+	// The content type is sniffed rather than trusting the filename
+	var rep CreateAttachmentResponse
+	require.Equal(s.T(), http.StatusCreated, s.Upload(path, "../../screen.txt", pngHeader, &rep))
+	require.Equal(s.T(), "screen.txt", rep.Attachment.Filename)
+	require.Equal(s.T(), "image/png", rep.Attachment.ContentType)
+	require.Equal(s.T(), int64(len(pngHeader)), rep.Attachment.Size)
+
+	var notes CreateAttachmentResponse
+	require.Equal(s.T(), http.StatusCreated, s.Upload(path, "notes.pdf", []byte("plain notes"), &notes))
+	require.Equal(s.T(), "text/plain; charset=utf-8", notes.Attachment.ContentType)
+
+	// Empty and oversized files are rejected
+	require.Equal(s.T(), http.StatusBadRequest, s.Upload(path, "empty.txt", nil, nil))
+	require.Equal(s.T(), http.StatusRequestEntityTooLarge, s.Upload(path, "big.txt", bytes.Repeat([]byte("a"), 4097), nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, path, map[string]string{"file": "nope"}, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Upload("/v1/tasks/99999/attachments", "lost.txt", []byte("lost"), nil))
+
+	var list ListAttachmentsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &list))
+	require.Len(s.T(), list.Attachments, 2)
+	require.Equal(s.T(), rep.Attachment.ID, list.Attachments[0].ID)
+
+	// Download the attachment
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d", path, rep.Attachment.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+s.Login(false))
+	s.router.ServeHTTP(w, req)
+	require.Equal(s.T(), http.StatusOK, w.Code)
+	require.Equal(s.T(), pngHeader, w.Body.Bytes())
+	require.Equal(s.T(), "image/png", w.Header().Get("Content-Type"))
+	require.Equal(s.T(), "attachment; filename=screen.txt", w.Header().Get("Content-Disposition"))
+	require.Equal(s.T(), "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	// Delete the attachment and its contents
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("%s/%d", path, notes.Attachment.ID), nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, fmt.Sprintf("%s/%d", path, notes.Attachment.ID), nil, nil))
+
+	// Deleting the task keeps its attachments in the trash, emptying the trash removes the
+	// contents of its attachments from storage
+	files := func() (n int) {
+		dirs, err := ioutil.ReadDir(s.conf.StorageDir)
+		require.NoError(s.T(), err)
+		for _, dir := range dirs {
+			blobs, err := ioutil.ReadDir(fmt.Sprintf("%s/%s", s.conf.StorageDir, dir.Name()))
+			require.NoError(s.T(), err)
+			n += len(blobs)
+		}
+		return n
+	}
+
+	before := files()
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", task.TaskID), nil, nil))
+	require.Equal(s.T(), before, files())
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, "/v1/trash", nil, nil))
+	require.Equal(s.T(), before-1, files())
+}
+
+// Upload posts the data as a multipart file as the test user and decodes the response.
+func (s *TodosTestSuite) Upload(path, filename string, data []byte, rep interface{}) int {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", filename)
+	s.NoError(err)
+	_, err = part.Write(data)
+	s.NoError(err)
+	s.NoError(form.Close())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+s.Login(false))
+	s.router.ServeHTTP(w, req)
+
+	if rep != nil {
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(rep))
+	}
+	return w.Code
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "todos-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	storage, err := NewLocalStorage(dir)
+	require.NoError(t, err)
+
+	n, err := storage.Put("1/blob", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	require.Equal(t, int64(11), n)
+
+	blob, err := storage.Get("1/blob")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	require.Equal(t, "hello world", string(data))
+
+	require.NoError(t, storage.Delete("1/blob"))
+	require.NoError(t, storage.Delete("1/blob"))
+
+	_, err = storage.Get("1/blob")
+	require.Equal(t, ErrBlobNotFound, err)
+
+	// Keys cannot escape the storage directory
+	for _, key := range []string{"", "../escape", "1/../../escape"} {
+		_, err = storage.Put(key, strings.NewReader("nope"))
+		require.Error(t, err, key)
+	}
+}
@@ -75,7 +75,7 @@ func (s *API) Login(c *gin.Context) {
 
 	// Lookup the user in the database
 	var user User
-	if err := s.db.Select("id, password").Where("username = ?", form.Username).First(&user).Error; err != nil {
+	if err := s.db.Select("id, password, is_admin").Where("username = ?", form.Username).First(&user).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			c.JSON(http.StatusUnauthorized, ErrorResponse(nil))
 			return
@@ -101,8 +101,23 @@ func (s *API) Login(c *gin.Context) {
 		return
 	}
 
+	// Select the workspace of the tokens if the user is a member of it
+	var workspace *uint
+	if form.Workspace > 0 {
+		if err = checkMember(s.db, user, form.Workspace); err != nil {
+			if err == errNotMember {
+				c.JSON(http.StatusForbidden, ErrorResponse(err))
+				return
+			}
+			logger.Printf("could not check workspace membership: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		workspace = &form.Workspace
+	}
+
 	// Issue new JWT tokens for the user
-	token, err := CreateAuthToken(s.db, user.ID)
+	token, err := CreateAuthToken(s.db, user.ID, workspace)
 	if err != nil {
 		// Panic instead?
 		logger.Printf("could not create auth token: %s", err)
@@ -213,7 +228,7 @@ func (s *API) Refresh(c *gin.Context) {
 	}
 
 	// Issue new JWT tokens for the user
-	token, err := CreateAuthToken(s.db, refresh.UserID)
+	token, err := CreateAuthToken(s.db, refresh.UserID, refresh.WorkspaceID)
 	if err != nil {
 		// Panic instead?
 		logger.Printf("could not create auth token: %s", err)
@@ -293,8 +308,25 @@ func (s *API) Authorize() gin.HandlerFunc {
 
 		// TODO: update last_seen in the database
 
-		// Save the user in the context for downstream usage
+		// Select the active workspace from the workspace header or the token
+		workspace, err := selectWorkspace(c, s.db, token.User, token)
+		if err != nil {
+			switch err {
+			case errNotMember:
+				c.JSON(http.StatusForbidden, ErrorResponse(err))
+			case errInvalidWorkspace:
+				c.JSON(http.StatusBadRequest, ErrorResponse(err))
+			default:
+				logger.Printf("could not select workspace: %s", err)
+				c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			}
+			c.Abort()
+			return
+		}
+
+		// Save the user and workspace in the context for downstream usage
 		c.Set(ctxUserKey, token.User)
+		c.Set(ctxWorkspaceKey, workspace)
 
 		// Everything checks out, user is good to go
 		c.Next()
@@ -533,16 +565,18 @@ func (t Token) RefreshToken() (token string, err error) {
 
 // CreateAuthToken generates acccess and refresh tokens for API authorization using a
 // cookie or Bearer header and stores them in the database. A single user can create
-// multiple auth tokens and each of them are assigned a unique uuid for lookup.
-func CreateAuthToken(db *gorm.DB, user uint) (token Token, err error) {
+// multiple auth tokens and each of them are assigned a unique uuid for lookup. If a
+// workspace is specified, it is the active workspace of requests made with the token.
+func CreateAuthToken(db *gorm.DB, user uint, workspace *uint) (token Token, err error) {
 	// Create the token record in the database
 	now := time.Now()
 	token = Token{
-		ID:        uuid.New(),
-		UserID:    user,
-		IssuedAt:  now,
-		ExpiresAt: now.Add(jwtAccessTokenDuration),
-		RefreshBy: now.Add(jwtRefreshTokenDuration),
+		ID:          uuid.New(),
+		UserID:      user,
+		WorkspaceID: workspace,
+		IssuedAt:    now,
+		ExpiresAt:   now.Add(jwtAccessTokenDuration),
+		RefreshBy:   now.Add(jwtRefreshTokenDuration),
 	}
 
 	// Sign and generate the accessToken (caching it and ensuring no errors)
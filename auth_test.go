@@ -55,7 +55,7 @@ func TestPasswordDerivedKey(t *testing.T) {
 
 func TestAuthTokens(t *testing.T) {
 	t.Skip("test requires database mock")
-	token, err := CreateAuthToken(nil, 42)
+	token, err := CreateAuthToken(nil, 42, nil)
 	require.NoError(t, err)
 	require.NotZero(t, token, "no token struct was returned")
 
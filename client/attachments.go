@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/bbengfort/todos"
+)
+
+// ListAttachments returns the metadata of the files attached to the specified task.
+// This function checks the response for errors but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) ListAttachments(id uint) (out *todos.ListAttachmentsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d/attachments", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateAttachment uploads the file at the specified path to the task as a multipart
+// form. This function checks the response for errors, but does not otherwise modify
+// the output response. User authentication is required.
+func (c *Client) CreateAttachment(id uint, path string) (out *todos.CreateAttachmentResponse, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+
+	var part io.Writer
+	if part, err = form.CreateFormFile("file", filepath.Base(path)); err != nil {
+		return nil, err
+	}
+
+	if _, err = io.Copy(part, f); err != nil {
+		return nil, err
+	}
+
+	if err = form.Close(); err != nil {
+		return nil, err
+	}
+
+	// Replace the JSON body and content type with the multipart form
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/attachments", id), true, nil); err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(body)
+	req.ContentLength = int64(body.Len())
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DownloadAttachment writes the contents of the attachment to the writer and returns
+// the filename of the attachment. User authentication is required.
+func (c *Client) DownloadAttachment(id, attachment uint, w io.Writer) (filename string, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d/attachments/%d", id, attachment), true, nil); err != nil {
+		return "", err
+	}
+
+	var rep *http.Response
+	if rep, err = c.Client.Do(req); err != nil {
+		return "", err
+	}
+	defer rep.Body.Close()
+
+	// Errors are returned as JSON rather than as the attachment contents
+	if rep.StatusCode != http.StatusOK {
+		out := &todos.Response{}
+		json.NewDecoder(rep.Body).Decode(out)
+		return "", StatusError(rep.StatusCode, out.Error)
+	}
+
+	if _, params, err := mime.ParseMediaType(rep.Header.Get("Content-Disposition")); err == nil {
+		filename = params["filename"]
+	}
+
+	if _, err = io.Copy(w, rep.Body); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// DeleteAttachment removes the attachment from the specified task. This function checks
+// the response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteAttachment(id, attachment uint) (out *todos.DeleteAttachmentResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d/attachments/%d", id, attachment), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
@@ -20,9 +20,10 @@ func (c *Client) Login() (err error) {
 
 	// Build data request
 	data := &todos.LoginRequest{
-		Username: c.creds.Username,
-		Password: c.creds.Password,
-		NoCookie: true,
+		Username:  c.creds.Username,
+		Password:  c.creds.Password,
+		Workspace: c.creds.Workspace,
+		NoCookie:  true,
 	}
 
 	if data.Username == "" {
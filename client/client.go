@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/bbengfort/todos"
@@ -62,6 +63,11 @@ func (c *Client) NewRequest(method, url string, auth bool, data interface{}) (re
 			return nil, ErrNotLoggedIn
 		}
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.creds.Tokens.Access))
+
+		// Select the default workspace, otherwise the personal space is used
+		if c.creds.Workspace > 0 {
+			req.Header.Set(todos.WorkspaceHeader, strconv.FormatUint(uint64(c.creds.Workspace), 10))
+		}
 	}
 
 	return req, nil
@@ -131,3 +137,11 @@ func (c *Client) Overview() (rep *todos.OverviewResponse, err error) {
 
 	return rep, nil
 }
+
+// UseWorkspace stores the workspace as the default workspace in the credentials so that
+// it is selected as the active workspace of future requests; 0 selects the personal
+// space of the user.
+func (c *Client) UseWorkspace(id uint) (err error) {
+	c.creds.Workspace = id
+	return c.creds.Dump()
+}
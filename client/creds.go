@@ -35,13 +35,16 @@ func Configuration() (string, error) {
 // login. The access token is used in the Bearer header to make request. After the
 // NotBefore timestamp, the access token is automatically refreshed until the refresh
 // token expires. If the password is stored, then automatic login occurs in this case.
-// Note that the local client can only maintain one set of credentials at a time.
+// If a default workspace is stored, it is selected as the active workspace of all
+// requests. Note that the local client can only maintain one set of credentials at a
+// time.
 type Credentials struct {
-	Version  string `yaml:"version"`            // api version to prepend to all path requests
-	Endpoint string `yaml:"endpoint"`           // the endpoint to connect to
-	Username string `yaml:"username,omitempty"` // username to login with (optional)
-	Password string `yaml:"password,omitempty"` // password to login with (optional)
-	Tokens   struct {
+	Version   string `yaml:"version"`             // api version to prepend to all path requests
+	Endpoint  string `yaml:"endpoint"`            // the endpoint to connect to
+	Username  string `yaml:"username,omitempty"`  // username to login with (optional)
+	Password  string `yaml:"password,omitempty"`  // password to login with (optional)
+	Workspace uint   `yaml:"workspace,omitempty"` // default workspace to work in (optional)
+	Tokens    struct {
 		Access    string    `yaml:"access"`     // access token to send with Bearer requests
 		Refresh   string    `yaml:"refresh"`    // refresh token to obtain a new access token without login
 		IssuedAt  time.Time `yaml:"issued_at"`  // timestamp of the login
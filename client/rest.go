@@ -3,6 +3,9 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/bbengfort/todos"
 )
@@ -12,7 +15,7 @@ import (
 // modify the output response. User authentication is required.
 func (c *Client) ListTasks(in *todos.ListTasksRequest) (out *todos.ListTasksResponse, err error) {
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodGet, "/tasks", true, in); err != nil {
+	if req, err = c.NewRequest(http.MethodGet, "/tasks?"+listTasksQuery(in).Encode(), true, nil); err != nil {
 		return nil, err
 	}
 
@@ -28,6 +31,88 @@ func (c *Client) ListTasks(in *todos.ListTasksRequest) (out *todos.ListTasksResp
 	return out, nil
 }
 
+// listTasksQuery encodes the list tasks request as url query parameters since the
+// server binds GET requests from the query string rather than the request body.
+func listTasksQuery(in *todos.ListTasksRequest) url.Values {
+	query := make(url.Values)
+	if in == nil {
+		return query
+	}
+
+	if in.Checklist > 0 {
+		query.Set("checklist", strconv.FormatUint(uint64(in.Checklist), 10))
+	}
+
+	for _, tag := range in.Tags {
+		query.Add("tag", tag)
+	}
+
+	for _, field := range in.Fields {
+		query.Add("field", field)
+	}
+
+	if in.Completed != nil {
+		query.Set("completed", strconv.FormatBool(*in.Completed))
+	}
+
+	if in.Archived != nil {
+		query.Set("archived", strconv.FormatBool(*in.Archived))
+	}
+
+	if in.Overdue {
+		query.Set("overdue", "true")
+	}
+
+	// Time ranges are encoded as RFC3339 timestamps with nanosecond precision
+	ranges := []struct {
+		param string
+		ts    *time.Time
+	}{
+		{"deadline_before", in.DeadlineBefore},
+		{"deadline_after", in.DeadlineAfter},
+		{"created_before", in.CreatedBefore},
+		{"created_after", in.CreatedAfter},
+		{"updated_before", in.UpdatedBefore},
+		{"updated_after", in.UpdatedAfter},
+	}
+
+	for _, r := range ranges {
+		if r.ts != nil {
+			query.Set(r.param, r.ts.Format(time.RFC3339Nano))
+		}
+	}
+
+	if in.Title != "" {
+		query.Set("title", in.Title)
+	}
+
+	for _, sort := range in.Sort {
+		query.Add("sort", sort)
+	}
+
+	if in.Deferred {
+		query.Set("deferred", "true")
+	}
+
+	if in.Assigned {
+		query.Set("assigned", "true")
+	}
+
+	if in.Page > 0 {
+		query.Set("page", strconv.Itoa(in.Page))
+	}
+
+	if in.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(in.PerPage))
+	}
+
+	if in.Cursor != "" {
+		query.Set("cursor", in.Cursor)
+	}
+
+	return query
+}
+
 // CreateTask posts the task to the server in order to create it. This function checks
 // the response for errors, but does not otherwise modify the output response. User
 // authentication is required.
@@ -54,7 +139,7 @@ func (c *Client) CreateTask(in *todos.Task) (out *todos.CreateTaskResponse, err
 // response. User authentication is required.
 func (c *Client) DetailTask(id uint) (out *todos.DetailTaskResponse, err error) {
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d", id), true, nil); err != nil {
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d", id), true, nil); err != nil {
 		return nil, err
 	}
 
@@ -70,19 +155,172 @@ func (c *Client) DetailTask(id uint) (out *todos.DetailTaskResponse, err error)
 	return out, nil
 }
 
-// UpdateTask puts the task info to the specified id in order to update it. This
-// function checks the response for errors, but does not otherwise modify the output
-// response. User authentication is required.
-func (c *Client) UpdateTask(id uint, task *todos.Task) (out *todos.UpdateTaskResponse, err error) {
-	if id == 0 || (task.ID > 0 && id != task.ID) {
-		return nil, fmt.Errorf("cannot update with id %d and task id %d", id, task.ID)
+// UpdateTask patches the task with the specified id, only the fields in the merge patch
+// are modified and null values clear the field. This function checks the response for
+// errors, but does not otherwise modify the output response. User authentication is
+// required.
+func (c *Client) UpdateTask(id uint, patch todos.UpdateTaskRequest) (out *todos.UpdateTaskResponse, err error) {
+	if id == 0 {
+		return nil, fmt.Errorf("cannot update task with id %d", id)
+	}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%d", id), true, patch); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", todos.MIMEMergePatch)
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteTask sends a delete request for the specified id. If the task has subtasks,
+// cascade must be true to delete them along with the task. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteTask(id uint, cascade bool) (out *todos.DeleteTaskResponse, err error) {
+	path := fmt.Sprintf("/tasks/%d", id)
+	if cascade {
+		path += "?cascade=true"
+	}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, path, true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListDependencies returns the tasks that block the specified task. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) ListDependencies(id uint) (out *todos.ListDependenciesResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d/dependencies", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+
+	return out, nil
+}
+
+// CreateDependency marks the task as blocked by the blocker task. This function checks
+// the response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) CreateDependency(id, blocker uint) (out *todos.CreateDependencyResponse, err error) {
+	in := &todos.CreateDependencyRequest{BlockerID: blocker}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/dependencies", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+
+	return out, nil
+}
+
+// DeleteDependency removes the blocker from the task's dependencies. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) DeleteDependency(id, blocker uint) (out *todos.DeleteDependencyResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d/dependencies/%d", id, blocker), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListComments returns the comments on the specified task. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) ListComments(id uint) (out *todos.ListCommentsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d/comments", id), true, nil); err != nil {
+		return nil, err
 	}
 
-	// Ensure that the task ID is a zero value.
-	task.ID = 0
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateComment posts a comment on the specified task. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) CreateComment(id uint, text string) (out *todos.CreateCommentResponse, err error) {
+	in := &todos.Comment{Text: text}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/comments", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UpdateComment replaces the text of a comment on the specified task. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) UpdateComment(id, comment uint, text string) (out *todos.UpdateCommentResponse, err error) {
+	in := &todos.Comment{Text: text}
 
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%d", id), true, task); err != nil {
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%d/comments/%d", id, comment), true, in); err != nil {
 		return nil, err
 	}
 
@@ -97,12 +335,12 @@ func (c *Client) UpdateTask(id uint, task *todos.Task) (out *todos.UpdateTaskRes
 	return out, nil
 }
 
-// DeleteTask sends a delete request for the specified id. This function checks the
+// DeleteComment removes a comment from the specified task. This function checks the
 // response for errors, but does not otherwise modify the output response. User
 // authentication is required.
-func (c *Client) DeleteTask(id uint) (out *todos.DeleteTaskResponse, err error) {
+func (c *Client) DeleteComment(id, comment uint) (out *todos.DeleteCommentResponse, err error) {
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d", id), true, nil); err != nil {
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d/comments/%d", id, comment), true, nil); err != nil {
 		return nil, err
 	}
 
@@ -117,12 +355,72 @@ func (c *Client) DeleteTask(id uint) (out *todos.DeleteTaskResponse, err error)
 	return out, nil
 }
 
+// TaskHistory returns the history of changes to the specified task. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) TaskHistory(id uint) (out *todos.HistoryResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/tasks/%d/history", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// MoveTask places the task immediately before or after another task in its checklist.
+// This function checks the response for errors, but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) MoveTask(id uint, in *todos.MoveTaskRequest) (out *todos.MoveTaskResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/tasks/%d/move", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// SnoozeTask defers the task by the duration, e.g. 3d, hiding it from the task list
+// until its new start date. User authentication is required.
+func (c *Client) SnoozeTask(id uint, duration string) (out *todos.SnoozeTaskResponse, err error) {
+	var req *http.Request
+	in := &todos.SnoozeTaskRequest{For: duration}
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/snooze", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
 // ListChecklists returns all checklists for the authenticated user, sorted and filtered
 // by the input request. This function checks the response for errors but does not
 // otherwise modify the output response. User authentication is required.
 func (c *Client) ListChecklists(in *todos.ListChecklistsRequest) (out *todos.ListChecklistsResponse, err error) {
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodGet, "/lists", true, in); err != nil {
+	if req, err = c.NewRequest(http.MethodGet, "/lists?"+listChecklistsQuery(in).Encode(), true, nil); err != nil {
 		return nil, err
 	}
 
@@ -138,6 +436,32 @@ func (c *Client) ListChecklists(in *todos.ListChecklistsRequest) (out *todos.Lis
 	return out, nil
 }
 
+// listChecklistsQuery encodes the list checklists request as url query parameters.
+func listChecklistsQuery(in *todos.ListChecklistsRequest) url.Values {
+	query := make(url.Values)
+	if in == nil {
+		return query
+	}
+
+	if in.Project > 0 {
+		query.Set("project", strconv.FormatUint(uint64(in.Project), 10))
+	}
+
+	if in.Page > 0 {
+		query.Set("page", strconv.Itoa(in.Page))
+	}
+
+	if in.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(in.PerPage))
+	}
+
+	if in.Cursor != "" {
+		query.Set("cursor", in.Cursor)
+	}
+
+	return query
+}
+
 // CreateChecklist posts the checklist to the server in order to create it. This function
 // checks the response for errors, but does not otherwise modify the output response.
 // User authentication is required.
@@ -180,24 +504,23 @@ func (c *Client) DetailChecklist(id uint) (out *todos.DetailChecklistResponse, e
 	return out, nil
 }
 
-// UpdateChecklist puts the checklist info to the specified id in order to update it.
-// This function checks the response for errors, but does not otherwise modify the
-// output response. User authentication is required.
-func (c *Client) UpdateChecklist(id uint, list *todos.Checklist) (out *todos.UpdateChecklistResponse, err error) {
-	if id == 0 || (list.ID > 0 && id != list.ID) {
-		return nil, fmt.Errorf("cannot update with id %d and checklist id %d", id, list.ID)
+// UpdateChecklist patches the checklist with the specified id, only the fields in the
+// merge patch are modified and null values clear the field. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) UpdateChecklist(id uint, patch todos.UpdateChecklistRequest) (out *todos.UpdateChecklistResponse, err error) {
+	if id == 0 {
+		return nil, fmt.Errorf("cannot update checklist with id %d", id)
 	}
 
-	// Ensure that the checklist ID is a zero value.
-	list.ID = 0
-
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/lists/%d", id), true, list); err != nil {
+	if req, err = c.NewRequest(http.MethodPatch, fmt.Sprintf("/lists/%d", id), true, patch); err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", todos.MIMEMergePatch)
 
 	var status int
-	if status, err = c.Do(req, out); err != nil {
+	if status, err = c.Do(req, &out); err != nil {
 		return nil, err
 	}
 
@@ -207,12 +530,12 @@ func (c *Client) UpdateChecklist(id uint, list *todos.Checklist) (out *todos.Upd
 	return out, nil
 }
 
-// DeleteChecklist sends a delete request for the specified id. This function checks the
-// response for errors, but does not otherwise modify the output response. User
-// authentication is required.
-func (c *Client) DeleteChecklist(id uint) (out *todos.DeleteChecklistResponse, err error) {
+// ChecklistHistory returns the history of changes to the specified checklist. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ChecklistHistory(id uint) (out *todos.HistoryResponse, err error) {
 	var req *http.Request
-	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/lists/%d", id), true, nil); err != nil {
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/history", id), true, nil); err != nil {
 		return nil, err
 	}
 
@@ -221,7 +544,1017 @@ func (c *Client) DeleteChecklist(id uint) (out *todos.DeleteChecklistResponse, e
 		return nil, err
 	}
 
-	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ReorderChecklist sets the order of all of the tasks in the specified checklist. This
+// function checks the response for errors, but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ReorderChecklist(id uint, tasks []uint) (out *todos.ReorderChecklistResponse, err error) {
+	in := &todos.ReorderChecklistRequest{Tasks: tasks}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/lists/%d/order", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteChecklist sends a delete request for the specified id. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteChecklist(id uint) (out *todos.DeleteChecklistResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/lists/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListProjects returns the projects of the authenticated user with their progress. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ListProjects() (out *todos.ListProjectsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/projects", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateProject posts the project to the server in order to create it. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) CreateProject(in *todos.Project) (out *todos.CreateProjectResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/projects", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusCreated || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DetailProject returns the project with its checklists. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DetailProject(id uint) (out *todos.DetailProjectResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/projects/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UpdateProject puts the project info to the specified id. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) UpdateProject(id uint, project *todos.Project) (out *todos.UpdateProjectResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/projects/%d", id), true, project); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteProject sends a delete request for the specified project. This function checks
+// the response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteProject(id uint) (out *todos.DeleteProjectResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/projects/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListShares returns the users the checklist is shared with and their roles. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ListShares(id uint) (out *todos.ListSharesResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/shares", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ShareChecklist shares the checklist with the user as a viewer or an editor. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ShareChecklist(id uint, username, role string) (out *todos.ShareChecklistResponse, err error) {
+	var req *http.Request
+	in := &todos.ShareChecklistRequest{Username: username, Role: role}
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/lists/%d/shares", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UnshareChecklist stops sharing the checklist with the user. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) UnshareChecklist(id uint, username string) (out *todos.UnshareChecklistResponse, err error) {
+	var req *http.Request
+	path := fmt.Sprintf("/lists/%d/shares/%s", id, url.PathEscape(username))
+	if req, err = c.NewRequest(http.MethodDelete, path, true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListFields returns the custom fields defined on the checklist. This function checks
+// the response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) ListFields(id uint) (out *todos.ListFieldsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/fields", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateField posts the custom field to the server in order to add it to the checklist.
+// This function checks the response for errors but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) CreateField(id uint, in *todos.CustomField) (out *todos.CreateFieldResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/lists/%d/fields", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UpdateField puts the custom field info to the specified field of the checklist in
+// order to rename it or to modify its options. This function checks the response for
+// errors but does not otherwise modify the output response. User authentication is
+// required.
+func (c *Client) UpdateField(id, field uint, in *todos.CustomField) (out *todos.UpdateFieldResponse, err error) {
+	if field == 0 || (in.ID > 0 && field != in.ID) {
+		return nil, fmt.Errorf("cannot update with id %d and field id %d", field, in.ID)
+	}
+
+	// Ensure that the field ID is a zero value.
+	in.ID = 0
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/lists/%d/fields/%d", id, field), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteField removes the custom field and all of its task values from the checklist.
+// This function checks the response for errors but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) DeleteField(id, field uint) (out *todos.DeleteFieldResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/lists/%d/fields/%d", id, field), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListWorkspaces returns the workspaces the authenticated user is a member of. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ListWorkspaces() (out *todos.ListWorkspacesResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/workspaces", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateWorkspace posts the workspace to the server in order to create it with the
+// authenticated user as its owner. This function checks the response for errors but
+// does not otherwise modify the output response. User authentication is required.
+func (c *Client) CreateWorkspace(in *todos.Workspace) (out *todos.CreateWorkspaceResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/workspaces", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusCreated || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DetailWorkspace returns the workspace with its members. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DetailWorkspace(id uint) (out *todos.DetailWorkspaceResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/workspaces/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UpdateWorkspace puts the workspace info to the specified id. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) UpdateWorkspace(id uint, workspace *todos.Workspace) (out *todos.UpdateWorkspaceResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/workspaces/%d", id), true, workspace); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteWorkspace sends a delete request for the specified workspace. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) DeleteWorkspace(id uint) (out *todos.DeleteWorkspaceResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/workspaces/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// AddMember adds the user to the workspace as an owner or a member. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) AddMember(id uint, username, role string) (out *todos.AddMemberResponse, err error) {
+	var req *http.Request
+	in := &todos.AddMemberRequest{Username: username, Role: role}
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/workspaces/%d/members", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// RemoveMember removes the user from the workspace. This function checks the response
+// for errors but does not otherwise modify the output response. User authentication is
+// required.
+func (c *Client) RemoveMember(id uint, username string) (out *todos.RemoveMemberResponse, err error) {
+	var req *http.Request
+	path := fmt.Sprintf("/workspaces/%d/members/%s", id, url.PathEscape(username))
+	if req, err = c.NewRequest(http.MethodDelete, path, true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListTemplates returns the checklist templates of the authenticated user. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ListTemplates() (out *todos.ListTemplatesResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/templates", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// SaveTemplate saves the specified checklist as a template. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) SaveTemplate(id uint, in *todos.SaveTemplateRequest) (out *todos.CreateTemplateResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/lists/%d/template", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusCreated || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DetailTemplate returns the specified template with its tasks. This function checks
+// the response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DetailTemplate(id uint) (out *todos.DetailTemplateResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/templates/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// InstantiateTemplate creates a new checklist from the specified template. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) InstantiateTemplate(id uint, in *todos.InstantiateTemplateRequest) (out *todos.CreateChecklistResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/templates/%d/instantiate", id), true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusCreated || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteTemplate sends a delete request for the specified template. This function
+// checks the response for errors but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) DeleteTemplate(id uint) (out *todos.DeleteTemplateResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/templates/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListTimeEntries returns the time entries of the authenticated user, filtered by the
+// input request. This function checks the response for errors but does not otherwise
+// modify the output response. User authentication is required.
+func (c *Client) ListTimeEntries(in *todos.ListTimeEntriesRequest) (out *todos.ListTimeEntriesResponse, err error) {
+	query := make(url.Values)
+	if in.Task > 0 {
+		query.Set("task", strconv.FormatUint(uint64(in.Task), 10))
+	}
+	if in.Checklist > 0 {
+		query.Set("checklist", strconv.FormatUint(uint64(in.Checklist), 10))
+	}
+	if !in.From.IsZero() {
+		query.Set("from", in.From.Format(time.RFC3339))
+	}
+	if !in.To.IsZero() {
+		query.Set("to", in.To.Format(time.RFC3339))
+	}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/time?"+query.Encode(), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateTimeEntry manually records time spent on a task. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) CreateTimeEntry(in *todos.TimeEntry) (out *todos.TimeEntryResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/time", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteTimeEntry sends a delete request for the specified time entry. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) DeleteTimeEntry(id uint) (out *todos.DeleteTimeEntryResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/time/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// RunningTimer returns the running timer of the authenticated user. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) RunningTimer() (out *todos.TimeEntryResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/timer", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// StartTimer starts a timer on the specified task, stopping any running timer. This
+// function checks the response for errors, but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) StartTimer(in *todos.StartTimerRequest) (out *todos.StartTimerResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/timer", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// StopTimer stops the running timer of the authenticated user. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) StopTimer() (out *todos.TimeEntryResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, "/timer", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListTags returns all tags defined by the authenticated user. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) ListTags() (out *todos.ListTagsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/tags", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+
+	return out, nil
+}
+
+// CreateTag posts the tag to the server in order to create it. This function checks
+// the response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) CreateTag(in *todos.Tag) (out *todos.CreateTagResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/tags", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+
+	return out, nil
+}
+
+// UpdateTag puts the tag info to the specified id in order to rename it. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) UpdateTag(id uint, tag *todos.Tag) (out *todos.UpdateTagResponse, err error) {
+	if id == 0 || (tag.ID > 0 && id != tag.ID) {
+		return nil, fmt.Errorf("cannot update with id %d and tag id %d", id, tag.ID)
+	}
+
+	// Ensure that the tag ID is a zero value.
+	tag.ID = 0
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/tags/%d", id), true, tag); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteTag sends a delete request for the specified id. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteTag(id uint) (out *todos.DeleteTagResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/tags/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListViews returns the saved views of the authenticated user with the number of tasks
+// that match each view. This function checks the response for errors but does not
+// otherwise modify the output response. User authentication is required.
+func (c *Client) ListViews() (out *todos.ListViewsResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/views", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// CreateView posts the saved view to the server in order to create it. This function
+// checks the response for errors, but does not otherwise modify the output response.
+// User authentication is required.
+func (c *Client) CreateView(in *todos.SavedView) (out *todos.CreateViewResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, "/views", true, in); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusCreated) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DetailView returns the saved view with the specified id. This function checks the
+// response for errors but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DetailView(id uint) (out *todos.DetailViewResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/views/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// UpdateView puts the saved view to the specified id, replacing its name and filter.
+// This function checks the response for errors, but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) UpdateView(id uint, view *todos.SavedView) (out *todos.UpdateViewResponse, err error) {
+	if id == 0 || (view.ID > 0 && id != view.ID) {
+		return nil, fmt.Errorf("cannot update with id %d and view id %d", id, view.ID)
+	}
+
+	// Ensure that the view ID is a zero value.
+	view.ID = 0
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPut, fmt.Sprintf("/views/%d", id), true, view); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// DeleteView sends a delete request for the specified id. This function checks the
+// response for errors, but does not otherwise modify the output response. User
+// authentication is required.
+func (c *Client) DeleteView(id uint) (out *todos.DeleteViewResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, fmt.Sprintf("/views/%d", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if !(status == http.StatusOK || status == http.StatusNoContent) || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// EvaluateView returns the tasks that match the saved view with the specified id. Only
+// the page, per page, and cursor of the request are used, the filter and sort are those
+// of the view. This function checks the response for errors but does not otherwise
+// modify the output response. User authentication is required.
+func (c *Client) EvaluateView(id uint, in *todos.ListTasksRequest) (out *todos.ListTasksResponse, err error) {
+	query := make(url.Values)
+	if in != nil {
+		if in.Page > 0 {
+			query.Set("page", strconv.Itoa(in.Page))
+		}
+
+		if in.PerPage > 0 {
+			query.Set("per_page", strconv.Itoa(in.PerPage))
+		}
+
+		if in.Cursor != "" {
+			query.Set("cursor", in.Cursor)
+		}
+	}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, fmt.Sprintf("/views/%d/tasks?%s", id, query.Encode()), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// ListTrash returns the tasks and checklists in the trash of the active workspace. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) ListTrash() (out *todos.ListTrashResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/trash", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// RestoreTask restores the task and the subtasks deleted with it from the trash. This
+// function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) RestoreTask(id uint) (out *todos.RestoreTrashResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/trash/tasks/%d/restore", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// RestoreChecklist restores the checklist and the tasks deleted with it from the trash.
+// This function checks the response for errors but does not otherwise modify the
+// output response. User authentication is required.
+func (c *Client) RestoreChecklist(id uint) (out *todos.RestoreTrashResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodPost, fmt.Sprintf("/trash/lists/%d/restore", id), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// EmptyTrash permanently deletes the tasks and checklists in the trash of the active
+// workspace. This function checks the response for errors but does not otherwise modify
+// the output response. User authentication is required.
+func (c *Client) EmptyTrash() (out *todos.EmptyTrashResponse, err error) {
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodDelete, "/trash", true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
+		return out, StatusError(status, out.Error)
+	}
+	return out, nil
+}
+
+// Search returns the tasks and checklists that match the search query, ordered by rank.
+// This function checks the response for errors but does not otherwise modify the output
+// response. User authentication is required.
+func (c *Client) Search(in *todos.SearchRequest) (out *todos.SearchResponse, err error) {
+	query := make(url.Values)
+	query.Set("q", in.Query)
+	if in.Type != "" {
+		query.Set("type", in.Type)
+	}
+
+	if in.Limit > 0 {
+		query.Set("limit", strconv.Itoa(in.Limit))
+	}
+
+	var req *http.Request
+	if req, err = c.NewRequest(http.MethodGet, "/search?"+query.Encode(), true, nil); err != nil {
+		return nil, err
+	}
+
+	var status int
+	if status, err = c.Do(req, &out); err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK || !out.Success {
 		return out, StatusError(status, out.Error)
 	}
 	return out, nil
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -88,6 +93,22 @@ func main() {
 					Name:  "t, no-tokens",
 					Usage: "do not clean up access tokens",
 				},
+				cli.BoolFlag{
+					Name:  "T, no-trash",
+					Usage: "do not purge old tasks and checklists from the trash",
+				},
+				cli.DurationFlag{
+					Name:   "r, retention",
+					Usage:  "purge items that have been in the trash for longer than this",
+					Value:  720 * time.Hour,
+					EnvVar: "TODOS_TRASH_RETENTION",
+				},
+				cli.StringFlag{
+					Name:   "s, storage",
+					Usage:  "directory where the contents of attachments are stored",
+					Value:  "attachments",
+					EnvVar: "TODOS_STORAGE_DIR",
+				},
 				cli.StringFlag{
 					Name:   "d, db",
 					Usage:  "database connection uri",
@@ -113,6 +134,10 @@ func main() {
 					Name:  "p, password",
 					Usage: "prompt to enter a password into the credentials file",
 				},
+				cli.UintFlag{
+					Name:  "w, workspace",
+					Usage: "specify the default workspace to work in (optional)",
+				},
 				cli.BoolFlag{
 					Name:  "d, dir",
 					Usage: "print the directory containing the configuration and exit",
@@ -156,13 +181,108 @@ func main() {
 			Category: "client",
 			Flags:    []cli.Flag{},
 		},
+		{
+			Name:      "search",
+			Usage:     "find tasks and checklists by the words in their title or details",
+			ArgsUsage: "word [word ...]",
+			Before:    setupClientWithLogin,
+			Action:    search,
+			Category:  "client",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "t, type",
+					Usage: "only search for a task or checklist",
+				},
+				cli.IntFlag{
+					Name:  "n, limit",
+					Usage: "maximum number of results to return",
+				},
+			},
+		},
 		{
 			Name:     "task:list",
 			Usage:    "list the tasks stored in the server",
 			Before:   setupClientWithLogin,
 			Action:   listTasks,
 			Category: "tasks",
-			Flags:    []cli.Flag{},
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "only list tasks in the checklist, in checklist order",
+				},
+				cli.StringSliceFlag{
+					Name:  "g, tag",
+					Usage: "only list tasks labeled with the tag (repeatable)",
+				},
+				cli.BoolFlag{
+					Name:  "d, deferred",
+					Usage: "include tasks that are deferred until a future start date",
+				},
+				cli.BoolFlag{
+					Name:  "a, assigned",
+					Usage: "only list tasks that are assigned to you",
+				},
+				cli.StringSliceFlag{
+					Name:  "F, field",
+					Usage: "only list tasks whose custom field matches name=value (repeatable)",
+				},
+				cli.BoolFlag{
+					Name:  "c, completed",
+					Usage: "only list tasks that are completed",
+				},
+				cli.BoolFlag{
+					Name:  "o, open",
+					Usage: "only list tasks that are not completed",
+				},
+				cli.BoolFlag{
+					Name:  "A, archived",
+					Usage: "only list tasks that are archived",
+				},
+				cli.BoolFlag{
+					Name:  "O, overdue",
+					Usage: "only list incomplete tasks whose deadline has passed",
+				},
+				cli.DurationFlag{
+					Name:  "deadline-before",
+					Usage: "only list tasks due before this much time from now",
+				},
+				cli.DurationFlag{
+					Name:  "deadline-after",
+					Usage: "only list tasks due after this much time from now",
+				},
+				cli.DurationFlag{
+					Name:  "created-after",
+					Usage: "only list tasks created within this much time ago",
+				},
+				cli.DurationFlag{
+					Name:  "created-before",
+					Usage: "only list tasks created more than this much time ago",
+				},
+				cli.DurationFlag{
+					Name:  "updated-after",
+					Usage: "only list tasks updated within this much time ago",
+				},
+				cli.DurationFlag{
+					Name:  "updated-before",
+					Usage: "only list tasks updated more than this much time ago",
+				},
+				cli.StringFlag{
+					Name:  "t, title",
+					Usage: "only list tasks whose title contains the text",
+				},
+				cli.StringSliceFlag{
+					Name:  "s, sort",
+					Usage: "sort by deadline, created, updated, title, priority, or position, prefix - for descending (repeatable)",
+				},
+				cli.IntFlag{
+					Name:  "p, page",
+					Usage: "only list the specified page rather than all tasks",
+				},
+				cli.IntFlag{
+					Name:  "n, per-page",
+					Usage: "number of tasks to fetch per page",
+				},
+			},
 		},
 		{
 			Name:     "task:create",
@@ -187,6 +307,38 @@ func main() {
 					Name:  "D, deadline",
 					Usage: "how much time in the future the deadline is (optional)",
 				},
+				cli.DurationFlag{
+					Name:  "S, start",
+					Usage: "how much time in the future to defer the task until (optional)",
+				},
+				cli.StringSliceFlag{
+					Name:  "g, tag",
+					Usage: "label the task with the tag (repeatable)",
+				},
+				cli.UintFlag{
+					Name:  "P, parent",
+					Usage: "id of the parent task to create a subtask (optional)",
+				},
+				cli.StringFlag{
+					Name:  "r, repeat",
+					Usage: "RRULE or daily, weekly, monthly to repeat the task (optional)",
+				},
+				cli.StringFlag{
+					Name:  "p, priority",
+					Usage: "none, low, medium, high, or critical (optional)",
+				},
+				cli.UintFlag{
+					Name:  "e, estimate",
+					Usage: "estimated effort of the task in points or minutes (optional)",
+				},
+				cli.StringFlag{
+					Name:  "a, assign",
+					Usage: "username of the user to assign the task to (optional)",
+				},
+				cli.StringSliceFlag{
+					Name:  "F, field",
+					Usage: "set a custom field as name=value, an empty value clears it (repeatable)",
+				},
 			},
 		},
 		{
@@ -217,31 +369,71 @@ func main() {
 					Name:  "c, completed",
 					Usage: "mark the task as completed",
 				},
+				cli.BoolFlag{
+					Name:  "o, open",
+					Usage: "reopen the task if it is completed",
+				},
 				cli.BoolFlag{
 					Name:  "a, archived",
 					Usage: "mark the task as archived",
 				},
+				cli.BoolFlag{
+					Name:  "unarchive",
+					Usage: "restore the task from the archive",
+				},
 				cli.StringFlag{
 					Name:  "t, title",
 					Usage: "title of the task",
 				},
 				cli.StringFlag{
 					Name:  "d, details",
-					Usage: "additional details of the task (optional)",
+					Usage: "additional details of the task, empty to clear them",
 				},
 				cli.UintFlag{
 					Name:  "l, list",
-					Usage: "list to associate task with (optional)",
+					Usage: "list to move the task to",
+				},
+				cli.BoolFlag{
+					Name:  "no-list",
+					Usage: "remove the task from its list",
 				},
 				cli.DurationFlag{
 					Name:  "D, deadline",
-					Usage: "how much time in the future the deadline is (optional)",
+					Usage: "how much time in the future the deadline is",
+				},
+				cli.BoolFlag{
+					Name:  "no-deadline",
+					Usage: "clear the deadline of the task",
+				},
+				cli.DurationFlag{
+					Name:  "S, start",
+					Usage: "how much time in the future to defer the task until, 0 to start now",
+				},
+				cli.StringSliceFlag{
+					Name:  "g, tag",
+					Usage: "replace the task labels with the tag (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "p, priority",
+					Usage: "none, low, medium, high, or critical",
+				},
+				cli.UintFlag{
+					Name:  "e, estimate",
+					Usage: "estimated effort of the task in points or minutes, 0 to clear it",
+				},
+				cli.StringFlag{
+					Name:  "U, assign",
+					Usage: "username of the user to assign the task to, empty to unassign it",
+				},
+				cli.StringSliceFlag{
+					Name:  "F, field",
+					Usage: "set a custom field as name=value, an empty value clears it (repeatable)",
 				},
 			},
 		},
 		{
 			Name:     "task:delete",
-			Usage:    "delete a task from the database",
+			Usage:    "move a task to the trash",
 			Before:   setupClientWithLogin,
 			Action:   deleteTask,
 			Category: "tasks",
@@ -250,6 +442,160 @@ func main() {
 					Name:  "i, id",
 					Usage: "id of the task to delete (required)",
 				},
+				cli.BoolFlag{
+					Name:  "C, cascade",
+					Usage: "also delete all of the subtasks of the task",
+				},
+			},
+		},
+		{
+			Name:     "task:block",
+			Usage:    "mark a task as blocked by another task",
+			Before:   setupClientWithLogin,
+			Action:   blockTask,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task that is blocked (required)",
+				},
+				cli.UintFlag{
+					Name:  "b, by",
+					Usage: "id of the task that must be done first (required)",
+				},
+			},
+		},
+		{
+			Name:     "task:unblock",
+			Usage:    "remove a dependency between two tasks",
+			Before:   setupClientWithLogin,
+			Action:   unblockTask,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task that is blocked (required)",
+				},
+				cli.UintFlag{
+					Name:  "b, by",
+					Usage: "id of the blocking task to remove (required)",
+				},
+			},
+		},
+		{
+			Name:     "task:comment",
+			Usage:    "comment on a task or list the comments on a task",
+			Before:   setupClientWithLogin,
+			Action:   commentTask,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to comment on (required)",
+				},
+				cli.StringFlag{
+					Name:  "m, message",
+					Usage: "text of the comment, if omitted the comments are listed",
+				},
+				cli.UintFlag{
+					Name:  "e, edit",
+					Usage: "id of your comment to replace with the message",
+				},
+				cli.UintFlag{
+					Name:  "D, delete",
+					Usage: "id of your comment to delete",
+				},
+			},
+		},
+		{
+			Name:      "task:attach",
+			Usage:     "upload files as attachments to a task",
+			ArgsUsage: "path [path ...]",
+			Before:    setupClientWithLogin,
+			Action:    attachTask,
+			Category:  "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to attach the files to (required)",
+				},
+			},
+		},
+		{
+			Name:     "task:attachments",
+			Usage:    "list, download, or delete the attachments of a task",
+			Before:   setupClientWithLogin,
+			Action:   taskAttachments,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task the attachments belong to (required)",
+				},
+				cli.UintFlag{
+					Name:  "d, download",
+					Usage: "id of the attachment to download",
+				},
+				cli.StringFlag{
+					Name:  "o, output",
+					Usage: "path to write the download to, defaults to the attachment filename",
+				},
+				cli.UintFlag{
+					Name:  "D, delete",
+					Usage: "id of the attachment to delete",
+				},
+			},
+		},
+		{
+			Name:     "task:history",
+			Usage:    "show who changed a task and when",
+			Before:   setupClientWithLogin,
+			Action:   taskHistory,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to show the history of (required)",
+				},
+			},
+		},
+		{
+			Name:     "task:move",
+			Usage:    "move a task before or after another task in its checklist",
+			Before:   setupClientWithLogin,
+			Action:   moveTask,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to move (required)",
+				},
+				cli.UintFlag{
+					Name:  "b, before",
+					Usage: "id of the task to place the task before",
+				},
+				cli.UintFlag{
+					Name:  "a, after",
+					Usage: "id of the task to place the task after",
+				},
+			},
+		},
+		{
+			Name:     "task:snooze",
+			Usage:    "hide a task from the task list until later",
+			Before:   setupClientWithLogin,
+			Action:   snoozeTask,
+			Category: "tasks",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to snooze (required)",
+				},
+				cli.StringFlag{
+					Name:  "f, for",
+					Usage: "how long to snooze the task, e.g. 4h, 3d, or 1w",
+					Value: "1d",
+				},
 			},
 		},
 		{
@@ -258,7 +604,20 @@ func main() {
 			Before:   setupClientWithLogin,
 			Action:   listChecklists,
 			Category: "lists",
-			Flags:    []cli.Flag{},
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "P, project",
+					Usage: "only list checklists in the project",
+				},
+				cli.IntFlag{
+					Name:  "p, page",
+					Usage: "only list the specified page rather than all checklists",
+				},
+				cli.IntFlag{
+					Name:  "n, per-page",
+					Usage: "number of checklists to fetch per page",
+				},
+			},
 		},
 		{
 			Name:     "list:create",
@@ -279,6 +638,10 @@ func main() {
 					Name:  "D, deadline",
 					Usage: "how much time in the future the deadline is (optional)",
 				},
+				cli.UintFlag{
+					Name:  "P, project",
+					Usage: "project to add the list to (optional)",
+				},
 			},
 		},
 		{
@@ -311,17 +674,29 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  "d, details",
-					Usage: "additional details of the list (optional)",
+					Usage: "additional details of the list, empty to clear them",
 				},
 				cli.DurationFlag{
 					Name:  "D, deadline",
-					Usage: "how much time in the future the deadline is (optional)",
+					Usage: "how much time in the future the deadline is",
+				},
+				cli.BoolFlag{
+					Name:  "no-deadline",
+					Usage: "clear the deadline of the list",
+				},
+				cli.UintFlag{
+					Name:  "P, project",
+					Usage: "project to move the list to",
+				},
+				cli.BoolFlag{
+					Name:  "no-project",
+					Usage: "remove the list from its project",
 				},
 			},
 		},
 		{
 			Name:     "list:delete",
-			Usage:    "delete a checklist from the database",
+			Usage:    "move a checklist and its tasks to the trash",
 			Before:   setupClientWithLogin,
 			Action:   deleteChecklist,
 			Category: "lists",
@@ -332,419 +707,2171 @@ func main() {
 				},
 			},
 		},
-	}
-
-	// Run the CLI program
-	app.Run(os.Args)
-}
-
-//===========================================================================
-// Server Commands
-//===========================================================================
-
-func serve(c *cli.Context) (err error) {
-	// The configuration is primarily loaded from the environment and defaults
-	var conf todos.Settings
-	if conf, err = todos.Config(); err != nil {
-		return cli.NewExitError(err, 1)
-	}
-
-	// Update the configuration from the CLI flags
-	if addr := c.String("addr"); addr != "" {
-		conf.Bind = addr
-	}
-
-	if port := c.Int("port"); port > 0 {
-		conf.Port = port
-	}
-
-	if useTLS := c.Bool("tls"); useTLS {
-		conf.UseTLS = useTLS
-	}
-
-	if domain := c.String("domain"); domain != "" {
-		conf.Domain = domain
-	}
-
-	// Create the API server
-	var api *todos.API
-	if api, err = todos.New(conf); err != nil {
-		return cli.NewExitError(err, 1)
-	}
-
-	// Run the API server
-	if err = api.Serve(); err != nil {
-		return cli.NewExitError(err, 1)
-	}
-	return nil
-}
-
-// characters for generating random passwords
-const charset = "abcdefghijklmnopqrstuvwxyz" + "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-
-func createSuperUser(c *cli.Context) (err error) {
+		{
+			Name:     "list:share",
+			Usage:    "share a checklist with another user or list its collaborators",
+			Before:   setupClientWithLogin,
+			Action:   shareChecklist,
+			Category: "lists",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the list to share (required)",
+				},
+				cli.StringFlag{
+					Name:  "u, user",
+					Usage: "username to share the list with, if omitted the collaborators are listed",
+				},
+				cli.StringFlag{
+					Name:  "r, role",
+					Usage: "role of the user on the list, viewer or editor",
+					Value: todos.RoleViewer,
+				},
+			},
+		},
+		{
+			Name:     "list:unshare",
+			Usage:    "stop sharing a checklist with a user",
+			Before:   setupClientWithLogin,
+			Action:   unshareChecklist,
+			Category: "lists",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the list to unshare (required)",
+				},
+				cli.StringFlag{
+					Name:  "u, user",
+					Usage: "username to stop sharing the list with (required)",
+				},
+			},
+		},
+		{
+			Name:     "field:list",
+			Usage:    "list the custom fields defined on a checklist",
+			Before:   setupClientWithLogin,
+			Action:   listFields,
+			Category: "fields",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "id of the list to get the fields for (required)",
+				},
+			},
+		},
+		{
+			Name:     "field:create",
+			Usage:    "add a custom field to a checklist",
+			Before:   setupClientWithLogin,
+			Action:   createField,
+			Category: "fields",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "id of the list to add the field to (required)",
+				},
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "name of the field (required)",
+				},
+				cli.StringFlag{
+					Name:  "t, type",
+					Usage: "text, number, date, enum, or boolean",
+					Value: todos.FieldText,
+				},
+				cli.StringSliceFlag{
+					Name:  "o, option",
+					Usage: "allowed value of an enum field (repeatable)",
+				},
+			},
+		},
+		{
+			Name:     "field:update",
+			Usage:    "rename a custom field or change its options",
+			Before:   setupClientWithLogin,
+			Action:   updateField,
+			Category: "fields",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "id of the list the field belongs to (required)",
+				},
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the field to update (required)",
+				},
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "new name of the field",
+				},
+				cli.StringSliceFlag{
+					Name:  "o, option",
+					Usage: "replace the allowed values of an enum field (repeatable)",
+				},
+			},
+		},
+		{
+			Name:     "field:delete",
+			Usage:    "remove a custom field and its values from a checklist",
+			Before:   setupClientWithLogin,
+			Action:   deleteField,
+			Category: "fields",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "id of the list the field belongs to (required)",
+				},
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the field to delete (required)",
+				},
+			},
+		},
+		{
+			Name:     "project:list",
+			Usage:    "list the projects stored in the server",
+			Before:   setupClientWithLogin,
+			Action:   listProjects,
+			Category: "projects",
+			Flags:    []cli.Flag{},
+		},
+		{
+			Name:     "project:create",
+			Usage:    "create a project to group checklists",
+			Before:   setupClientWithLogin,
+			Action:   createProject,
+			Category: "projects",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "t, title",
+					Usage: "title of the project",
+				},
+				cli.StringFlag{
+					Name:  "d, details",
+					Usage: "additional details of the project (optional)",
+				},
+				cli.DurationFlag{
+					Name:  "D, deadline",
+					Usage: "how much time in the future the deadline is (optional)",
+				},
+			},
+		},
+		{
+			Name:     "project:detail",
+			Usage:    "print the checklists and progress of a project",
+			Before:   setupClientWithLogin,
+			Action:   detailProject,
+			Category: "projects",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the project to get details for (required)",
+				},
+			},
+		},
+		{
+			Name:     "project:update",
+			Usage:    "update a project with new information",
+			Before:   setupClientWithLogin,
+			Action:   updateProject,
+			Category: "projects",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the project to update (required)",
+				},
+				cli.StringFlag{
+					Name:  "t, title",
+					Usage: "title of the project",
+				},
+				cli.StringFlag{
+					Name:  "d, details",
+					Usage: "additional details of the project (optional)",
+				},
+				cli.DurationFlag{
+					Name:  "D, deadline",
+					Usage: "how much time in the future the deadline is (optional)",
+				},
+			},
+		},
+		{
+			Name:     "project:delete",
+			Usage:    "delete a project, keeping its checklists",
+			Before:   setupClientWithLogin,
+			Action:   deleteProject,
+			Category: "projects",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the project to delete (required)",
+				},
+			},
+		},
+		{
+			Name:     "workspace:list",
+			Usage:    "list the workspaces you are a member of",
+			Before:   setupClientWithLogin,
+			Action:   listWorkspaces,
+			Category: "workspaces",
+		},
+		{
+			Name:     "workspace:create",
+			Usage:    "create a workspace for a team",
+			Before:   setupClientWithLogin,
+			Action:   createWorkspace,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "name of the workspace",
+				},
+			},
+		},
+		{
+			Name:     "workspace:detail",
+			Usage:    "print the members of a workspace",
+			Before:   setupClientWithLogin,
+			Action:   detailWorkspace,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace to get details for (required)",
+				},
+			},
+		},
+		{
+			Name:     "workspace:update",
+			Usage:    "rename a workspace",
+			Before:   setupClientWithLogin,
+			Action:   updateWorkspace,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace to update (required)",
+				},
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "new name of the workspace",
+				},
+			},
+		},
+		{
+			Name:     "workspace:delete",
+			Usage:    "delete an empty workspace",
+			Before:   setupClientWithLogin,
+			Action:   deleteWorkspace,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace to delete (required)",
+				},
+			},
+		},
+		{
+			Name:     "workspace:add",
+			Usage:    "add a member to a workspace or change their role",
+			Before:   setupClientWithLogin,
+			Action:   addMember,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace (required)",
+				},
+				cli.StringFlag{
+					Name:  "u, user",
+					Usage: "username of the member (required)",
+				},
+				cli.StringFlag{
+					Name:  "r, role",
+					Usage: "role of the user in the workspace, owner or member",
+					Value: todos.RoleMember,
+				},
+			},
+		},
+		{
+			Name:     "workspace:remove",
+			Usage:    "remove a member from a workspace",
+			Before:   setupClientWithLogin,
+			Action:   removeMember,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace (required)",
+				},
+				cli.StringFlag{
+					Name:  "u, user",
+					Usage: "username of the member to remove (required)",
+				},
+			},
+		},
+		{
+			Name:     "workspace:use",
+			Usage:    "set the default workspace, 0 uses your personal tasks and lists",
+			Before:   setupClient,
+			Action:   useWorkspace,
+			Category: "workspaces",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the workspace to work in",
+				},
+			},
+		},
+		{
+			Name:     "trash:list",
+			Usage:    "list the tasks and checklists in the trash",
+			Before:   setupClientWithLogin,
+			Action:   listTrash,
+			Category: "trash",
+			Flags:    []cli.Flag{},
+		},
+		{
+			Name:     "trash:restore",
+			Usage:    "restore a task or checklist from the trash",
+			Before:   setupClientWithLogin,
+			Action:   restoreTrash,
+			Category: "trash",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "t, task",
+					Usage: "id of the task to restore",
+				},
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "id of the checklist to restore",
+				},
+			},
+		},
+		{
+			Name:     "trash:empty",
+			Usage:    "permanently delete the tasks and checklists in the trash",
+			Before:   setupClientWithLogin,
+			Action:   emptyTrash,
+			Category: "trash",
+			Flags:    []cli.Flag{},
+		},
+		{
+			Name:     "template:list",
+			Usage:    "list the checklist templates stored in the server",
+			Before:   setupClientWithLogin,
+			Action:   listTemplates,
+			Category: "templates",
+			Flags:    []cli.Flag{},
+		},
+		{
+			Name:     "template:save",
+			Usage:    "save a checklist as a reusable template",
+			Before:   setupClientWithLogin,
+			Action:   saveTemplate,
+			Category: "templates",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the list to save as a template (required)",
+				},
+				cli.StringFlag{
+					Name:  "t, title",
+					Usage: "title of the template, defaults to the list title",
+				},
+			},
+		},
+		{
+			Name:     "template:detail",
+			Usage:    "print the tasks of a checklist template",
+			Before:   setupClientWithLogin,
+			Action:   detailTemplate,
+			Category: "templates",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the template to get details for (required)",
+				},
+			},
+		},
+		{
+			Name:     "template:use",
+			Usage:    "create a new checklist from a template",
+			Before:   setupClientWithLogin,
+			Action:   useTemplate,
+			Category: "templates",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the template to create the list from (required)",
+				},
+				cli.StringFlag{
+					Name:  "t, title",
+					Usage: "title of the new list, defaults to the template title",
+				},
+				cli.StringFlag{
+					Name:  "a, anchor",
+					Usage: "date (YYYY-MM-DD) or RFC3339 time deadlines are computed from, defaults to now",
+				},
+			},
+		},
+		{
+			Name:     "template:delete",
+			Usage:    "delete a checklist template",
+			Before:   setupClientWithLogin,
+			Action:   deleteTemplate,
+			Category: "templates",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the template to delete (required)",
+				},
+			},
+		},
+		{
+			Name:     "task:start",
+			Usage:    "start a timer on a task, stopping any running timer",
+			Before:   setupClientWithLogin,
+			Action:   startTimer,
+			Category: "time",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the task to track time on (required)",
+				},
+				cli.StringFlag{
+					Name:  "n, notes",
+					Usage: "notes about the work being done",
+				},
+			},
+		},
+		{
+			Name:     "task:stop",
+			Usage:    "stop the running timer",
+			Before:   setupClientWithLogin,
+			Action:   stopTimer,
+			Category: "time",
+		},
+		{
+			Name:     "time:report",
+			Usage:    "summarize tracked time by day and checklist",
+			Before:   setupClientWithLogin,
+			Action:   timeReport,
+			Category: "time",
+			Flags: []cli.Flag{
+				cli.DurationFlag{
+					Name:  "s, since",
+					Usage: "report time tracked within the duration before now",
+					Value: 7 * 24 * time.Hour,
+				},
+				cli.UintFlag{
+					Name:  "l, list",
+					Usage: "only report time tracked on tasks in the checklist",
+				},
+			},
+		},
+		{
+			Name:     "view:list",
+			Usage:    "list the saved views and the number of tasks in each",
+			Before:   setupClientWithLogin,
+			Action:   listViews,
+			Category: "views",
+			Flags:    []cli.Flag{},
+		},
+		{
+			Name:     "view:create",
+			Usage:    "save the task list filter and sort as a named view",
+			Before:   setupClientWithLogin,
+			Action:   createView,
+			Category: "views",
+			Flags: viewFlags(
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "name of the view (required)",
+				},
+			),
+		},
+		{
+			Name:      "view:run",
+			Usage:     "list the tasks of a saved view",
+			ArgsUsage: "[name]",
+			Before:    setupClientWithLogin,
+			Action:    runView,
+			Category:  "views",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the view to list, otherwise the view is found by name",
+				},
+				cli.IntFlag{
+					Name:  "p, page",
+					Usage: "only list the specified page rather than all tasks",
+				},
+				cli.IntFlag{
+					Name:  "n, per-page",
+					Usage: "number of tasks to fetch per page",
+				},
+			},
+		},
+		{
+			Name:      "view:update",
+			Usage:     "replace the filter and sort of a saved view",
+			ArgsUsage: "[name]",
+			Before:    setupClientWithLogin,
+			Action:    updateView,
+			Category:  "views",
+			Flags: viewFlags(
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the view to update, otherwise the view is found by name",
+				},
+				cli.StringFlag{
+					Name:  "n, name",
+					Usage: "rename the view",
+				},
+			),
+		},
+		{
+			Name:      "view:delete",
+			Usage:     "delete a saved view",
+			ArgsUsage: "[name]",
+			Before:    setupClientWithLogin,
+			Action:    deleteView,
+			Category:  "views",
+			Flags: []cli.Flag{
+				cli.UintFlag{
+					Name:  "i, id",
+					Usage: "id of the view to delete, otherwise the view is found by name",
+				},
+			},
+		},
+	}
+
+	// Run the CLI program
+	app.Run(os.Args)
+}
+
+//===========================================================================
+// Server Commands
+//===========================================================================
+
+func serve(c *cli.Context) (err error) {
+	// The configuration is primarily loaded from the environment and defaults
+	var conf todos.Settings
+	if conf, err = todos.Config(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Update the configuration from the CLI flags
+	if addr := c.String("addr"); addr != "" {
+		conf.Bind = addr
+	}
+
+	if port := c.Int("port"); port > 0 {
+		conf.Port = port
+	}
+
+	if useTLS := c.Bool("tls"); useTLS {
+		conf.UseTLS = useTLS
+	}
+
+	if domain := c.String("domain"); domain != "" {
+		conf.Domain = domain
+	}
+
+	// Create the API server
+	var api *todos.API
+	if api, err = todos.New(conf); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Run the API server
+	if err = api.Serve(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+// characters for generating random passwords
+const charset = "abcdefghijklmnopqrstuvwxyz" + "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func createSuperUser(c *cli.Context) (err error) {
+	var db *gorm.DB
+	if dburl := c.String("db"); dburl != "" {
+		if db, err = gorm.Open("postgres", dburl); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	} else {
+		return cli.NewExitError("specify $DATABASE_URL to create the admin user", 1)
+	}
+	defer db.Close()
+
+	username := c.String("username")
+	email := c.String("email")
+	password := ""
+
+	if c.Bool("generate") {
+		seed := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+		b := make([]byte, 8)
+		for i := range b {
+			b[i] = charset[seed.Intn(len(charset))]
+		}
+		password = string(b)
+	}
+
+	if username == "" {
+		username = client.Prompt("username", "")
+	}
+
+	if email == "" {
+		email = client.Prompt("email", "")
+	}
+
+	if password == "" {
+		password, err = client.PromptPassword("password", true, false)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	user := &todos.User{
+		Username: username,
+		Email:    email,
+		IsAdmin:  true,
+	}
+
+	// TODO: this should be a standalone call rather than modifying the struct
+	if user.Password, err = user.SetPassword(password); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Save the user to the database
+	if err = db.Create(user).Error; err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// If the password was generated, inform the user
+	if c.Bool("generate") {
+		fmt.Printf("%s:%s\n", username, password)
+	}
+	return nil
+}
+
+func cleanup(c *cli.Context) (err error) {
 	var db *gorm.DB
 	if dburl := c.String("db"); dburl != "" {
 		if db, err = gorm.Open("postgres", dburl); err != nil {
 			return cli.NewExitError(err, 1)
 		}
-	} else {
-		return cli.NewExitError("specify $DATABASE_URL to create the admin user", 1)
+	} else {
+		return cli.NewExitError("specify $DATABASE_URL to create the admin user", 1)
+	}
+	defer db.Close()
+
+	if !c.Bool("no-tokens") {
+		var rows int
+		if rows, err = todos.TokensCleanup(db); err != nil {
+			return cli.NewExitError(fmt.Errorf("could not clean up tokens: %s", err), 1)
+		}
+		fmt.Printf("- cleaned up %d tokens\n", rows)
+	}
+
+	if !c.Bool("no-trash") {
+		var storage todos.Storage
+		if storage, err = todos.NewLocalStorage(c.String("storage")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		var rows int
+		if rows, err = todos.TrashCleanup(db, storage, c.Duration("retention")); err != nil {
+			return cli.NewExitError(fmt.Errorf("could not purge trash: %s", err), 1)
+		}
+		fmt.Printf("- purged %d tasks and lists from the trash\n", rows)
+	}
+
+	return nil
+}
+
+//===========================================================================
+// Client Commands
+//===========================================================================
+
+var todoc *client.Client
+
+func setupClient(c *cli.Context) (err error) {
+	if todoc, err = client.New(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func setupClientWithLogin(c *cli.Context) (err error) {
+	if err = setupClient(c); err != nil {
+		return err
+	}
+
+	if err = todoc.CheckLogin(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func configure(c *cli.Context) (err error) {
+	if c.Bool("dir") {
+		var cdir string
+		if cdir, err = client.Configuration(); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		fmt.Println(cdir)
+		return
+	}
+
+	// Attempt to load the previous credentials to provide defaults if they exist.
+	creds := client.Credentials{}
+	creds.Load()
+
+	if vs := c.String("version"); vs != "" {
+		creds.Version = vs
+	} else {
+		if creds.Version == "" {
+			creds.Version = strings.Trim(todos.VersionURL(), "/")
+		}
+		creds.Version = client.Prompt("version", creds.Version)
+	}
+
+	if ep := c.String("endpoint"); ep != "" {
+		creds.Endpoint = ep
+	} else {
+		creds.Endpoint = client.Prompt("endpoint", creds.Endpoint)
+	}
+
+	if un := c.String("username"); un != "" {
+		creds.Username = un
+	} else {
+		creds.Username = client.Prompt("username", creds.Username)
+	}
+
+	if c.IsSet("workspace") {
+		creds.Workspace = c.Uint("workspace")
+	}
+
+	if c.Bool("password") {
+		if creds.Password, err = client.PromptPassword("password", true, true); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	// Write the configuration to disk
+	if err = creds.Dump(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return nil
+}
+
+func status(c *cli.Context) (err error) {
+	var data *todos.StatusResponse
+	if data, err = todoc.Status(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var out []byte
+	if out, err = yaml.Marshal(data); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
+
+func login(c *cli.Context) (err error) {
+	if err = todoc.Login(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return nil
+}
+
+func logout(c *cli.Context) (err error) {
+	if err = todoc.Logout(c.Bool("revoke")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	return nil
+}
+
+func overview(c *cli.Context) (err error) {
+	var data *todos.OverviewResponse
+	if data, err = todoc.Overview(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var out []byte
+	if out, err = yaml.Marshal(data); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
+
+func search(c *cli.Context) (err error) {
+	if c.NArg() == 0 {
+		return cli.NewExitError("specify the words to search for", 1)
+	}
+
+	in := &todos.SearchRequest{
+		Query: strings.Join(c.Args(), " "),
+		Type:  c.String("type"),
+		Limit: c.Int("limit"),
+	}
+
+	var out *todos.SearchResponse
+	if out, err = todoc.Search(in); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if len(out.Results) == 0 {
+		fmt.Println("no matching tasks or checklists")
+		return nil
+	}
+
+	// Highlight the matching words of the snippets in bold in the terminal
+	bold := strings.NewReplacer(todos.HighlightStart, "\033[1m", todos.HighlightStop, "\033[0m")
+	for _, result := range out.Results {
+		fmt.Printf("%s %d: %s\n", result.Type, result.ID, result.Title)
+		if result.Snippet != "" {
+			fmt.Printf("    %s\n", bold.Replace(result.Snippet))
+		}
+	}
+	return nil
+}
+
+func listTasks(c *cli.Context) (err error) {
+	var req *todos.ListTasksRequest
+	if req, err = taskFilter(c); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	req.Page = c.Int("page")
+	req.PerPage = c.Int("per-page")
+
+	// Deadlines are relative to the future, the other dates are relative to the past
+	now := time.Now()
+	req.DeadlineBefore = relativeTime(now, c.Duration("deadline-before"))
+	req.DeadlineAfter = relativeTime(now, c.Duration("deadline-after"))
+	req.CreatedBefore = relativeTime(now, -1*c.Duration("created-before"))
+	req.CreatedAfter = relativeTime(now, -1*c.Duration("created-after"))
+	req.UpdatedBefore = relativeTime(now, -1*c.Duration("updated-before"))
+	req.UpdatedAfter = relativeTime(now, -1*c.Duration("updated-after"))
+
+	// Unless a specific page is requested, follow the cursor through all of the pages
+	var data *todos.ListTasksResponse
+	var tasks []todos.Task
+	for {
+		if data, err = todoc.ListTasks(req); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		tasks = append(tasks, data.Tasks...)
+		if req.Page > 0 || data.NextCursor == "" {
+			break
+		}
+		req.Cursor = data.NextCursor
+	}
+
+	printTasks(tasks, req.Archived != nil)
+	if req.Page > 0 {
+		fmt.Printf("\npage %d of %d\n", data.Page, data.NumPages)
+	}
+	return nil
+}
+
+// taskFilter returns the list tasks request for the filter and sort flags that are
+// shared by the task list and saved views.
+func taskFilter(c *cli.Context) (req *todos.ListTasksRequest, err error) {
+	req = &todos.ListTasksRequest{
+		Checklist: c.Uint("list"),
+		Tags:      c.StringSlice("tag"),
+		Deferred:  c.Bool("deferred"),
+		Assigned:  c.Bool("assigned"),
+		Fields:    c.StringSlice("field"),
+		Overdue:   c.Bool("overdue"),
+		Title:     c.String("title"),
+		Sort:      c.StringSlice("sort"),
+	}
+
+	switch {
+	case c.Bool("completed") && c.Bool("open"):
+		return nil, errors.New("specify either completed or open tasks, not both")
+	case c.Bool("completed"):
+		req.Completed = boolPtr(true)
+	case c.Bool("open"):
+		req.Completed = boolPtr(false)
+	}
+
+	if c.Bool("archived") {
+		req.Archived = boolPtr(true)
+	}
+	return req, nil
+}
+
+// printTasks prints one line per task with its status, priority, and labels followed by
+// the open effort of the tasks. Archived tasks are skipped unless requested.
+func printTasks(tasks []todos.Task, archived bool) {
+	var effort uint
+	for _, item := range tasks {
+		if item.Archived && !archived {
+			continue
+		}
+
+		tags := ""
+		if len(item.Tags) > 0 {
+			names := make([]string, 0, len(item.Tags))
+			for _, tag := range item.Tags {
+				names = append(names, "#"+tag.Name)
+			}
+			tags = " " + strings.Join(names, " ")
+		}
+
+		marker := ""
+		if item.Priority > todos.PriorityNone {
+			marker = strings.Repeat("!", int(item.Priority)) + " "
+		}
+
+		if item.Repeat != "" {
+			tags += " ↻"
+		}
+
+		if item.Blocked {
+			tags += " (blocked)"
+		}
+
+		if item.StartAt != nil && item.StartAt.After(time.Now()) {
+			tags += " (deferred until " + item.StartAt.Local().Format("Jan 2 15:04") + ")"
+		}
+
+		if item.Comments > 0 {
+			tags += fmt.Sprintf(" [%d comments]", item.Comments)
+		}
+
+		if item.Assignee != "" {
+			tags += " @" + item.Assignee
+		}
+
+		if item.Estimate > 0 {
+			tags += fmt.Sprintf(" (est %d)", item.Estimate)
+			if !item.Completed {
+				effort += item.Estimate
+			}
+		}
+
+		if item.Completed {
+			fmt.Printf("☑ %d: %s%s%s\n", item.ID, marker, item.Title, tags)
+		} else {
+			fmt.Printf("☐ %d: %s%s%s\n", item.ID, marker, item.Title, tags)
+		}
+	}
+
+	if effort > 0 {
+		fmt.Printf("\nopen effort: %d\n", effort)
+	}
+}
+
+// boolPtr returns a pointer to the value for optional boolean filters.
+func boolPtr(val bool) *bool {
+	return &val
+}
+
+// relativeTime returns the time offset from now by the duration or nil if the duration
+// is zero, so that unspecified duration flags are not sent as filters.
+func relativeTime(now time.Time, d time.Duration) *time.Time {
+	if d == 0 {
+		return nil
+	}
+
+	ts := now.Add(d)
+	return &ts
+}
+
+func createTask(c *cli.Context) (err error) {
+	task := &todos.Task{
+		Title:   c.String("title"),
+		Details: c.String("details"),
+	}
+
+	if i := c.Uint("list"); i > 0 {
+		task.ChecklistID = &i
+	}
+
+	if d := c.Duration("deadline"); d > 0 {
+		deadline := time.Now().Add(d)
+		task.Deadline = &deadline
+	}
+
+	if d := c.Duration("start"); d > 0 {
+		startAt := time.Now().Add(d)
+		task.StartAt = &startAt
+	}
+
+	for _, name := range c.StringSlice("tag") {
+		task.Tags = append(task.Tags, todos.Tag{Name: name})
+	}
+
+	if p := c.String("priority"); p != "" {
+		if task.Priority, err = todos.ParsePriority(p); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+
+	task.Estimate = c.Uint("estimate")
+	task.Assignee = c.String("assign")
+
+	if task.Fields, err = parseFieldFlags(c.StringSlice("field")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if i := c.Uint("parent"); i > 0 {
+		task.ParentID = &i
+	}
+
+	if r := c.String("repeat"); r != "" {
+		var rule *todos.Recurrence
+		if rule, err = todos.ParseRecurrence(r); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		task.Repeat = rule.String()
+	}
+
+	var rep *todos.CreateTaskResponse
+	if rep, err = todoc.CreateTask(task); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("created task %d\n", rep.TaskID)
+	return nil
+}
+
+func detailTask(c *cli.Context) (err error) {
+	var data *todos.DetailTaskResponse
+	if data, err = todoc.DetailTask(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Print the subtasks as a tree rather than as nested yaml
+	children := data.Task.Children
+	data.Task.Children = nil
+
+	var out []byte
+	if out, err = yaml.Marshal(data.Task); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Print(string(out))
+
+	if len(children) > 0 {
+		fmt.Printf("subtasks: %d/%d done\n", data.Task.SubtasksCompleted, data.Task.Subtasks)
+		printTaskTree(children, "")
+	}
+	return nil
+}
+
+// printTaskTree prints the subtasks with box drawing characters to show nesting.
+func printTaskTree(tasks []todos.Task, prefix string) {
+	for i, task := range tasks {
+		branch, indent := "├── ", "│   "
+		if i == len(tasks)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		check := "☐"
+		if task.Completed {
+			check = "☑"
+		}
+
+		progress := ""
+		if task.Subtasks > 0 {
+			progress = fmt.Sprintf(" (%d/%d)", task.SubtasksCompleted, task.Subtasks)
+		}
+
+		fmt.Printf("%s%s%s %d: %s%s\n", prefix, branch, check, task.ID, task.Title, progress)
+		printTaskTree(task.Children, prefix+indent)
+	}
+}
+
+func updateTask(c *cli.Context) (err error) {
+	// Only the flags that are specified are sent so that other fields are not modified
+	patch := make(todos.UpdateTaskRequest)
+	for _, name := range []string{"title", "details"} {
+		if c.IsSet(name) {
+			patch[name] = c.String(name)
+		}
+	}
+
+	switch {
+	case c.Bool("completed") && c.Bool("open"):
+		return cli.NewExitError("specify either completed or open, not both", 1)
+	case c.Bool("completed"):
+		patch["completed"] = true
+	case c.Bool("open"):
+		patch["completed"] = false
+	}
+
+	switch {
+	case c.Bool("archived") && c.Bool("unarchive"):
+		return cli.NewExitError("specify either archived or unarchive, not both", 1)
+	case c.Bool("archived"):
+		patch["archived"] = true
+	case c.Bool("unarchive"):
+		patch["archived"] = false
+	}
+
+	switch {
+	case c.IsSet("list") && c.Bool("no-list"):
+		return cli.NewExitError("specify either a list or no list, not both", 1)
+	case c.IsSet("list"):
+		patch["checklist"] = c.Uint("list")
+	case c.Bool("no-list"):
+		patch["checklist"] = nil
+	}
+
+	switch {
+	case c.IsSet("deadline") && c.Bool("no-deadline"):
+		return cli.NewExitError("specify either a deadline or no deadline, not both", 1)
+	case c.IsSet("deadline"):
+		patch["deadline"] = time.Now().Add(c.Duration("deadline"))
+	case c.Bool("no-deadline"):
+		patch["deadline"] = nil
+	}
+
+	if c.IsSet("start") {
+		if d := c.Duration("start"); d > 0 {
+			patch["start"] = time.Now().Add(d)
+		} else {
+			patch["start"] = nil
+		}
+	}
+
+	if c.IsSet("tag") {
+		patch["tags"] = c.StringSlice("tag")
+	}
+
+	if c.IsSet("priority") {
+		patch["priority"] = c.String("priority")
+	}
+
+	if c.IsSet("estimate") {
+		patch["estimate"] = c.Uint("estimate")
+	}
+
+	if c.IsSet("assign") {
+		if assignee := c.String("assign"); assignee != "" {
+			patch["assignee"] = assignee
+		} else {
+			patch["assignee"] = nil
+		}
+	}
+
+	var fields map[string]interface{}
+	if fields, err = parseFieldFlags(c.StringSlice("field")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if len(fields) > 0 {
+		patch["fields"] = fields
+	}
+
+	if len(patch) == 0 {
+		return cli.NewExitError("specify at least one field of the task to update", 1)
+	}
+
+	if _, err = todoc.UpdateTask(c.Uint("id"), patch); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+// parseFieldFlags converts name=value flags into custom field values for a task. The
+// server validates the string values against the field type, an empty value is sent
+// as null so that the value is removed from the task.
+func parseFieldFlags(flags []string) (fields map[string]interface{}, err error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	fields = make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		parts := strings.SplitN(flag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("could not parse field %q, specify as name=value", flag)
+		}
+
+		if parts[1] == "" {
+			fields[parts[0]] = nil
+		} else {
+			fields[parts[0]] = parts[1]
+		}
+	}
+	return fields, nil
+}
+
+func deleteTask(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteTask(c.Uint("id"), c.Bool("cascade")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func blockTask(c *cli.Context) (err error) {
+	if _, err = todoc.CreateDependency(c.Uint("id"), c.Uint("by")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func unblockTask(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteDependency(c.Uint("id"), c.Uint("by")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func commentTask(c *cli.Context) (err error) {
+	id := c.Uint("id")
+	switch {
+	case c.Uint("delete") > 0:
+		if _, err = todoc.DeleteComment(id, c.Uint("delete")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	case c.Uint("edit") > 0:
+		if _, err = todoc.UpdateComment(id, c.Uint("edit"), c.String("message")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
+	case c.String("message") != "":
+		if _, err = todoc.CreateComment(id, c.String("message")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
 	}
-	defer db.Close()
 
-	username := c.String("username")
-	email := c.String("email")
-	password := ""
+	var out *todos.ListCommentsResponse
+	if out, err = todoc.ListComments(id); err != nil {
+		return cli.NewExitError(err, 1)
+	}
 
-	if c.Bool("generate") {
-		seed := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, comment := range out.Comments {
+		fmt.Printf("%d: %s on %s\n%s\n\n", comment.ID, comment.Username, comment.CreatedAt.Format("Jan 02, 2006 15:04"), comment.Text)
+	}
+	return nil
+}
 
-		b := make([]byte, 8)
-		for i := range b {
-			b[i] = charset[seed.Intn(len(charset))]
+func attachTask(c *cli.Context) (err error) {
+	if c.NArg() == 0 {
+		return cli.NewExitError("specify at least one file to attach", 1)
+	}
+
+	for _, path := range c.Args() {
+		var out *todos.CreateAttachmentResponse
+		if out, err = todoc.CreateAttachment(c.Uint("id"), path); err != nil {
+			return cli.NewExitError(err, 1)
 		}
-		password = string(b)
+		fmt.Printf("%d: %s (%s, %d bytes)\n", out.Attachment.ID, out.Attachment.Filename, out.Attachment.ContentType, out.Attachment.Size)
+	}
+	return nil
+}
+
+func taskAttachments(c *cli.Context) (err error) {
+	id := c.Uint("id")
+	if attachment := c.Uint("delete"); attachment > 0 {
+		if _, err = todoc.DeleteAttachment(id, attachment); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		return nil
 	}
 
-	if username == "" {
-		username = client.Prompt("username", "")
+	if attachment := c.Uint("download"); attachment > 0 {
+		return downloadAttachment(id, attachment, c.String("output"))
 	}
 
-	if email == "" {
-		email = client.Prompt("email", "")
+	var out *todos.ListAttachmentsResponse
+	if out, err = todoc.ListAttachments(id); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 
-	if password == "" {
-		password, err = client.PromptPassword("password", true, false)
-		if err != nil {
+	for _, item := range out.Attachments {
+		fmt.Printf("%d: %s (%s, %d bytes)\n", item.ID, item.Filename, item.ContentType, item.Size)
+	}
+	return nil
+}
+
+// downloadAttachment writes the attachment to the output path or to a file in the
+// current directory named after the attachment if no output is specified.
+func downloadAttachment(id, attachment uint, output string) (err error) {
+	buf := &bytes.Buffer{}
+	var filename string
+	if filename, err = todoc.DownloadAttachment(id, attachment, buf); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if output == "" {
+		if output = filepath.Base(filename); output == "" || output == "." || output == "/" {
+			output = fmt.Sprintf("attachment-%d", attachment)
+		}
+	}
+
+	if err = ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Printf("downloaded %s\n", output)
+	return nil
+}
+
+func taskHistory(c *cli.Context) (err error) {
+	var out *todos.HistoryResponse
+	if out, err = todoc.TaskHistory(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	printHistory(out.History)
+	return nil
+}
+
+// printHistory prints each history entry followed by the fields that were changed.
+func printHistory(history []todos.History) {
+	format := func(val interface{}) string {
+		if val == nil {
+			return "∅"
+		}
+		return fmt.Sprintf("%v", val)
+	}
+
+	for _, entry := range history {
+		fmt.Printf("%s %s %sd %s\n", entry.CreatedAt.Format("2006-01-02 15:04"), entry.Username, entry.Action, entry.ObjectType)
+		for _, change := range entry.Changes {
+			fmt.Printf("  %s: %s → %s\n", change.Field, format(change.Old), format(change.New))
+		}
+	}
+}
+
+func moveTask(c *cli.Context) (err error) {
+	in := &todos.MoveTaskRequest{
+		Before: c.Uint("before"),
+		After:  c.Uint("after"),
+	}
+
+	if (in.Before == 0) == (in.After == 0) {
+		return cli.NewExitError("specify exactly one of --before or --after", 1)
+	}
+
+	if _, err = todoc.MoveTask(c.Uint("id"), in); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func startTimer(c *cli.Context) (err error) {
+	in := &todos.StartTimerRequest{
+		TaskID: c.Uint("id"),
+		Notes:  c.String("notes"),
+	}
+
+	var out *todos.StartTimerResponse
+	if out, err = todoc.StartTimer(in); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if out.Stopped != nil {
+		fmt.Printf("stopped timer on %d: %s after %s\n", out.Stopped.TaskID, out.Stopped.TaskTitle, formatSeconds(out.Stopped.Seconds))
+	}
+	fmt.Printf("started timer on %d\n", out.TimeEntry.TaskID)
+	return nil
+}
+
+func stopTimer(c *cli.Context) (err error) {
+	var out *todos.TimeEntryResponse
+	if out, err = todoc.StopTimer(); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("stopped timer on %d: %s after %s\n", out.TimeEntry.TaskID, out.TimeEntry.TaskTitle, formatSeconds(out.TimeEntry.Seconds))
+	return nil
+}
+
+func timeReport(c *cli.Context) (err error) {
+	now := time.Now()
+	in := &todos.ListTimeEntriesRequest{
+		Checklist: c.Uint("list"),
+		From:      now.Add(-1 * c.Duration("since")),
+	}
+
+	var out *todos.ListTimeEntriesResponse
+	if out, err = todoc.ListTimeEntries(in); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	// Fetch the checklist titles from all pages to label the report
+	titles := make(map[uint]string)
+	req := &todos.ListChecklistsRequest{PerPage: todos.MaxPageSize}
+	for {
+		var lists *todos.ListChecklistsResponse
+		if lists, err = todoc.ListChecklists(req); err != nil {
 			return cli.NewExitError(err, 1)
 		}
+
+		for _, list := range lists.Checklists {
+			titles[list.ID] = list.Title
+		}
+
+		if lists.NextCursor == "" {
+			break
+		}
+		req.Cursor = lists.NextCursor
 	}
 
-	user := &todos.User{
-		Username: username,
-		Email:    email,
-		IsAdmin:  true,
+	// Summarize the entries by local day then by checklist, entries are ordered by start
+	var days []string
+	totals := make(map[string]int64)
+	checklists := make(map[string]map[string]int64)
+	for _, entry := range out.TimeEntries {
+		day := entry.Started.Local().Format("2006-01-02 Mon")
+		if _, ok := checklists[day]; !ok {
+			days = append(days, day)
+			checklists[day] = make(map[string]int64)
+		}
+
+		title := "(no list)"
+		if entry.ChecklistID != nil {
+			title = titles[*entry.ChecklistID]
+		}
+
+		elapsed := entry.Elapsed(now)
+		totals[day] += elapsed
+		checklists[day][title] += elapsed
 	}
 
-	// TODO: this should be a standalone call rather than modifying the struct
-	if user.Password, err = user.SetPassword(password); err != nil {
+	for _, day := range days {
+		fmt.Printf("%s  %s\n", day, formatSeconds(totals[day]))
+
+		names := make([]string, 0, len(checklists[day]))
+		for title := range checklists[day] {
+			names = append(names, title)
+		}
+		sort.Strings(names)
+
+		for _, title := range names {
+			fmt.Printf("  %-30s %s\n", title, formatSeconds(checklists[day][title]))
+		}
+	}
+
+	fmt.Printf("total: %s\n", formatSeconds(out.Total))
+	return nil
+}
+
+// formatSeconds formats a number of seconds as hours and minutes, e.g. 1h05m.
+func formatSeconds(seconds int64) string {
+	minutes := (seconds + 30) / 60
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+func snoozeTask(c *cli.Context) (err error) {
+	if _, err = todos.ParseSnooze(c.String("for")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var rep *todos.SnoozeTaskResponse
+	if rep, err = todoc.SnoozeTask(c.Uint("id"), c.String("for")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("task %d snoozed until %s\n", c.Uint("id"), rep.StartAt.Local().Format(time.RFC1123))
+	return nil
+}
+
+func listChecklists(c *cli.Context) (err error) {
+	in := &todos.ListChecklistsRequest{
+		Project: c.Uint("project"),
+		Page:    c.Int("page"),
+		PerPage: c.Int("per-page"),
+	}
+
+	// Unless a specific page is requested, follow the cursor through all of the pages
+	var out *todos.ListChecklistsResponse
+	var lists []todos.Checklist
+	for {
+		if out, err = todoc.ListChecklists(in); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		lists = append(lists, out.Checklists...)
+		if in.Page > 0 || out.NextCursor == "" {
+			break
+		}
+		in.Cursor = out.NextCursor
+	}
+
+	for _, item := range lists {
+		check := "☐"
+		if item.Done {
+			check = "☑"
+		}
+
+		progress := fmt.Sprintf("%d/%d done", item.Completed, item.Size-item.Archived)
+		if item.Effort > 0 {
+			progress += fmt.Sprintf(", %d/%d effort remaining", item.EffortRemaining, item.Effort)
+		}
+		if item.Role != "" && item.Role != todos.RoleOwner {
+			progress += ", shared as " + item.Role
+		}
+		fmt.Printf("%s %d: %s (%s)\n", check, item.ID, item.Title, progress)
+	}
+
+	if in.Page > 0 {
+		fmt.Printf("\npage %d of %d\n", out.Page, out.NumPages)
+	}
+	return nil
+}
+
+func createChecklist(c *cli.Context) (err error) {
+	checklist := &todos.Checklist{
+		Title:   c.String("title"),
+		Details: c.String("details"),
+	}
+
+	if d := c.Duration("deadline"); d > 0 {
+		deadline := time.Now().Add(d)
+		checklist.Deadline = &deadline
+	}
+
+	if p := c.Uint("project"); p > 0 {
+		checklist.ProjectID = &p
+	}
+
+	var rep *todos.CreateChecklistResponse
+	if rep, err = todoc.CreateChecklist(checklist); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("created checklist %d\n", rep.ChecklistID)
+	return nil
+}
+
+func detailChecklist(c *cli.Context) (err error) {
+	var out *todos.DetailChecklistResponse
+	if out, err = todoc.DetailChecklist(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var data []byte
+	if data, err = yaml.Marshal(out); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func updateChecklist(c *cli.Context) (err error) {
+	// Only the flags that are specified are sent so that other fields are not modified
+	patch := make(todos.UpdateChecklistRequest)
+	for _, name := range []string{"title", "details"} {
+		if c.IsSet(name) {
+			patch[name] = c.String(name)
+		}
+	}
+
+	switch {
+	case c.IsSet("deadline") && c.Bool("no-deadline"):
+		return cli.NewExitError("specify either a deadline or no deadline, not both", 1)
+	case c.IsSet("deadline"):
+		patch["deadline"] = time.Now().Add(c.Duration("deadline"))
+	case c.Bool("no-deadline"):
+		patch["deadline"] = nil
+	}
+
+	switch {
+	case c.IsSet("project") && c.Bool("no-project"):
+		return cli.NewExitError("specify either a project or no project, not both", 1)
+	case c.IsSet("project"):
+		patch["project"] = c.Uint("project")
+	case c.Bool("no-project"):
+		patch["project"] = nil
+	}
+
+	if len(patch) == 0 {
+		return cli.NewExitError("specify at least one field of the list to update", 1)
+	}
+
+	if _, err = todoc.UpdateChecklist(c.Uint("id"), patch); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func deleteChecklist(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteChecklist(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func shareChecklist(c *cli.Context) (err error) {
+	if c.String("user") == "" {
+		var out *todos.ListSharesResponse
+		if out, err = todoc.ListShares(c.Uint("id")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		for _, share := range out.Shares {
+			fmt.Printf("%s: %s\n", share.Username, share.Role)
+		}
+		return nil
+	}
+
+	if _, err = todoc.ShareChecklist(c.Uint("id"), c.String("user"), c.String("role")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func unshareChecklist(c *cli.Context) (err error) {
+	if _, err = todoc.UnshareChecklist(c.Uint("id"), c.String("user")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func listFields(c *cli.Context) (err error) {
+	var out *todos.ListFieldsResponse
+	if out, err = todoc.ListFields(c.Uint("list")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	for _, field := range out.Fields {
+		if len(field.Options) > 0 {
+			fmt.Printf("%d: %s (%s: %s)\n", field.ID, field.Name, field.Type, strings.Join(field.Options, ", "))
+		} else {
+			fmt.Printf("%d: %s (%s)\n", field.ID, field.Name, field.Type)
+		}
+	}
+	return nil
+}
+
+func createField(c *cli.Context) (err error) {
+	field := &todos.CustomField{
+		Name:    c.String("name"),
+		Type:    c.String("type"),
+		Options: c.StringSlice("option"),
+	}
+
+	var rep *todos.CreateFieldResponse
+	if rep, err = todoc.CreateField(c.Uint("list"), field); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	fmt.Printf("created field %d\n", rep.FieldID)
+	return nil
+}
+
+func updateField(c *cli.Context) (err error) {
+	// The field is replaced on update so start from the current definition of the field
+	var out *todos.ListFieldsResponse
+	if out, err = todoc.ListFields(c.Uint("list")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	// Save the user to the database
-	if err = db.Create(user).Error; err != nil {
+	var field *todos.CustomField
+	for i := range out.Fields {
+		if out.Fields[i].ID == c.Uint("id") {
+			field = &out.Fields[i]
+			break
+		}
+	}
+
+	if field == nil {
+		return cli.NewExitError(fmt.Errorf("list %d has no field with id %d", c.Uint("list"), c.Uint("id")), 1)
+	}
+
+	if name := c.String("name"); name != "" {
+		field.Name = name
+	}
+
+	if options := c.StringSlice("option"); len(options) > 0 {
+		field.Options = options
+	}
+
+	if _, err = todoc.UpdateField(c.Uint("list"), field.ID, field); err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	return nil
+}
 
-	// If the password was generated, inform the user
-	if c.Bool("generate") {
-		fmt.Printf("%s:%s\n", username, password)
+func deleteField(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteField(c.Uint("list"), c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 	return nil
 }
 
-func cleanup(c *cli.Context) (err error) {
-	var db *gorm.DB
-	if dburl := c.String("db"); dburl != "" {
-		if db, err = gorm.Open("postgres", dburl); err != nil {
-			return cli.NewExitError(err, 1)
-		}
-	} else {
-		return cli.NewExitError("specify $DATABASE_URL to create the admin user", 1)
+func listProjects(c *cli.Context) (err error) {
+	var out *todos.ListProjectsResponse
+	if out, err = todoc.ListProjects(); err != nil {
+		return cli.NewExitError(err, 1)
 	}
-	defer db.Close()
 
-	if !c.Bool("no-tokens") {
-		var rows int
-		if rows, err = todos.TokensCleanup(db); err != nil {
-			return cli.NewExitError(fmt.Errorf("could not clean up tokens: %s", err), 1)
-		}
-		fmt.Printf("- cleaned up %d tokens\n", rows)
+	for _, item := range out.Projects {
+		fmt.Printf("%d: %s (%s)\n", item.ID, item.Title, projectProgress(item))
 	}
-
 	return nil
 }
 
-//===========================================================================
-// Client Commands
-//===========================================================================
-
-var todoc *client.Client
+// projectProgress summarizes how many of the open tasks in a project are done.
+func projectProgress(project todos.Project) string {
+	return fmt.Sprintf("%d lists, %d/%d done", project.NumChecklists, project.Completed, project.Size-project.Archived)
+}
 
-func setupClient(c *cli.Context) (err error) {
-	if todoc, err = client.New(); err != nil {
-		return cli.NewExitError(err, 1)
+func createProject(c *cli.Context) (err error) {
+	project := &todos.Project{
+		Title:   c.String("title"),
+		Details: c.String("details"),
 	}
-	return nil
-}
 
-func setupClientWithLogin(c *cli.Context) (err error) {
-	if err = setupClient(c); err != nil {
-		return err
+	if d := c.Duration("deadline"); d > 0 {
+		deadline := time.Now().Add(d)
+		project.Deadline = &deadline
 	}
 
-	if err = todoc.CheckLogin(); err != nil {
+	var rep *todos.CreateProjectResponse
+	if rep, err = todoc.CreateProject(project); err != nil {
 		return cli.NewExitError(err, 1)
 	}
+
+	fmt.Printf("created project %d\n", rep.ProjectID)
 	return nil
 }
 
-func configure(c *cli.Context) (err error) {
-	if c.Bool("dir") {
-		var cdir string
-		if cdir, err = client.Configuration(); err != nil {
-			return cli.NewExitError(err, 1)
-		}
-		fmt.Println(cdir)
-		return
+func detailProject(c *cli.Context) (err error) {
+	var out *todos.DetailProjectResponse
+	if out, err = todoc.DetailProject(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 
-	// Attempt to load the previous credentials to provide defaults if they exist.
-	creds := client.Credentials{}
-	creds.Load()
+	project := out.Project
+	fmt.Printf("%d: %s (%s)\n", project.ID, project.Title, projectProgress(project))
+	if project.Details != "" {
+		fmt.Println(project.Details)
+	}
 
-	if vs := c.String("version"); vs != "" {
-		creds.Version = vs
-	} else {
-		if creds.Version == "" {
-			creds.Version = strings.Trim(todos.VersionURL(), "/")
+	for _, list := range project.Checklists {
+		check := "☐"
+		if list.Done {
+			check = "☑"
 		}
-		creds.Version = client.Prompt("version", creds.Version)
+		fmt.Printf("  %s %d: %s (%d/%d done)\n", check, list.ID, list.Title, list.Completed, list.Size-list.Archived)
 	}
+	return nil
+}
 
-	if ep := c.String("endpoint"); ep != "" {
-		creds.Endpoint = ep
-	} else {
-		creds.Endpoint = client.Prompt("endpoint", creds.Endpoint)
+func updateProject(c *cli.Context) (err error) {
+	project := &todos.Project{
+		Title:   c.String("title"),
+		Details: c.String("details"),
 	}
 
-	if un := c.String("username"); un != "" {
-		creds.Username = un
-	} else {
-		creds.Username = client.Prompt("username", creds.Username)
+	if d := c.Duration("deadline"); d > 0 {
+		deadline := time.Now().Add(d)
+		project.Deadline = &deadline
 	}
 
-	if c.Bool("password") {
-		if creds.Password, err = client.PromptPassword("password", true, true); err != nil {
-			return cli.NewExitError(err, 1)
-		}
+	if _, err = todoc.UpdateProject(c.Uint("id"), project); err != nil {
+		return cli.NewExitError(err, 1)
 	}
+	return nil
+}
 
-	// Write the configuration to disk
-	if err = creds.Dump(); err != nil {
+func deleteProject(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteProject(c.Uint("id")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
-
 	return nil
 }
 
-func status(c *cli.Context) (err error) {
-	var data *todos.StatusResponse
-	if data, err = todoc.Status(); err != nil {
+func listWorkspaces(c *cli.Context) (err error) {
+	var out *todos.ListWorkspacesResponse
+	if out, err = todoc.ListWorkspaces(); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	var out []byte
-	if out, err = yaml.Marshal(data); err != nil {
+	for _, item := range out.Workspaces {
+		if item.Role != "" {
+			fmt.Printf("%d: %s (%s)\n", item.ID, item.Name, item.Role)
+		} else {
+			fmt.Printf("%d: %s\n", item.ID, item.Name)
+		}
+	}
+	return nil
+}
+
+func createWorkspace(c *cli.Context) (err error) {
+	var rep *todos.CreateWorkspaceResponse
+	if rep, err = todoc.CreateWorkspace(&todos.Workspace{Name: c.String("name")}); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	fmt.Print(string(out))
+	fmt.Printf("created workspace %d\n", rep.WorkspaceID)
 	return nil
 }
 
-func login(c *cli.Context) (err error) {
-	if err = todoc.Login(); err != nil {
+func detailWorkspace(c *cli.Context) (err error) {
+	var out *todos.DetailWorkspaceResponse
+	if out, err = todoc.DetailWorkspace(c.Uint("id")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
+	fmt.Printf("%d: %s\n", out.Workspace.ID, out.Workspace.Name)
+	for _, member := range out.Workspace.Members {
+		fmt.Printf("  %s: %s\n", member.Username, member.Role)
+	}
 	return nil
 }
 
-func logout(c *cli.Context) (err error) {
-	if err = todoc.Logout(c.Bool("revoke")); err != nil {
+func updateWorkspace(c *cli.Context) (err error) {
+	if _, err = todoc.UpdateWorkspace(c.Uint("id"), &todos.Workspace{Name: c.String("name")}); err != nil {
 		return cli.NewExitError(err, 1)
 	}
-
 	return nil
 }
 
-func overview(c *cli.Context) (err error) {
-	var data *todos.OverviewResponse
-	if data, err = todoc.Overview(); err != nil {
+func deleteWorkspace(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteWorkspace(c.Uint("id")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	return nil
+}
 
-	var out []byte
-	if out, err = yaml.Marshal(data); err != nil {
+func addMember(c *cli.Context) (err error) {
+	if _, err = todoc.AddMember(c.Uint("id"), c.String("user"), c.String("role")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	return nil
+}
 
-	fmt.Print(string(out))
+func removeMember(c *cli.Context) (err error) {
+	if _, err = todoc.RemoveMember(c.Uint("id"), c.String("user")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
 	return nil
 }
 
-func listTasks(c *cli.Context) (err error) {
-	// TODO: add user input for filters and pagination
-	req := &todos.ListTasksRequest{}
+func useWorkspace(c *cli.Context) (err error) {
+	if err = todoc.UseWorkspace(c.Uint("id")); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
 
-	var data *todos.ListTasksResponse
-	if data, err = todoc.ListTasks(req); err != nil {
+func listTrash(c *cli.Context) (err error) {
+	var out *todos.ListTrashResponse
+	if out, err = todoc.ListTrash(); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	for _, item := range data.Tasks {
-		if item.Archived {
-			continue
-		}
+	if len(out.Tasks) == 0 && len(out.Checklists) == 0 {
+		fmt.Println("the trash is empty")
+		return nil
+	}
 
-		if item.Completed {
-			fmt.Printf("☑ %d: %s\n", item.ID, item.Title)
-		} else {
-			fmt.Printf("☐ %d: %s\n", item.ID, item.Title)
-		}
+	for _, item := range out.Checklists {
+		fmt.Printf("list %d: %s (deleted %s)\n", item.ID, item.Title, item.DeletedAt.Local().Format("Jan 2 15:04"))
 	}
 
+	for _, item := range out.Tasks {
+		fmt.Printf("task %d: %s (deleted %s)\n", item.ID, item.Title, item.DeletedAt.Local().Format("Jan 2 15:04"))
+	}
 	return nil
 }
 
-func createTask(c *cli.Context) (err error) {
-	task := &todos.Task{
-		Title:   c.String("title"),
-		Details: c.String("details"),
+func restoreTrash(c *cli.Context) (err error) {
+	switch {
+	case c.Uint("task") > 0 && c.Uint("list") > 0:
+		return cli.NewExitError("specify either a task or a list to restore, not both", 1)
+	case c.Uint("task") > 0:
+		if _, err = todoc.RestoreTask(c.Uint("task")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	case c.Uint("list") > 0:
+		if _, err = todoc.RestoreChecklist(c.Uint("list")); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	default:
+		return cli.NewExitError("specify a task or a list to restore", 1)
 	}
+	return nil
+}
 
-	if i := c.Uint("list"); i > 0 {
-		task.ChecklistID = &i
+func emptyTrash(c *cli.Context) (err error) {
+	var rep *todos.EmptyTrashResponse
+	if rep, err = todoc.EmptyTrash(); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 
-	if d := c.Duration("deadline"); d > 0 {
-		deadline := time.Now().Add(d)
-		task.Deadline = &deadline
-	}
+	fmt.Printf("permanently deleted %d tasks and %d lists\n", rep.Tasks, rep.Checklists)
+	return nil
+}
 
-	var rep *todos.CreateTaskResponse
-	if rep, err = todoc.CreateTask(task); err != nil {
+func listTemplates(c *cli.Context) (err error) {
+	var out *todos.ListTemplatesResponse
+	if out, err = todoc.ListTemplates(); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	fmt.Printf("created task %d\n", rep.TaskID)
+	for _, item := range out.Templates {
+		fmt.Printf("%d: %s\n", item.ID, item.Title)
+	}
 	return nil
 }
 
-func detailTask(c *cli.Context) (err error) {
-	var data *todos.DetailTaskResponse
-	if data, err = todoc.DetailTask(c.Uint("id")); err != nil {
+func saveTemplate(c *cli.Context) (err error) {
+	in := &todos.SaveTemplateRequest{Title: c.String("title")}
+
+	var rep *todos.CreateTemplateResponse
+	if rep, err = todoc.SaveTemplate(c.Uint("id"), in); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	var out []byte
-	if out, err = yaml.Marshal(data.Task); err != nil {
+	fmt.Printf("created template %d\n", rep.TemplateID)
+	return nil
+}
+
+func detailTemplate(c *cli.Context) (err error) {
+	var out *todos.DetailTemplateResponse
+	if out, err = todoc.DetailTemplate(c.Uint("id")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
-	fmt.Print(string(out))
+
+	fmt.Printf("%d: %s%s\n", out.Template.ID, out.Template.Title, formatOffset(out.Template.DeadlineOffset))
+	for _, task := range out.Template.Tasks {
+		fmt.Printf("  ☐ %s%s\n", task.Title, formatOffset(task.DeadlineOffset))
+	}
 	return nil
 }
 
-func updateTask(c *cli.Context) (err error) {
-	task := &todos.Task{
-		Title:   c.String("title"),
-		Details: c.String("details"),
+// formatOffset describes a template deadline relative to the anchor date.
+func formatOffset(offset *int64) string {
+	if offset == nil {
+		return ""
 	}
 
-	if i := c.Uint("list"); i > 0 {
-		task.ChecklistID = &i
+	if *offset < 0 {
+		return fmt.Sprintf(" (due %s before anchor)", formatSeconds(-*offset))
 	}
+	return fmt.Sprintf(" (due %s after anchor)", formatSeconds(*offset))
+}
 
-	if d := c.Duration("deadline"); d > 0 {
-		deadline := time.Now().Add(d)
-		task.Deadline = &deadline
+func useTemplate(c *cli.Context) (err error) {
+	in := &todos.InstantiateTemplateRequest{Title: c.String("title")}
+
+	if a := c.String("anchor"); a != "" {
+		var anchor time.Time
+		if anchor, err = time.ParseInLocation("2006-01-02", a, time.Local); err != nil {
+			if anchor, err = time.Parse(time.RFC3339, a); err != nil {
+				return cli.NewExitError(fmt.Errorf("could not parse anchor %q", a), 1)
+			}
+		}
+		in.Anchor = &anchor
 	}
 
-	if _, err = todoc.UpdateTask(c.Uint("id"), task); err != nil {
+	var rep *todos.CreateChecklistResponse
+	if rep, err = todoc.InstantiateTemplate(c.Uint("id"), in); err != nil {
 		return cli.NewExitError(err, 1)
 	}
+
+	fmt.Printf("created checklist %d\n", rep.ChecklistID)
 	return nil
 }
 
-func deleteTask(c *cli.Context) (err error) {
-	if _, err = todoc.DeleteTask(c.Uint("id")); err != nil {
+func deleteTemplate(c *cli.Context) (err error) {
+	if _, err = todoc.DeleteTemplate(c.Uint("id")); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 	return nil
 }
 
-func listChecklists(c *cli.Context) (err error) {
-	in := &todos.ListChecklistsRequest{}
-
-	var out *todos.ListChecklistsResponse
-	if out, err = todoc.ListChecklists(in); err != nil {
+func listViews(c *cli.Context) (err error) {
+	var out *todos.ListViewsResponse
+	if out, err = todoc.ListViews(); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	for _, item := range out.Checklists {
-		fmt.Printf("%d: %s\n", item.ID, item.Title)
+	for _, view := range out.Views {
+		fmt.Printf("%d: %s (%d tasks)\n", view.ID, view.Name, view.Count)
 	}
-
 	return nil
 }
 
-func createChecklist(c *cli.Context) (err error) {
-	checklist := &todos.Checklist{
-		Title:   c.String("title"),
-		Details: c.String("details"),
-	}
+func createView(c *cli.Context) (err error) {
+	view := &todos.SavedView{Name: c.String("name")}
 
-	if d := c.Duration("deadline"); d > 0 {
-		deadline := time.Now().Add(d)
-		checklist.Deadline = &deadline
+	var filter *todos.ListTasksRequest
+	if filter, err = taskFilter(c); err != nil {
+		return cli.NewExitError(err, 1)
 	}
+	view.Filter = todos.ViewFilter(*filter)
 
-	var rep *todos.CreateChecklistResponse
-	if rep, err = todoc.CreateChecklist(checklist); err != nil {
+	var rep *todos.CreateViewResponse
+	if rep, err = todoc.CreateView(view); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	fmt.Printf("created checklist %d\n", rep.ChecklistID)
+	fmt.Printf("created view %d\n", rep.ViewID)
 	return nil
 }
 
-func detailChecklist(c *cli.Context) (err error) {
-	var out *todos.DetailChecklistResponse
-	if out, err = todoc.DetailChecklist(c.Uint("id")); err != nil {
+func runView(c *cli.Context) (err error) {
+	var id uint
+	if id, err = findView(c); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 
-	var data []byte
-	if data, err = yaml.Marshal(out); err != nil {
+	var view *todos.DetailViewResponse
+	if view, err = todoc.DetailView(id); err != nil {
 		return cli.NewExitError(err, 1)
 	}
-	fmt.Print(string(data))
+
+	// Unless a specific page is requested, follow the cursor through all of the pages
+	req := &todos.ListTasksRequest{Page: c.Int("page"), PerPage: c.Int("per-page")}
+	var data *todos.ListTasksResponse
+	var tasks []todos.Task
+	for {
+		if data, err = todoc.EvaluateView(id, req); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		tasks = append(tasks, data.Tasks...)
+		if req.Page > 0 || data.NextCursor == "" {
+			break
+		}
+		req.Cursor = data.NextCursor
+	}
+
+	printTasks(tasks, view.View.Filter.Archived != nil)
+	if req.Page > 0 {
+		fmt.Printf("\npage %d of %d\n", data.Page, data.NumPages)
+	}
 	return nil
 }
 
-func updateChecklist(c *cli.Context) (err error) {
-	list := &todos.Checklist{
-		Title:   c.String("title"),
-		Details: c.String("details"),
+func updateView(c *cli.Context) (err error) {
+	var id uint
+	if id, err = findView(c); err != nil {
+		return cli.NewExitError(err, 1)
 	}
 
-	if d := c.Duration("deadline"); d > 0 {
-		deadline := time.Now().Add(d)
-		list.Deadline = &deadline
+	// The server replaces the view, so keep the current name unless it is renamed
+	view := &todos.SavedView{Name: c.String("name")}
+	if view.Name == "" {
+		var current *todos.DetailViewResponse
+		if current, err = todoc.DetailView(id); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		view.Name = current.View.Name
+	}
+
+	var filter *todos.ListTasksRequest
+	if filter, err = taskFilter(c); err != nil {
+		return cli.NewExitError(err, 1)
 	}
+	view.Filter = todos.ViewFilter(*filter)
 
-	if _, err = todoc.UpdateChecklist(c.Uint("id"), list); err != nil {
+	if _, err = todoc.UpdateView(id, view); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 	return nil
 }
 
-func deleteChecklist(c *cli.Context) (err error) {
-	if _, err = todoc.DeleteChecklist(c.Uint("id")); err != nil {
+func deleteView(c *cli.Context) (err error) {
+	var id uint
+	if id, err = findView(c); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	if _, err = todoc.DeleteView(id); err != nil {
 		return cli.NewExitError(err, 1)
 	}
 	return nil
 }
+
+// findView returns the id of the saved view specified by the id flag or otherwise the
+// id of the view whose name is the arguments of the command.
+func findView(c *cli.Context) (id uint, err error) {
+	if id = c.Uint("id"); id > 0 {
+		return id, nil
+	}
+
+	name := strings.TrimSpace(strings.Join(c.Args(), " "))
+	if name == "" {
+		return 0, errors.New("specify the id or the name of the view")
+	}
+
+	var out *todos.ListViewsResponse
+	if out, err = todoc.ListViews(); err != nil {
+		return 0, err
+	}
+
+	for _, view := range out.Views {
+		if strings.EqualFold(view.Name, name) {
+			return view.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no view named %q", name)
+}
+
+// viewFlags returns the flags of a saved view command followed by the task list filter
+// and sort flags that are saved with the view. The relative date filters of the task
+// list are not included since they would be saved as fixed times.
+func viewFlags(flags ...cli.Flag) []cli.Flag {
+	return append(flags,
+		cli.UintFlag{
+			Name:  "l, list",
+			Usage: "only include tasks in the checklist, in checklist order",
+		},
+		cli.StringSliceFlag{
+			Name:  "g, tag",
+			Usage: "only include tasks labeled with the tag (repeatable)",
+		},
+		cli.BoolFlag{
+			Name:  "d, deferred",
+			Usage: "include tasks that are deferred until a future start date",
+		},
+		cli.BoolFlag{
+			Name:  "a, assigned",
+			Usage: "only include tasks that are assigned to you",
+		},
+		cli.StringSliceFlag{
+			Name:  "F, field",
+			Usage: "only include tasks whose custom field matches name=value (repeatable)",
+		},
+		cli.BoolFlag{
+			Name:  "c, completed",
+			Usage: "only include tasks that are completed",
+		},
+		cli.BoolFlag{
+			Name:  "o, open",
+			Usage: "only include tasks that are not completed",
+		},
+		cli.BoolFlag{
+			Name:  "A, archived",
+			Usage: "only include tasks that are archived",
+		},
+		cli.BoolFlag{
+			Name:  "O, overdue",
+			Usage: "only include incomplete tasks whose deadline has passed",
+		},
+		cli.StringFlag{
+			Name:  "t, title",
+			Usage: "only include tasks whose title contains the text",
+		},
+		cli.StringSliceFlag{
+			Name:  "s, sort",
+			Usage: "sort by deadline, created, updated, title, priority, or position, prefix - for descending (repeatable)",
+		},
+	)
+}
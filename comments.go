@@ -0,0 +1,201 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// The maximum number of characters in a comment, the size of the text column.
+const maxCommentLength = 4095
+
+var (
+	errEmptyComment   = errors.New("comment text cannot be empty")
+	errCommentTooLong = fmt.Errorf("comments cannot be longer than %d characters", maxCommentLength)
+	errNotAuthor      = errors.New("only the author of the comment can modify it")
+)
+
+//===========================================================================
+// Viewset for Comment objects
+//===========================================================================
+
+// ListComments returns all of the comments on the task, oldest first.
+func (s *API) ListComments(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	var comments []Comment
+	if err := s.db.Preload("User").Where("task_id = ?", task.ID).Order("created_at").Order("id").Find(&comments).Error; err != nil {
+		logger.Printf("could not fetch comments: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	for i := range comments {
+		comments[i].Username = comments[i].User.Username
+	}
+
+	c.JSON(http.StatusOK, ListCommentsResponse{Success: true, Comments: comments})
+}
+
+// CreateComment adds a comment to the task authored by the authenticated user.
+func (s *API) CreateComment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Parse the user input
+	var comment Comment
+	if err := c.ShouldBind(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if err := validateComment(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// The task and author are set from the request, not the input
+	comment.ID = 0
+	comment.TaskID = task.ID
+	comment.UserID = user.ID
+
+	if err := s.db.Create(&comment).Error; err != nil {
+		logger.Printf("could not create comment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateCommentResponse{Success: true, CommentID: comment.ID})
+}
+
+// UpdateComment edits the text of the comment, only the author of the comment can edit it.
+func (s *API) UpdateComment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	comment, ok := s.authorComment(c, user)
+	if !ok {
+		return
+	}
+
+	// Parse the user input
+	var input Comment
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if err := validateComment(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if err := s.db.Model(&comment).Update("text", input.Text).Error; err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateCommentResponse{Success: true})
+}
+
+// DeleteComment removes the comment, only the author of the comment can delete it.
+func (s *API) DeleteComment(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	comment, ok := s.authorComment(c, user)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Delete(&comment).Error; err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteCommentResponse{Success: true})
+}
+
+// authorComment fetches the comment specified by the comment url parameter on the task
+// specified by the id url parameter and ensures that the user is its author. If the
+// comment cannot be found or modified, the error response is written and false is
+// returned.
+func (s *API) authorComment(c *gin.Context, user User) (comment Comment, ok bool) {
+	var task Task
+	if task, ok = s.userTask(c, user, RoleViewer); !ok {
+		return comment, false
+	}
+
+	if err := s.db.Where("id = ? AND task_id = ?", c.Param("comment"), task.ID).First(&comment).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return comment, false
+		}
+		logger.Printf("could not find comment: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return comment, false
+	}
+
+	if comment.UserID != user.ID {
+		c.JSON(http.StatusForbidden, ErrorResponse(errNotAuthor))
+		return comment, false
+	}
+	return comment, true
+}
+
+//===========================================================================
+// Comment Helpers
+//===========================================================================
+
+// validateComment trims the comment text and ensures that it fits in the database.
+func validateComment(comment *Comment) error {
+	if comment.Text = strings.TrimSpace(comment.Text); comment.Text == "" {
+		return errEmptyComment
+	}
+
+	if len([]rune(comment.Text)) > maxCommentLength {
+		return errCommentTooLong
+	}
+	return nil
+}
+
+// commentCounts populates the number of comments on each of the tasks using a single
+// aggregate query.
+func commentCounts(db *gorm.DB, tasks []Task) (err error) {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	index := make(map[uint]int, len(tasks))
+	for i, task := range tasks {
+		ids = append(ids, task.ID)
+		index[task.ID] = i
+	}
+
+	var rows []struct {
+		TaskID uint
+		Total  uint
+	}
+
+	err = db.Model(&Comment{}).
+		Select("task_id, COUNT(*) AS total").
+		Where("task_id IN (?)", ids).
+		Group("task_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		tasks[index[row.TaskID]].Comments = row.Total
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestComments() {
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "discuss"}, &task))
+	path := fmt.Sprintf("/v1/tasks/%d/comments", task.TaskID)
+
+	// Create comments on the task
+	ids := make([]uint, 2)
+	for i, text := range []string{"first thoughts", "second thoughts"} {
+		var rep CreateCommentResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, path, Comment{Text: text}, &rep))
+		ids[i] = rep.CommentID
+	}
+
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, path, Comment{Text: "   "}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, path, Comment{Text: strings.Repeat("a", 4096)}, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodPost, "/v1/tasks/99999/comments", Comment{Text: "lost"}, nil))
+
+	var list ListCommentsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &list))
+	require.Len(s.T(), list.Comments, 2)
+	require.Equal(s.T(), "first thoughts", list.Comments[0].Text)
+	require.Equal(s.T(), "jane", list.Comments[0].Username)
+
+	// The comment count is returned with the tasks
+	var tasks ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks", nil, &tasks))
+	for _, item := range tasks.Tasks {
+		if item.ID == task.TaskID {
+			require.Equal(s.T(), uint(2), item.Comments)
+		}
+	}
+
+	// Edit and delete comments
+	comment := fmt.Sprintf("%s/%d", path, ids[0])
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, comment, Comment{Text: "revised thoughts"}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("%s/%d", path, ids[1]), nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodDelete, fmt.Sprintf("%s/%d", path, ids[1]), nil, nil))
+
+	list = ListCommentsResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &list))
+	require.Len(s.T(), list.Comments, 1)
+	require.Equal(s.T(), "revised thoughts", list.Comments[0].Text)
+
+	// Only the author of a comment can modify it
+	var admin User
+	db := s.api.DB()
+	s.RequireAdmin()
+	require.NoError(s.T(), db.Where("username = ?", "admin").First(&admin).Error)
+
+	other := Comment{TaskID: task.TaskID, UserID: admin.ID, Text: "not yours"}
+	require.NoError(s.T(), db.Create(&other).Error)
+
+	comment = fmt.Sprintf("%s/%d", path, other.ID)
+	require.Equal(s.T(), http.StatusForbidden, s.Do(http.MethodPut, comment, Comment{Text: "mine now"}, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.Do(http.MethodDelete, comment, nil, nil))
+
+	// Deleting the task keeps its comments in the trash, emptying the trash deletes them
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", task.TaskID), nil, nil))
+
+	var count int
+	require.NoError(s.T(), db.Model(&Comment{}).Where("task_id = ?", task.TaskID).Count(&count).Error)
+	require.Equal(s.T(), 2, count)
+
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, "/v1/trash", nil, nil))
+	require.NoError(s.T(), db.Model(&Comment{}).Where("task_id = ?", task.TaskID).Count(&count).Error)
+	require.Zero(s.T(), count)
+}
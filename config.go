@@ -3,6 +3,7 @@ package todos
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kelseyhightower/envconfig"
@@ -30,15 +31,19 @@ func Config() (conf Settings, err error) {
 // that I'm particularly fond of, but it's late and I'm not sure how to mock the
 // internal database without a big mess of spaghetti.
 type Settings struct {
-	Mode         string `default:"debug"`
-	UseTLS       bool   `default:"false"`
-	Bind         string `default:"127.0.0.1"`
-	Port         int    `envconfig:"PORT" default:"8080" required:"true"`
-	Domain       string `default:"localhost"`
-	SecretKey    string `envconfig:"SECRET_KEY" required:"true"`
-	DatabaseURL  string `envconfig:"DATABASE_URL" required:"true"`
-	SentryDSN    string `envconfig:"SENTRY_DSN"`
-	TokenCleanup bool   `default:"true" split_words:"true"`
+	Mode           string        `default:"debug"`
+	UseTLS         bool          `default:"false"`
+	Bind           string        `default:"127.0.0.1"`
+	Port           int           `envconfig:"PORT" default:"8080" required:"true"`
+	Domain         string        `default:"localhost"`
+	SecretKey      string        `envconfig:"SECRET_KEY" required:"true"`
+	DatabaseURL    string        `envconfig:"DATABASE_URL" required:"true"`
+	SentryDSN      string        `envconfig:"SENTRY_DSN"`
+	TokenCleanup   bool          `default:"true" split_words:"true"`
+	TrashCleanup   bool          `default:"true" split_words:"true"`
+	TrashRetention time.Duration `default:"720h" split_words:"true"`
+	StorageDir     string        `default:"attachments" split_words:"true"`
+	MaxUploadSize  int64         `default:"10485760" split_words:"true"`
 }
 
 // Addr returns the IPADDR:PORT to listen on
@@ -3,6 +3,7 @@ package todos_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/bbengfort/todos"
 	"github.com/stretchr/testify/require"
@@ -37,6 +38,9 @@ func TestConfig(t *testing.T) {
 	require.Equal(t, "127.0.0.1:8080", conf.Addr())
 	require.Equal(t, "http://localhost:8080/", conf.Endpoint())
 	require.False(t, conf.TokenCleanup)
+	require.True(t, conf.TrashCleanup)
+	require.Equal(t, 30*24*time.Hour, conf.TrashRetention)
+	require.Equal(t, "attachments", conf.StorageDir)
 }
 
 func TestBadConfigs(t *testing.T) {
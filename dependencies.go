@@ -0,0 +1,214 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	errDependencyCycle = errors.New("dependency would create a cycle")
+	errBlockedTask     = errors.New("task is blocked by open tasks, complete or remove its dependencies first")
+)
+
+//===========================================================================
+// Viewset for Dependency objects
+//===========================================================================
+
+// ListDependencies returns the tasks that block the specified task.
+func (s *API) ListDependencies(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	var blockers []Task
+	err := s.db.Preload("Tags").
+		Joins("JOIN dependencies ON dependencies.blocker_id = tasks.id").
+		Where("dependencies.task_id = ?", task.ID).
+		Order("tasks.id").
+		Find(&blockers).Error
+	if err != nil {
+		logger.Printf("could not fetch dependencies: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err = loadBlockers(s.db, blockers); err != nil {
+		logger.Printf("could not fetch dependencies: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListDependenciesResponse{Success: true, Blockers: blockers})
+}
+
+// CreateDependency records that the specified task is blocked by another task. Both
+// tasks must belong to the same user and the dependency cannot create a cycle.
+func (s *API) CreateDependency(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	var req CreateDependencyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var blocker Task
+		query := inWorkspace(tx, "tasks", task.WorkspaceID)
+		if err = query.Where("id = ? AND user_id = ?", req.BlockerID, task.UserID).First(&blocker).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return fmt.Errorf("blocking task %d does not exist", req.BlockerID)
+			}
+			logger.Printf("could not find blocking task: %s", err)
+			return errInternal
+		}
+
+		var cycle bool
+		if cycle, err = dependencyCycle(tx, task.ID, blocker.ID); err != nil {
+			logger.Printf("could not check dependency cycle: %s", err)
+			return errInternal
+		}
+
+		if cycle {
+			return errDependencyCycle
+		}
+
+		if err = tx.FirstOrCreate(&Dependency{}, Dependency{TaskID: task.ID, BlockerID: blocker.ID}).Error; err != nil {
+			logger.Printf("could not create dependency: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		if err == errInternal {
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateDependencyResponse{Success: true})
+}
+
+// DeleteDependency removes the dependency between the task and the blocking task.
+func (s *API) DeleteDependency(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	query := s.db.Where("task_id = ? AND blocker_id = ?", task.ID, c.Param("blocker")).Delete(&Dependency{})
+	if err := query.Error; err != nil {
+		logger.Printf("could not delete dependency: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if query.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteDependencyResponse{Success: true})
+}
+
+//===========================================================================
+// Dependency Helpers
+//===========================================================================
+
+// dependencyCycle returns true if making the task blocked by the blocker would create a
+// cycle, e.g. if the blocker is the task or is already (transitively) blocked by it.
+func dependencyCycle(db *gorm.DB, taskID, blockerID uint) (_ bool, err error) {
+	if taskID == blockerID {
+		return true, nil
+	}
+
+	visited := map[uint]bool{blockerID: true}
+	frontier := []uint{blockerID}
+	for len(frontier) > 0 {
+		var next []uint
+		if err = db.Model(&Dependency{}).Where("task_id IN (?)", frontier).Pluck("blocker_id", &next).Error; err != nil {
+			return false, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == taskID {
+				return true, nil
+			}
+
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+// loadBlockers populates the ids of the blocking tasks for each of the tasks and marks
+// them as blocked if any of their blockers are neither completed nor archived. The
+// dependencies for all of the tasks are fetched in a single query; blockers that are in
+// the trash are ignored.
+func loadBlockers(db *gorm.DB, tasks []Task) (err error) {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	index := make(map[uint]int, len(tasks))
+	for i, task := range tasks {
+		ids = append(ids, task.ID)
+		index[task.ID] = i
+	}
+
+	var rows []struct {
+		TaskID    uint
+		BlockerID uint
+		Completed bool
+		Archived  bool
+	}
+
+	err = db.Table("dependencies").
+		Select("dependencies.task_id, dependencies.blocker_id, tasks.completed, tasks.archived").
+		Joins("JOIN tasks ON tasks.id = dependencies.blocker_id AND tasks.deleted_at IS NULL").
+		Where("dependencies.task_id IN (?)", ids).
+		Order("dependencies.blocker_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		task := &tasks[index[row.TaskID]]
+		task.BlockedBy = append(task.BlockedBy, row.BlockerID)
+		if !row.Completed && !row.Archived {
+			task.Blocked = true
+		}
+	}
+	return nil
+}
+
+// isBlocked returns true if the task has any blockers that are not completed, archived,
+// or in the trash.
+func isBlocked(db *gorm.DB, taskID uint) (_ bool, err error) {
+	var count int
+	err = db.Table("dependencies").
+		Joins("JOIN tasks ON tasks.id = dependencies.blocker_id AND tasks.deleted_at IS NULL").
+		Where("dependencies.task_id = ? AND tasks.completed = ? AND tasks.archived = ?", taskID, false, false).
+		Count(&count).Error
+	return count > 0, err
+}
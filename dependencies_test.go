@@ -0,0 +1,67 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestDependencies() {
+	ids := make([]uint, 3)
+	for i, title := range []string{"design", "build", "ship"} {
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: title}, &rep))
+		ids[i] = rep.TaskID
+	}
+	design, build, ship := ids[0], ids[1], ids[2]
+
+	depends := func(task, blocker uint) int {
+		return s.Do(http.MethodPost, fmt.Sprintf("/v1/tasks/%d/dependencies", task), CreateDependencyRequest{BlockerID: blocker}, nil)
+	}
+
+	// ship is blocked by build which is blocked by design
+	require.Equal(s.T(), http.StatusCreated, depends(build, design))
+	require.Equal(s.T(), http.StatusCreated, depends(ship, build))
+
+	// Cycles are not allowed, including self dependencies
+	require.Equal(s.T(), http.StatusBadRequest, depends(design, ship))
+	require.Equal(s.T(), http.StatusBadRequest, depends(design, design))
+	require.Equal(s.T(), http.StatusBadRequest, depends(design, 99999))
+
+	var blockers ListDependenciesResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d/dependencies", ship), nil, &blockers))
+	require.Len(s.T(), blockers.Blockers, 1)
+	require.Equal(s.T(), build, blockers.Blockers[0].ID)
+	require.True(s.T(), blockers.Blockers[0].Blocked)
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", build), nil, &detail))
+	require.True(s.T(), detail.Task.Blocked)
+	require.Equal(s.T(), []uint{design}, detail.Task.BlockedBy)
+
+	// Blocked tasks cannot be completed
+	complete := func(id uint) int {
+		return s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", id), map[string]interface{}{"completed": true}, nil)
+	}
+	require.Equal(s.T(), http.StatusConflict, complete(build))
+	require.Equal(s.T(), http.StatusOK, complete(design))
+	require.Equal(s.T(), http.StatusOK, complete(build))
+
+	// Completing the blockers unblocks the task
+	var list ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks", nil, &list))
+	for _, task := range list.Tasks {
+		if task.ID == ship {
+			require.False(s.T(), task.Blocked)
+			require.Equal(s.T(), []uint{build}, task.BlockedBy)
+		}
+	}
+
+	// Remove the dependency
+	code := s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d/dependencies/%d", ship, build), nil, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+	code = s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d/dependencies/%d", ship, build), nil, nil)
+	require.Equal(s.T(), http.StatusNotFound, code)
+}
@@ -1,6 +1,7 @@
 package todos
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,10 @@ var (
 	notAllowed   = Response{Success: false, Error: "method not allowed"}
 )
 
+// errInternal is returned from transactions in handlers to signal that the error has
+// already been logged and that an internal server error should be returned.
+var errInternal = errors.New("internal server error")
+
 // ErrorResponse constructs an new response from the error or returns a success: false.
 func ErrorResponse(err error) Response {
 	if err == nil {
@@ -0,0 +1,38 @@
+package todos
+
+import (
+	"errors"
+
+	"github.com/jinzhu/gorm"
+)
+
+var errInvalidEstimate = errors.New("estimate must be a non-negative whole number")
+
+//===========================================================================
+// Estimate Helpers
+//===========================================================================
+
+// parseEstimate validates an estimate from an update request; estimates are whole
+// units of effort, e.g. points or minutes, and null clears the estimate.
+func parseEstimate(val interface{}) (uint, error) {
+	if val == nil {
+		return 0, nil
+	}
+
+	est, ok := val.(float64)
+	if !ok || est < 0 || est != float64(uint(est)) {
+		return 0, errInvalidEstimate
+	}
+	return uint(est), nil
+}
+
+// openEffort returns the total estimate of the user's tasks in the workspace that are
+// neither completed nor archived.
+func openEffort(db *gorm.DB, userID uint, workspace *uint) (effort uint, err error) {
+	var row struct{ Effort uint }
+	err = inWorkspace(db, "tasks", workspace).Model(&Task{}).
+		Select("COALESCE(SUM(estimate), 0) AS effort").
+		Where("user_id = ? AND completed = ? AND archived = ?", userID, false, false).
+		Scan(&row).Error
+	return row.Effort, err
+}
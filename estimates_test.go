@@ -0,0 +1,52 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestEstimates() {
+	var overview OverviewResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/", nil, &overview))
+	open := overview.OpenEffort
+
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "sprint"}, &list))
+
+	ids := make([]uint, 4)
+	for i, estimate := range []uint{3, 5, 8, 0} {
+		var rep CreateTaskResponse
+		task := Task{Title: fmt.Sprintf("story %d", i), ChecklistID: &list.ChecklistID, Estimate: estimate}
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids[i] = rep.TaskID
+	}
+
+	// Complete one task, archive another, and estimate the last
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[0]), map[string]interface{}{"completed": true}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[2]), map[string]interface{}{"archived": true}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[3]), map[string]interface{}{"estimate": 2}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[3]), map[string]interface{}{"estimate": -1}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[3]), map[string]interface{}{"estimate": 1.5}, nil))
+
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, &detail))
+	require.Equal(s.T(), uint(10), detail.Checklist.Effort)
+	require.Equal(s.T(), uint(3), detail.Checklist.EffortCompleted)
+	require.Equal(s.T(), uint(7), detail.Checklist.EffortRemaining)
+	require.Equal(s.T(), uint(2), detail.Checklist.Tasks[3].Estimate)
+
+	var lists ListChecklistsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/lists", nil, &lists))
+	for _, item := range lists.Checklists {
+		if item.ID == list.ChecklistID {
+			require.Equal(s.T(), uint(7), item.EffortRemaining)
+		}
+	}
+
+	overview = OverviewResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/", nil, &overview))
+	require.Equal(s.T(), open+7, overview.OpenEffort)
+}
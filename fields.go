@@ -0,0 +1,537 @@
+package todos
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Types of the custom fields that checklists can define for their tasks.
+const (
+	FieldText    = "text"
+	FieldNumber  = "number"
+	FieldDate    = "date"
+	FieldEnum    = "enum"
+	FieldBoolean = "boolean"
+)
+
+// FieldDateFormat is the format of the values of date fields.
+const FieldDateFormat = "2006-01-02"
+
+var fieldTypes = map[string]bool{
+	FieldText:    true,
+	FieldNumber:  true,
+	FieldDate:    true,
+	FieldEnum:    true,
+	FieldBoolean: true,
+}
+
+var (
+	errFieldNotFound    = errors.New("field does not exist")
+	errFieldName        = errors.New("field name must be between 1 and 255 characters")
+	errFieldType        = errors.New("field type must be text, number, date, enum, or boolean")
+	errFieldTypeChanged = errors.New("the type of a field cannot be changed")
+	errFieldOptions     = errors.New("enum fields require at least one option and only enum fields have options")
+	errFieldsChecklist  = errors.New("only tasks in a checklist can have custom fields")
+	errFieldsInput      = errors.New("fields must be an object of field names and values")
+	errFieldFilter      = errors.New("field filters must be in the form name=value")
+)
+
+// FieldOptions are the allowed values of an enum field, stored in the database as a
+// JSON encoded text column.
+type FieldOptions []string
+
+// Scan implements sql.Scanner to decode the options from JSON.
+func (o *FieldOptions) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	case []byte:
+		return json.Unmarshal(v, o)
+	default:
+		return fmt.Errorf("cannot scan field options from %T", src)
+	}
+}
+
+// Value implements driver.Valuer to encode the options as JSON.
+func (o FieldOptions) Value() (driver.Value, error) {
+	if o == nil {
+		o = FieldOptions{}
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Validate the name, type, and options of the field, normalizing the field in place.
+func (f *CustomField) Validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" || len(f.Name) > 255 {
+		return errFieldName
+	}
+
+	f.Type = strings.ToLower(strings.TrimSpace(f.Type))
+	if !fieldTypes[f.Type] {
+		return errFieldType
+	}
+
+	options := make(FieldOptions, 0, len(f.Options))
+	seen := make(map[string]bool, len(f.Options))
+	for _, option := range f.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			continue
+		}
+		seen[option] = true
+		options = append(options, option)
+	}
+
+	if (f.Type == FieldEnum) != (len(options) > 0) {
+		return errFieldOptions
+	}
+	f.Options = options
+	return nil
+}
+
+// Parse the value of the field from user input, returning the canonical form of the
+// value that is stored in the database. Values can be decoded from JSON as their type
+// or as strings (e.g. from the command line), dates are formatted as YYYY-MM-DD.
+func (f CustomField) Parse(val interface{}) (_ string, err error) {
+	switch f.Type {
+	case FieldText:
+		if text, ok := val.(string); ok && len(text) <= 4095 {
+			return text, nil
+		}
+		return "", fmt.Errorf("field %q must be text of at most 4095 characters", f.Name)
+
+	case FieldNumber:
+		switch v := val.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			var num float64
+			if num, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return strconv.FormatFloat(num, 'f', -1, 64), nil
+			}
+		}
+		return "", fmt.Errorf("field %q must be a number", f.Name)
+
+	case FieldDate:
+		if v, ok := val.(string); ok {
+			if date, ok := parseFieldDate(v); ok {
+				return date, nil
+			}
+		}
+		return "", fmt.Errorf("field %q must be a date formatted as YYYY-MM-DD", f.Name)
+
+	case FieldEnum:
+		if v, ok := val.(string); ok {
+			for _, option := range f.Options {
+				if v == option {
+					return v, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("field %q must be one of %s", f.Name, strings.Join(f.Options, ", "))
+
+	case FieldBoolean:
+		switch v := val.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			var b bool
+			if b, err = strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return strconv.FormatBool(b), nil
+			}
+		}
+		return "", fmt.Errorf("field %q must be true or false", f.Name)
+
+	default:
+		return "", errFieldType
+	}
+}
+
+// Decode the stored value of the field into the type that is returned in JSON.
+func (f CustomField) Decode(value string) interface{} {
+	switch f.Type {
+	case FieldNumber:
+		if num, err := strconv.ParseFloat(value, 64); err == nil {
+			return num
+		}
+	case FieldBoolean:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+//===========================================================================
+// Viewset for CustomField objects
+//===========================================================================
+
+// ListFields returns the custom fields defined by the checklist.
+func (s *API) ListFields(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	var fields []CustomField
+	if err := s.db.Where("checklist_id = ?", list.ID).Order("id").Find(&fields).Error; err != nil {
+		logger.Printf("could not fetch custom fields: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListFieldsResponse{Success: true, Fields: fields})
+}
+
+// CreateField defines a new custom field for the tasks of the checklist. Owners and
+// editors of the checklist can define its fields.
+func (s *API) CreateField(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	var field CustomField
+	if err := c.ShouldBind(&field); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if err := field.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	taken, err := fieldNameTaken(s.db, list.ID, 0, field.Name)
+	if err != nil {
+		logger.Printf("could not check custom field name: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse(fmt.Errorf("checklist already has a field named %q", field.Name)))
+		return
+	}
+
+	field.ID = 0
+	field.ChecklistID = list.ID
+	if err = s.db.Create(&field).Error; err != nil {
+		logger.Printf("could not create custom field: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateFieldResponse{Success: true, FieldID: field.ID})
+}
+
+// UpdateField renames the custom field or changes the options of an enum field. The type
+// of the field cannot be changed and options that are used by tasks cannot be removed.
+func (s *API) UpdateField(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	field, ok := s.checklistField(c, list)
+	if !ok {
+		return
+	}
+
+	var input CustomField
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if input.Type != field.Type {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errFieldTypeChanged))
+		return
+	}
+
+	taken, err := fieldNameTaken(s.db, list.ID, field.ID, input.Name)
+	if err != nil {
+		logger.Printf("could not check custom field name: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse(fmt.Errorf("checklist already has a field named %q", input.Name)))
+		return
+	}
+
+	if field.Type == FieldEnum {
+		var used []string
+		if err = s.db.Model(&FieldValue{}).Where("field_id = ? AND value NOT IN (?)", field.ID, []string(input.Options)).Pluck("DISTINCT value", &used).Error; err != nil {
+			logger.Printf("could not check custom field options: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+
+		if len(used) > 0 {
+			c.JSON(http.StatusConflict, ErrorResponse(fmt.Errorf("options in use by tasks cannot be removed: %s", strings.Join(used, ", "))))
+			return
+		}
+	}
+
+	if err = s.db.Model(&field).Updates(map[string]interface{}{"name": input.Name, "options": input.Options}).Error; err != nil {
+		logger.Printf("could not update custom field: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateFieldResponse{Success: true})
+}
+
+// DeleteField removes the custom field and its values from all tasks of the checklist.
+func (s *API) DeleteField(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	field, ok := s.checklistField(c, list)
+	if !ok {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Where("field_id = ?", field.ID).Delete(&FieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&field).Error
+	})
+
+	if err != nil {
+		logger.Printf("could not delete custom field: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteFieldResponse{Success: true})
+}
+
+//===========================================================================
+// Custom Field Helpers
+//===========================================================================
+
+// checklistField fetches the custom field specified by the field url parameter from the
+// checklist. If the field cannot be found, the error response is written and false is
+// returned.
+func (s *API) checklistField(c *gin.Context, list Checklist) (field CustomField, ok bool) {
+	if err := s.db.Where("id = ? AND checklist_id = ?", c.Param("field"), list.ID).First(&field).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, ErrorResponse(errFieldNotFound))
+			return field, false
+		}
+		logger.Printf("could not find custom field: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return field, false
+	}
+	return field, true
+}
+
+// fieldNameTaken returns true if another field of the checklist has the name.
+func fieldNameTaken(db *gorm.DB, checklistID, fieldID uint, name string) (_ bool, err error) {
+	var count int
+	if err = db.Model(&CustomField{}).Where("checklist_id = ? AND id <> ? AND name = ?", checklistID, fieldID, name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// parseFieldsInput validates that the fields of a task update are an object that maps
+// field names to values.
+func parseFieldsInput(val interface{}) (map[string]interface{}, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	fields, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, errFieldsInput
+	}
+	return fields, nil
+}
+
+// parseFieldDate parses a date or timestamp and returns it in the date format.
+func parseFieldDate(val string) (string, bool) {
+	val = strings.TrimSpace(val)
+	for _, layout := range []string{FieldDateFormat, time.RFC3339} {
+		if date, err := time.Parse(layout, val); err == nil {
+			return date.Format(FieldDateFormat), true
+		}
+	}
+	return "", false
+}
+
+// setFieldValues validates the values of the custom fields of the task by name against
+// the fields defined by its checklist and stores them; a null value removes the value
+// of the field from the task. The values of fields that are not specified are kept.
+func setFieldValues(db *gorm.DB, task Task, values map[string]interface{}) (err error) {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if task.ChecklistID == nil {
+		return errFieldsChecklist
+	}
+
+	var fields []CustomField
+	if err = db.Where("checklist_id = ?", *task.ChecklistID).Find(&fields).Error; err != nil {
+		logger.Printf("could not fetch custom fields: %s", err)
+		return errInternal
+	}
+
+	byName := make(map[string]CustomField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	for name, val := range values {
+		field, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("checklist has no field named %q", name)
+		}
+
+		if val == nil {
+			if err = db.Where("task_id = ? AND field_id = ?", task.ID, field.ID).Delete(&FieldValue{}).Error; err != nil {
+				logger.Printf("could not delete field value: %s", err)
+				return errInternal
+			}
+			continue
+		}
+
+		var value string
+		if value, err = field.Parse(val); err != nil {
+			return err
+		}
+
+		fv := FieldValue{TaskID: task.ID, FieldID: field.ID}
+		if err = db.Where(fv).Assign(FieldValue{Value: value}).FirstOrCreate(&fv).Error; err != nil {
+			logger.Printf("could not store field value: %s", err)
+			return errInternal
+		}
+	}
+	return nil
+}
+
+// clearFieldValues removes the values of the task for fields that are not defined by the
+// checklist, e.g. when the task is moved to another checklist.
+func clearFieldValues(db *gorm.DB, taskID uint, checklistID *uint) error {
+	query := db.Where("task_id = ?", taskID)
+	if checklistID != nil {
+		fields := db.Table("custom_fields").Select("id").Where("checklist_id = ?", *checklistID)
+		query = query.Where("field_id NOT IN ?", fields.SubQuery())
+	}
+	return query.Delete(&FieldValue{}).Error
+}
+
+// copyFieldValues copies the values of the custom fields of one task to another task.
+func copyFieldValues(db *gorm.DB, fromID, toID uint) (err error) {
+	var values []FieldValue
+	if err = db.Where("task_id = ?", fromID).Find(&values).Error; err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err = db.Create(&FieldValue{TaskID: toID, FieldID: value.FieldID, Value: value.Value}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFieldValues sets the values of the custom fields of each of the tasks by field
+// name, decoded into the type of the field, fetching the values in a single query.
+func loadFieldValues(db *gorm.DB, tasks []Task) (err error) {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	index := make(map[uint]int, len(tasks))
+	for i, task := range tasks {
+		ids = append(ids, task.ID)
+		index[task.ID] = i
+	}
+
+	var rows []struct {
+		TaskID uint
+		Name   string
+		Type   string
+		Value  string
+	}
+
+	err = db.Table("field_values").
+		Select("field_values.task_id, custom_fields.name, custom_fields.type, field_values.value").
+		Joins("JOIN custom_fields ON custom_fields.id = field_values.field_id").
+		Where("field_values.task_id IN (?)", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		task := &tasks[index[row.TaskID]]
+		if task.Fields == nil {
+			task.Fields = make(map[string]interface{})
+		}
+		task.Fields[row.Name] = CustomField{Type: row.Type}.Decode(row.Value)
+	}
+	return nil
+}
+
+// filterFields filters the query to the tasks whose custom fields have the values
+// specified by the filters in the form name=value. Since fields with the same name can
+// have different types in different checklists, the value is compared in the canonical
+// form of each type that it can be parsed as.
+func filterFields(db, query *gorm.DB, filters []string) (_ *gorm.DB, err error) {
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errFieldFilter
+		}
+
+		values := []string{parts[1]}
+		for _, ftype := range []string{FieldNumber, FieldDate, FieldBoolean} {
+			if value, err := (CustomField{Type: ftype}).Parse(parts[1]); err == nil {
+				values = append(values, value)
+			}
+		}
+
+		matches := db.Table("field_values").Select("field_values.task_id").
+			Joins("JOIN custom_fields ON custom_fields.id = field_values.field_id").
+			Where("custom_fields.name = ? AND field_values.value IN (?)", strings.TrimSpace(parts[0]), values)
+		query = query.Where("tasks.id IN ?", matches.SubQuery())
+	}
+	return query, nil
+}
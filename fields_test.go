@@ -0,0 +1,110 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestCustomFields() {
+	var list, other CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "deployments"}, &list))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "errands"}, &other))
+	fieldsPath := fmt.Sprintf("/v1/lists/%d/fields", list.ChecklistID)
+
+	// Fields must have a valid type and only enum fields have options
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, fieldsPath, CustomField{Name: "customer", Type: "color"}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, fieldsPath, CustomField{Name: "environment", Type: FieldEnum}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, fieldsPath, CustomField{Name: "customer", Type: FieldText, Options: FieldOptions{"acme"}}, nil))
+
+	fields := []CustomField{
+		{Name: "customer", Type: FieldText},
+		{Name: "ticket", Type: FieldNumber},
+		{Name: "release", Type: FieldDate},
+		{Name: "environment", Type: FieldEnum, Options: FieldOptions{"staging", "production"}},
+		{Name: "hotfix", Type: FieldBoolean},
+	}
+
+	ids := make(map[string]uint, len(fields))
+	for _, field := range fields {
+		var rep CreateFieldResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, fieldsPath, field, &rep))
+		ids[field.Name] = rep.FieldID
+	}
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPost, fieldsPath, CustomField{Name: "customer", Type: FieldText}, nil))
+
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, &detail))
+	require.Len(s.T(), detail.Checklist.Fields, len(fields))
+
+	// Values are validated against the type of the field
+	invalid := []map[string]interface{}{
+		{"ticket": "abc"},
+		{"release": "next tuesday"},
+		{"environment": "qa"},
+		{"hotfix": "maybe"},
+		{"unknown": "value"},
+	}
+	for _, values := range invalid {
+		require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "deploy", ChecklistID: &list.ChecklistID, Fields: values}, nil))
+	}
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "deploy", Fields: map[string]interface{}{"customer": "acme"}}, nil))
+
+	var deploy, rollback CreateTaskResponse
+	values := map[string]interface{}{"customer": "acme", "ticket": 1042, "release": "2020-07-04", "environment": "production", "hotfix": false}
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "deploy", ChecklistID: &list.ChecklistID, Fields: values}, &deploy))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "rollback", ChecklistID: &list.ChecklistID, Fields: map[string]interface{}{"environment": "staging", "hotfix": "true"}}, &rollback))
+
+	var task DetailTaskResponse
+	deployPath := fmt.Sprintf("/v1/tasks/%d", deploy.TaskID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, deployPath, nil, &task))
+	require.Equal(s.T(), map[string]interface{}{"customer": "acme", "ticket": 1042.0, "release": "2020-07-04", "environment": "production", "hotfix": false}, task.Task.Fields)
+
+	// Updates only modify the specified fields and null removes the value
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, deployPath, map[string]interface{}{"fields": []string{"customer"}}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, deployPath, map[string]interface{}{"fields": map[string]interface{}{"ticket": "1043.0", "customer": nil}}, nil))
+
+	task = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, deployPath, nil, &task))
+	require.Equal(s.T(), 1043.0, task.Task.Fields["ticket"])
+	require.NotContains(s.T(), task.Task.Fields, "customer")
+
+	// Tasks can be filtered by the values of their custom fields
+	var tasks ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?field=environment=staging", nil, &tasks))
+	require.Equal(s.T(), []uint{rollback.TaskID}, taskIDs(tasks.Tasks))
+	require.Equal(s.T(), true, tasks.Tasks[0].Fields["hotfix"])
+
+	tasks = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?field=ticket=1043&field=hotfix=false", nil, &tasks))
+	require.Equal(s.T(), []uint{deploy.TaskID}, taskIDs(tasks.Tasks))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?field=hotfix", nil, nil))
+
+	// Options that are in use cannot be removed and the type cannot be changed
+	environmentPath := fmt.Sprintf("%s/%d", fieldsPath, ids["environment"])
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPut, environmentPath, CustomField{Name: "environment", Type: FieldEnum, Options: FieldOptions{"production"}}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, environmentPath, CustomField{Name: "environment", Type: FieldText}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, environmentPath, CustomField{Name: "env", Type: FieldEnum, Options: FieldOptions{"staging", "production", "qa"}}, nil))
+
+	// Moving the task to another checklist removes the values of the old fields
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, deployPath, map[string]interface{}{"checklist": other.ChecklistID}, nil))
+	task = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, deployPath, nil, &task))
+	require.Empty(s.T(), task.Task.Fields)
+
+	// Deleting a field removes its values from the tasks
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, environmentPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodDelete, environmentPath, nil, nil))
+
+	task = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", rollback.TaskID), nil, &task))
+	require.Equal(s.T(), map[string]interface{}{"hotfix": true}, task.Task.Fields)
+
+	// Clean up so that other tests are not affected by the checklists
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, deployPath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", rollback.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", other.ChecklistID), nil, nil))
+}
@@ -0,0 +1,288 @@
+package todos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Default orders of the task list: tasks in a checklist are returned in their manual
+// order, otherwise tasks are ordered by priority then deadline so the server owns the
+// triage order.
+var (
+	checklistOrder = []string{"position"}
+	triageOrder    = []string{"-priority", "deadline"}
+)
+
+// taskSortKey describes a column that tasks can be sorted by. Tasks without a value for
+// a nullable column are always ordered last, regardless of the sort direction.
+type taskSortKey struct {
+	column   string
+	nullable bool
+	value    func(Task) interface{}
+	decode   func(json.RawMessage) (interface{}, error)
+}
+
+var taskSortKeys = map[string]taskSortKey{
+	"priority": {column: "tasks.priority", value: func(t Task) interface{} { return int64(t.Priority) }, decode: decodeSortInt},
+	"position": {column: "tasks.position", value: func(t Task) interface{} { return t.Position }, decode: decodeSortInt},
+	"deadline": {column: "tasks.deadline", nullable: true, value: func(t Task) interface{} { return t.Deadline }, decode: decodeSortTime},
+	"created":  {column: "tasks.created_at", value: func(t Task) interface{} { return t.CreatedAt }, decode: decodeSortTime},
+	"updated":  {column: "tasks.updated_at", value: func(t Task) interface{} { return t.UpdatedAt }, decode: decodeSortTime},
+	"title":    {column: "LOWER(tasks.title)", value: func(t Task) interface{} { return strings.ToLower(t.Title) }, decode: decodeSortString},
+}
+
+// taskOrder is a multi-key sort of the task list, ties are always broken by task id.
+type taskOrder struct {
+	keys []taskSortKey
+	desc []bool
+	spec string
+}
+
+// parseTaskOrder parses the sort keys of a list tasks request. Each key is the name of
+// a column optionally prefixed with - to sort in descending order; multiple keys can
+// be repeated or separated by commas. If no keys are specified the default order is used.
+func parseTaskOrder(sort []string, inChecklist bool) (order *taskOrder, err error) {
+	names := make([]string, 0, len(sort))
+	for _, item := range sort {
+		for _, name := range strings.Split(item, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		names = triageOrder
+		if inChecklist {
+			names = checklistOrder
+		}
+	}
+
+	order = &taskOrder{spec: strings.Join(names, ",")}
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimLeft(name, "+-")
+
+		key, ok := taskSortKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort tasks by %q", name)
+		}
+
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("cannot sort tasks by %q more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		order.keys = append(order.keys, key)
+		order.desc = append(order.desc, desc)
+	}
+	return order, nil
+}
+
+// String returns the sort specification that cursors are created for.
+func (o *taskOrder) String() string {
+	return o.spec
+}
+
+// Apply orders the query by the sort keys and if a cursor is specified, filters the
+// query to the tasks that follow the cursor. The tasks after the cursor are those that
+// have the same values for the first keys and a later value for the next key.
+func (o *taskOrder) Apply(query *gorm.DB, cursor *pageCursor) (_ *gorm.DB, err error) {
+	if cursor != nil {
+		if len(cursor.Values) != len(o.keys) {
+			return nil, errInvalidCursor
+		}
+
+		var (
+			equal     []string
+			equalArgs []interface{}
+			after     []string
+			afterArgs []interface{}
+		)
+
+		for i, key := range o.keys {
+			var val interface{}
+			if val, err = key.decode(cursor.Values[i]); err != nil {
+				return nil, errInvalidCursor
+			}
+
+			// Tasks without a value are last, so there are no tasks after them
+			if val == nil {
+				equal = append(equal, key.column+" IS NULL")
+				continue
+			}
+
+			cmp := ">"
+			if o.desc[i] {
+				cmp = "<"
+			}
+
+			later := fmt.Sprintf("%s %s ?", key.column, cmp)
+			if key.nullable {
+				later = fmt.Sprintf("(%s IS NULL OR %s)", key.column, later)
+			}
+
+			after = append(after, strings.Join(append(append([]string{}, equal...), later), " AND "))
+			afterArgs = append(afterArgs, append(append([]interface{}{}, equalArgs...), val)...)
+
+			equal = append(equal, key.column+" = ?")
+			equalArgs = append(equalArgs, val)
+		}
+
+		after = append(after, strings.Join(append(equal, "tasks.id > ?"), " AND "))
+		afterArgs = append(afterArgs, append(equalArgs, cursor.ID)...)
+		query = query.Where("("+strings.Join(after, ") OR (")+")", afterArgs...)
+	}
+
+	for i, key := range o.keys {
+		if key.nullable {
+			query = query.Order(fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", key.column))
+		}
+
+		if o.desc[i] {
+			query = query.Order(key.column + " DESC")
+		} else {
+			query = query.Order(key.column)
+		}
+	}
+	return query.Order("tasks.id"), nil
+}
+
+// Cursor returns the cursor that the page after the task starts from.
+func (o *taskOrder) Cursor(task Task) pageCursor {
+	cursor := pageCursor{Order: o.spec, ID: task.ID, Values: make([]json.RawMessage, 0, len(o.keys))}
+	for _, key := range o.keys {
+		data, _ := json.Marshal(key.value(task))
+		cursor.Values = append(cursor.Values, data)
+	}
+	return cursor
+}
+
+// filterTaskList applies all of the filters of the list tasks request to the query of
+// the tasks visible to the user, returning an error if a filter is invalid. Deferred
+// tasks are hidden unless requested.
+func filterTaskList(db, query *gorm.DB, user uint, req ListTasksRequest, now time.Time) (_ *gorm.DB, err error) {
+	// Only return the tasks assigned to the user if requested
+	if req.Assigned {
+		query = query.Where("assignee_id = ?", user)
+	}
+
+	// Hide deferred tasks until they start
+	if !req.Deferred {
+		query = query.Where("start_at IS NULL OR start_at <= ?", now)
+	}
+
+	// Filter tasks that are labeled with all of the specified tags
+	if names := normalizeTagNames(req.Tags); len(names) > 0 {
+		tagged := db.Table("task_tags").Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.user_id = ? AND tags.name IN (?)", user, names).
+			Group("task_tags.task_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(names))
+		query = query.Where("id IN ?", tagged.SubQuery())
+	}
+
+	// Filter tasks by the values of their custom fields
+	if len(req.Fields) > 0 {
+		if query, err = filterFields(db, query, req.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only return the tasks in the checklist if requested
+	if req.Checklist > 0 {
+		query = query.Where("checklist_id = ?", req.Checklist)
+	}
+
+	// Filter tasks by their status, dates, and title
+	return filterTasks(query, req, now), nil
+}
+
+// filterTasks applies the completion, archive, date range, and title filters of the
+// list tasks request to the query.
+func filterTasks(query *gorm.DB, req ListTasksRequest, now time.Time) *gorm.DB {
+	if req.Completed != nil {
+		query = query.Where("tasks.completed = ?", *req.Completed)
+	}
+
+	if req.Archived != nil {
+		query = query.Where("tasks.archived = ?", *req.Archived)
+	}
+
+	if req.Overdue {
+		query = query.Where("tasks.completed = ? AND tasks.deadline < ?", false, now)
+	}
+
+	if req.DeadlineBefore != nil {
+		query = query.Where("tasks.deadline < ?", *req.DeadlineBefore)
+	}
+
+	if req.DeadlineAfter != nil {
+		query = query.Where("tasks.deadline > ?", *req.DeadlineAfter)
+	}
+
+	if req.CreatedBefore != nil {
+		query = query.Where("tasks.created_at < ?", *req.CreatedBefore)
+	}
+
+	if req.CreatedAfter != nil {
+		query = query.Where("tasks.created_at > ?", *req.CreatedAfter)
+	}
+
+	if req.UpdatedBefore != nil {
+		query = query.Where("tasks.updated_at < ?", *req.UpdatedBefore)
+	}
+
+	if req.UpdatedAfter != nil {
+		query = query.Where("tasks.updated_at > ?", *req.UpdatedAfter)
+	}
+
+	if title := strings.TrimSpace(req.Title); title != "" {
+		query = query.Where(`LOWER(tasks.title) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(title))+"%")
+	}
+	return query
+}
+
+//===========================================================================
+// Filter Helpers
+//===========================================================================
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the wildcards of a LIKE pattern so that the text is matched as is.
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
+func decodeSortInt(data json.RawMessage) (interface{}, error) {
+	var val int64
+	if err := json.Unmarshal(data, &val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func decodeSortString(data json.RawMessage) (interface{}, error) {
+	var val string
+	if err := json.Unmarshal(data, &val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func decodeSortTime(data json.RawMessage) (interface{}, error) {
+	var val *time.Time
+	if err := json.Unmarshal(data, &val); err != nil {
+		return nil, err
+	}
+
+	if val == nil {
+		return nil, nil
+	}
+	return *val, nil
+}
@@ -0,0 +1,120 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTaskFilters() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "errands"}, &list))
+
+	now := time.Now()
+	yesterday, tomorrow, nextWeek := now.Add(-24*time.Hour), now.Add(24*time.Hour), now.Add(7*24*time.Hour)
+	tasks := []Task{
+		{Title: "Buy milk", Deadline: &tomorrow},
+		{Title: "pay rent", Deadline: &yesterday},
+		{Title: "Return library books", Deadline: &nextWeek},
+		{Title: "buy 100% cotton socks"},
+		{Title: "renew passport", Deadline: &yesterday},
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	created := make([]time.Time, 0, len(tasks))
+	for _, task := range tasks {
+		task.ChecklistID = &list.ChecklistID
+
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids = append(ids, rep.TaskID)
+
+		var detail DetailTaskResponse
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", rep.TaskID), nil, &detail))
+		created = append(created, detail.Task.CreatedAt)
+	}
+
+	// Complete the rent and archive the passport so that they are no longer overdue
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[1]), map[string]interface{}{"completed": true}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[4]), map[string]interface{}{"archived": true}, nil))
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", ids[1]), nil, &detail))
+	updated := detail.Task.UpdatedAt
+
+	filter := func(query url.Values) []uint {
+		query.Set("checklist", fmt.Sprint(list.ChecklistID))
+		var rep ListTasksResponse
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?"+query.Encode(), nil, &rep))
+		return taskIDs(rep.Tasks)
+	}
+
+	stamp := func(ts time.Time) string {
+		return ts.Format(time.RFC3339Nano)
+	}
+
+	// Filter by completion, archive, and overdue status
+	require.Equal(s.T(), []uint{ids[1]}, filter(url.Values{"completed": {"true"}}))
+	require.Equal(s.T(), []uint{ids[0], ids[2], ids[3], ids[4]}, filter(url.Values{"completed": {"false"}}))
+	require.Equal(s.T(), []uint{ids[4]}, filter(url.Values{"archived": {"true"}}))
+	require.Equal(s.T(), []uint{ids[4]}, filter(url.Values{"overdue": {"true"}}))
+	require.Empty(s.T(), filter(url.Values{"overdue": {"true"}, "archived": {"false"}}))
+
+	// Filter by deadline, created, and updated ranges
+	require.Equal(s.T(), []uint{ids[0], ids[1], ids[4]}, filter(url.Values{"deadline_before": {stamp(now.Add(48 * time.Hour))}}))
+	require.Equal(s.T(), []uint{ids[0], ids[2]}, filter(url.Values{"deadline_after": {stamp(now)}}))
+	require.Equal(s.T(), []uint{ids[0]}, filter(url.Values{"deadline_after": {stamp(now)}, "deadline_before": {stamp(now.Add(48 * time.Hour))}}))
+	require.Equal(s.T(), []uint{ids[3], ids[4]}, filter(url.Values{"created_after": {stamp(created[2])}}))
+	require.Equal(s.T(), []uint{ids[0], ids[1]}, filter(url.Values{"created_before": {stamp(created[2])}}))
+	require.Equal(s.T(), []uint{ids[4]}, filter(url.Values{"updated_after": {stamp(updated)}}))
+	require.Equal(s.T(), []uint{ids[0], ids[2], ids[3]}, filter(url.Values{"updated_before": {stamp(updated)}, "completed": {"false"}, "archived": {"false"}}))
+
+	// Filter by title substring, ignoring case and treating wildcards literally
+	require.Equal(s.T(), []uint{ids[0], ids[3]}, filter(url.Values{"title": {"BUY"}}))
+	require.Equal(s.T(), []uint{ids[3]}, filter(url.Values{"title": {"100%"}}))
+	require.Empty(s.T(), filter(url.Values{"title": {"r_nt"}}))
+
+	// Sort by multiple keys, tasks without a deadline are always last
+	require.Equal(s.T(), []uint{ids[3], ids[0], ids[1], ids[4], ids[2]}, filter(url.Values{"sort": {"title"}}))
+	require.Equal(s.T(), []uint{ids[2], ids[4], ids[1], ids[0], ids[3]}, filter(url.Values{"sort": {"-title"}}))
+	require.Equal(s.T(), []uint{ids[1], ids[4], ids[0], ids[2], ids[3]}, filter(url.Values{"sort": {"deadline"}}))
+	require.Equal(s.T(), []uint{ids[2], ids[0], ids[4], ids[1], ids[3]}, filter(url.Values{"sort": {"-deadline", "-created"}}))
+	require.Equal(s.T(), []uint{ids[4], ids[1], ids[0], ids[2], ids[3]}, filter(url.Values{"sort": {"deadline,-updated"}}))
+	require.Equal(s.T(), []uint{ids[4], ids[3], ids[2], ids[1], ids[0]}, filter(url.Values{"sort": {"-created"}}))
+
+	// Paging with the cursor follows the requested sort
+	for _, sort := range []string{"-deadline,-created", "title", "updated,title"} {
+		expected := filter(url.Values{"sort": {sort}})
+		paged := make([]uint, 0, len(expected))
+		query := url.Values{"sort": {sort}, "per_page": {"2"}}
+		for {
+			var rep ListTasksResponse
+			query.Set("checklist", fmt.Sprint(list.ChecklistID))
+			require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?"+query.Encode(), nil, &rep))
+			paged = append(paged, taskIDs(rep.Tasks)...)
+			if rep.NextCursor == "" {
+				break
+			}
+			query.Set("cursor", rep.NextCursor)
+		}
+		require.Equal(s.T(), expected, paged, "cursor paging sorted by %s", sort)
+	}
+
+	// Invalid filters and sorts are rejected, as are cursors for a different sort
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?sort=color", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?sort=title,-title", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?deadline_before=tomorrow", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?completed=maybe", nil, nil))
+
+	var rep ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?sort=title&per_page=1", nil, &rep))
+	require.NotEmpty(s.T(), rep.NextCursor)
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?sort=-title&cursor="+rep.NextCursor, nil, nil))
+
+	// Clean up so that other tests are not affected by the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+}
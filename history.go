@@ -0,0 +1,257 @@
+package todos
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Object types and actions recorded in the history.
+const (
+	HistoryTask      = "task"
+	HistoryChecklist = "checklist"
+	HistoryCreate    = "create"
+	HistoryUpdate    = "update"
+	HistoryDelete    = "delete"
+	HistoryRestore   = "restore"
+)
+
+// FieldChange describes the old and new value of a single field of an object. On
+// create or restore the old value is nil and on delete the new value is nil.
+type FieldChange struct {
+	Field string      `json:"field" yaml:"field"`
+	Old   interface{} `json:"old" yaml:"old"`
+	New   interface{} `json:"new" yaml:"new"`
+}
+
+// FieldChanges are stored in the database as a JSON encoded text column.
+type FieldChanges []FieldChange
+
+// Scan implements sql.Scanner to decode the changes from JSON.
+func (f *FieldChanges) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case []byte:
+		return json.Unmarshal(v, f)
+	default:
+		return fmt.Errorf("cannot scan field changes from %T", src)
+	}
+}
+
+// Value implements driver.Valuer to encode the changes as JSON.
+func (f FieldChanges) Value() (driver.Value, error) {
+	if f == nil {
+		f = FieldChanges{}
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+//===========================================================================
+// History Handlers
+//===========================================================================
+
+// TaskHistory returns the history of changes to the task, oldest first.
+func (s *API) TaskHistory(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+	s.history(c, HistoryTask, task.ID)
+}
+
+// ChecklistHistory returns the history of changes to the checklist, oldest first.
+func (s *API) ChecklistHistory(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+	s.history(c, HistoryChecklist, list.ID)
+}
+
+// history writes the history response for the specified object.
+func (s *API) history(c *gin.Context, objectType string, objectID uint) {
+	var entries []History
+	err := s.db.Preload("User").
+		Where("object_type = ? AND object_id = ?", objectType, objectID).
+		Order("created_at").
+		Order("id").
+		Find(&entries).Error
+	if err != nil {
+		logger.Printf("could not fetch history: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	for i := range entries {
+		entries[i].Username = entries[i].User.Username
+	}
+
+	c.JSON(http.StatusOK, HistoryResponse{Success: true, History: entries})
+}
+
+//===========================================================================
+// History Helpers
+//===========================================================================
+
+// snapshot captures the fields of an object that are recorded in its history. Values
+// are normalized through JSON so that snapshots taken from different sources compare
+// equal and are stored in the same format that is returned by the API.
+type snapshot map[string]interface{}
+
+// taskSnapshot captures the user-editable fields of the task. The position of the task
+// is not recorded since moving a task may renumber every task in its checklist.
+func taskSnapshot(db *gorm.DB, taskID uint) (_ snapshot, err error) {
+	var task Task
+	if err = db.Preload("Tags").Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	detail := []Task{task}
+	if err = loadFieldValues(db, detail); err != nil {
+		return nil, err
+	}
+
+	return newSnapshot(map[string]interface{}{
+		"title":     task.Title,
+		"details":   task.Details,
+		"completed": task.Completed,
+		"archived":  task.Archived,
+		"priority":  task.Priority,
+		"estimate":  task.Estimate,
+		"assignee":  task.AssigneeID,
+		"checklist": task.ChecklistID,
+		"parent":    task.ParentID,
+		"deadline":  task.Deadline,
+		"start":     task.StartAt,
+		"repeat":    task.Repeat,
+		"tags":      tags,
+		"fields":    detail[0].Fields,
+	})
+}
+
+// checklistSnapshot captures the user-editable fields of the checklist.
+func checklistSnapshot(db *gorm.DB, checklistID uint) (_ snapshot, err error) {
+	var list Checklist
+	if err = db.Where("id = ?", checklistID).First(&list).Error; err != nil {
+		return nil, err
+	}
+
+	return newSnapshot(map[string]interface{}{
+		"title":    list.Title,
+		"details":  list.Details,
+		"deadline": list.Deadline,
+		"project":  list.ProjectID,
+	})
+}
+
+// newSnapshot normalizes the fields by encoding and decoding them as JSON.
+func newSnapshot(fields map[string]interface{}) (snap snapshot, err error) {
+	var data []byte
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// diff returns the fields whose values differ between the snapshots, ordered by field.
+// Either snapshot may be nil, e.g. before an object is created or after it is deleted,
+// in which case only fields with non-empty values are included.
+func diff(before, after snapshot) (changes FieldChanges) {
+	fields := make(map[string]struct{}, len(before)+len(after))
+	for field := range before {
+		fields[field] = struct{}{}
+	}
+	for field := range after {
+		fields[field] = struct{}{}
+	}
+
+	for field := range fields {
+		prev, next := before[field], after[field]
+		if reflect.DeepEqual(prev, next) {
+			continue
+		}
+
+		if (before == nil && isEmpty(field, next)) || (after == nil && isEmpty(field, prev)) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Old: prev, New: next})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// isEmpty returns true for the zero values of JSON decoded snapshot values.
+func isEmpty(field string, val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		if field == "priority" {
+			return v == PriorityNone.String()
+		}
+		return v == ""
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// recordHistory stores a history entry for the change to the object made by the user.
+// Updates that do not change any recorded fields are not stored.
+func recordHistory(db *gorm.DB, objectType string, objectID, userID uint, action string, before, after snapshot) error {
+	changes := diff(before, after)
+	if action == HistoryUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	entry := &History{
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		UserID:     userID,
+		Action:     action,
+		Changes:    changes,
+	}
+	return db.Create(entry).Error
+}
+
+// recordTaskCreate stores a history entry for a newly created task.
+func recordTaskCreate(db *gorm.DB, taskID, userID uint) (err error) {
+	var after snapshot
+	if after, err = taskSnapshot(db, taskID); err != nil {
+		return err
+	}
+	return recordHistory(db, HistoryTask, taskID, userID, HistoryCreate, nil, after)
+}
@@ -0,0 +1,76 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTaskHistory() {
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "audit", Priority: PriorityHigh}, &task))
+	path := fmt.Sprintf("/v1/tasks/%d", task.TaskID)
+
+	// Updates record only the fields that changed
+	deadline := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	update := map[string]interface{}{"deadline": deadline, "title": "audit", "tags": []string{"history"}}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, path, update, nil))
+
+	// Updates that do not change anything are not recorded
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, path, map[string]interface{}{"title": "audit"}, nil))
+
+	var rep HistoryResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"/history", nil, &rep))
+	require.Len(s.T(), rep.History, 2)
+
+	created := rep.History[0]
+	require.Equal(s.T(), HistoryCreate, created.Action)
+	require.Equal(s.T(), HistoryTask, created.ObjectType)
+	require.Equal(s.T(), "jane", created.Username)
+	require.Equal(s.T(), FieldChanges{
+		{Field: "priority", Old: nil, New: "high"},
+		{Field: "title", Old: nil, New: "audit"},
+	}, created.Changes)
+
+	updated := rep.History[1]
+	require.Equal(s.T(), HistoryUpdate, updated.Action)
+	require.Len(s.T(), updated.Changes, 2)
+	require.Equal(s.T(), "deadline", updated.Changes[0].Field)
+	require.Nil(s.T(), updated.Changes[0].Old)
+	require.NotNil(s.T(), updated.Changes[0].New)
+	require.Equal(s.T(), FieldChange{Field: "tags", Old: []interface{}{}, New: []interface{}{"history"}}, updated.Changes[1])
+
+	// Deleting the task records the final values of its fields
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, path+"/history", nil, nil))
+
+	var deleted History
+	db := s.api.DB()
+	require.NoError(s.T(), db.Where("object_type = ? AND object_id = ? AND action = ?", HistoryTask, task.TaskID, HistoryDelete).First(&deleted).Error)
+	require.Len(s.T(), deleted.Changes, 4)
+	require.Equal(s.T(), "audit", deleted.Changes[3].Old)
+	require.Nil(s.T(), deleted.Changes[3].New)
+
+	// Checklists record their history as well
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "chores"}, &list))
+	path = fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, path, map[string]interface{}{"title": "errands"}, nil))
+
+	rep = HistoryResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"/history", nil, &rep))
+	require.Len(s.T(), rep.History, 2)
+	require.Equal(s.T(), FieldChanges{{Field: "title", Old: "chores", New: "errands"}}, rep.History[1].Changes)
+
+	// Tasks moved to the trash with their checklist record their deletion
+	var chore CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "dishes", ChecklistID: &list.ChecklistID}, &chore))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+
+	deleted = History{}
+	require.NoError(s.T(), db.Where("object_type = ? AND object_id = ? AND action = ?", HistoryTask, chore.TaskID, HistoryDelete).First(&deleted).Error)
+	require.Contains(s.T(), deleted.Changes, FieldChange{Field: "title", Old: "dishes", New: nil})
+}
@@ -11,22 +11,145 @@ import (
 // Task is the primary database structure for the todos application and represents a
 // single unit of work that must be completed. Tasks are primarily described by their
 // title, but can also have arbitrary text details stored alongside it. Optionally, each
-// task can have a deadline, which is used for reminders and ordering. Each task is
-// assigned to a user, generally the user that created the task and the task can
-// optionally be assigned to a checklist. The primary modification of a task is to
-// complete it (which marks it as done) or to archive it (deleting it without removal).
+// task can have a deadline, which is used for reminders and ordering, and a start date
+// that hides the task until the work can begin. Each task is owned by a user, generally
+// the user that created the task, can be assigned to any user who can see it, and can
+// optionally be assigned to a checklist, where it is ordered by its position. The
+// primary modification of a task is to complete it (which marks it as done) or to
+// archive it (deleting it without removal), the server records when either happens.
+//
+// Tasks are given a priority and an estimate in points or minutes for planning, can be
+// tagged, and can repeat with a recurrence rule. Subtasks have a parent task and tasks
+// are blocked by the tasks they depend on. The subtask progress, blockers, number of
+// comments, time tracked, and custom field values of a task are computed on demand.
+// Tasks that are not in a workspace belong to the personal space of their user, and
+// deleted tasks are moved to the trash until they are restored or purged.
 type Task struct {
+	ID                uint                   `gorm:"primary_key" json:"id,omitempty"`
+	UserID            uint                   `json:"-"`
+	User              User                   `json:"-"`
+	Username          string                 `gorm:"-" json:"user,omitempty"`
+	AssigneeID        *uint                  `gorm:"index" json:"-"`
+	Assignee          string                 `gorm:"-" json:"assignee,omitempty"`
+	WorkspaceID       *uint                  `gorm:"index" json:"workspace,omitempty"`
+	Title             string                 `gorm:"not null;size:255" json:"title,omitempty" binding:"required"`
+	Details           string                 `gorm:"not null;size:4095" json:"details,omitempty"`
+	Completed         bool                   `json:"completed"`
+	CompletedAt       *time.Time             `json:"completed_at,omitempty"`
+	Archived          bool                   `json:"archived"`
+	ArchivedAt        *time.Time             `json:"archived_at,omitempty"`
+	Priority          Priority               `gorm:"not null;default:0" json:"priority,omitempty"`
+	Estimate          uint                   `gorm:"not null;default:0" json:"estimate,omitempty"`
+	ChecklistID       *uint                  `json:"checklist,omitempty"`
+	Position          int64                  `gorm:"not null;default:0" json:"position"`
+	Checklist         *Checklist             `json:"-"`
+	Deadline          *time.Time             `json:"deadline,omitempty"`
+	StartAt           *time.Time             `json:"start,omitempty"`
+	Repeat            string                 `gorm:"not null;default:'';size:255" json:"repeat,omitempty"`
+	Tags              []Tag                  `gorm:"many2many:task_tags" json:"tags,omitempty"`
+	ParentID          *uint                  `json:"parent,omitempty"`
+	Parent            *Task                  `json:"-"`
+	Children          []Task                 `gorm:"foreignkey:ParentID" json:"children,omitempty"`
+	Subtasks          uint                   `gorm:"-" json:"subtasks,omitempty"`
+	SubtasksCompleted uint                   `gorm:"-" json:"subtasks_completed,omitempty"`
+	Blocked           bool                   `gorm:"-" json:"blocked"`
+	BlockedBy         []uint                 `gorm:"-" json:"blocked_by,omitempty"`
+	Comments          uint                   `gorm:"-" json:"comments,omitempty"`
+	TimeTracked       int64                  `gorm:"-" json:"time_tracked,omitempty"`
+	Fields            map[string]interface{} `gorm:"-" json:"fields,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+	DeletedAt         *time.Time             `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// Tag is a user-defined label that can be applied to many tasks so that related work
+// can be found regardless of which checklist it is assigned to. Tags are owned by the
+// user that created them and tag names are unique for each user.
+type Tag struct {
+	ID        uint      `gorm:"primary_key" json:"id,omitempty"`
+	UserID    uint      `gorm:"unique_index:idx_tags_user_name;not null" json:"-"`
+	User      User      `json:"-"`
+	Name      string    `gorm:"unique_index:idx_tags_user_name;not null;size:255" json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Tasks     []Task    `gorm:"many2many:task_tags" json:"-"`
+}
+
+// Dependency records that a task cannot be started until another task is done, e.g.
+// that the task is blocked by the blocker. Both tasks must belong to the same user and
+// dependencies cannot form cycles.
+type Dependency struct {
+	TaskID    uint      `gorm:"primary_key;auto_increment:false" json:"task"`
+	Task      Task      `json:"-"`
+	BlockerID uint      `gorm:"primary_key;auto_increment:false" json:"blocker"`
+	Blocker   Task      `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Comment is a note left on a task by a user so that the discussion about a task is
+// kept as a history rather than being overwritten in the task details. Comments can
+// only be edited or deleted by their author.
+type Comment struct {
+	ID        uint      `gorm:"primary_key" json:"id,omitempty"`
+	TaskID    uint      `gorm:"index;not null" json:"task"`
+	Task      Task      `json:"-" binding:"-"`
+	UserID    uint      `gorm:"not null" json:"-"`
+	User      User      `json:"-"`
+	Username  string    `gorm:"-" json:"user,omitempty"`
+	Text      string    `gorm:"not null;size:4095" json:"text" binding:"required"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Attachment describes a file uploaded to a task, e.g. a screenshot or a PDF. The
+// contents of the file are kept in the blob storage under the key, the database only
+// stores its metadata. The content type is sniffed from the contents of the file
+// rather than trusting the type declared by the client.
+type Attachment struct {
+	ID          uint      `gorm:"primary_key" json:"id,omitempty"`
+	TaskID      uint      `gorm:"index;not null" json:"task"`
+	Task        Task      `json:"-" binding:"-"`
+	UserID      uint      `gorm:"not null" json:"-"`
+	User        User      `json:"-"`
+	Filename    string    `gorm:"not null;size:255" json:"filename"`
+	ContentType string    `gorm:"not null;size:255" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	Key         string    `gorm:"unique;not null;size:255" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// History records a single create, update, or delete of a task or checklist: who made
+// the change, when it was made, and the old and new values of every field that was
+// changed. The object is referenced by its type and id rather than by a foreign key so
+// that the history is retained after the object is deleted.
+type History struct {
+	ID         uint         `gorm:"primary_key" json:"id,omitempty"`
+	ObjectType string       `gorm:"index:idx_histories_object;not null;size:31" json:"object_type"`
+	ObjectID   uint         `gorm:"index:idx_histories_object;not null" json:"object_id"`
+	UserID     uint         `gorm:"not null" json:"-"`
+	User       User         `json:"-"`
+	Username   string       `gorm:"-" json:"user,omitempty"`
+	Action     string       `gorm:"not null;size:15" json:"action"`
+	Changes    FieldChanges `gorm:"type:text;not null" json:"changes,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// TimeEntry records time spent by a user working on a task. Entries are either created
+// by starting and stopping a timer or entered manually. A running timer has no stopped
+// time and each user can only have one running timer. The duration of the entry is
+// stored in seconds when it is stopped so that totals can be computed by the database.
+type TimeEntry struct {
 	ID          uint       `gorm:"primary_key" json:"id,omitempty"`
-	UserID      uint       `json:"-"`
+	TaskID      uint       `gorm:"index;not null" json:"task"`
+	Task        Task       `json:"-" binding:"-"`
+	TaskTitle   string     `gorm:"-" json:"task_title,omitempty"`
+	ChecklistID *uint      `gorm:"-" json:"checklist,omitempty"`
+	UserID      uint       `gorm:"index;not null" json:"-"`
 	User        User       `json:"-"`
-	Username    string     `gorm:"-" json:"user,omitempty"`
-	Title       string     `gorm:"not null;size:255" json:"title,omitempty" binding:"required"`
-	Details     string     `gorm:"not null;size:4095" json:"details,omitempty"`
-	Completed   bool       `json:"completed"`
-	Archived    bool       `json:"archived"`
-	ChecklistID *uint      `json:"checklist,omitempty"`
-	Checklist   *Checklist `json:"-"`
-	Deadline    *time.Time `json:"deadline,omitempty"`
+	Started     time.Time  `gorm:"not null" json:"started"`
+	Stopped     *time.Time `json:"stopped,omitempty"`
+	Seconds     int64      `gorm:"not null;default:0" json:"seconds"`
+	Notes       string     `gorm:"not null;default:'';size:255" json:"notes,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
@@ -37,27 +160,181 @@ type Task struct {
 // users, which manage their lists. Similar to tasks, checklists are described by a
 // title and optional details. However, checklists can only be "completed" if all of its
 // tasks are either completed or archived, and this is not directly stored in the
-// database, but is rather computed on demand. Checklists can also have a deadline,
-// which is used for reminders and checklist ordering.
+// database, but is rather computed on demand along with the estimated effort of its
+// tasks. Checklists can also have a deadline, which is used for reminders and checklist
+// ordering.
+//
+// Related checklists can be grouped into a project and owners can share a checklist
+// with other users; the role of the requesting user is reported with the checklist.
+// Checklists can define typed custom fields that each of their tasks store values for.
+// Checklists belong to the workspace they were created in and are moved to the trash
+// together with their tasks when they are deleted.
 type Checklist struct {
+	ID              uint          `gorm:"primary_key" json:"id,omitempty"`
+	UserID          uint          `json:"-"`
+	User            User          `json:"-"`
+	Username        string        `gorm:"-" json:"user,omitempty"`
+	WorkspaceID     *uint         `gorm:"index" json:"workspace,omitempty"`
+	Title           string        `gorm:"not null;size:255" json:"title,omitempty"`
+	Details         string        `gorm:"not null;size:4095" json:"details,omitempty"`
+	ProjectID       *uint         `gorm:"index" json:"project,omitempty"`
+	Project         *Project      `json:"-"`
+	Role            string        `gorm:"-" json:"role,omitempty"`
+	Completed       uint          `gorm:"-" json:"completed,omitempty"`
+	Archived        uint          `gorm:"-" json:"archived,omitempty"`
+	Size            uint          `gorm:"-" json:"size"`
+	Done            bool          `gorm:"-" json:"done"`
+	Effort          uint          `gorm:"-" json:"effort,omitempty"`
+	EffortCompleted uint          `gorm:"-" json:"effort_completed,omitempty"`
+	EffortRemaining uint          `gorm:"-" json:"effort_remaining,omitempty"`
+	Deadline        *time.Time    `json:"deadline,omitempty"`
+	TimeTracked     int64         `gorm:"-" json:"time_tracked,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	DeletedAt       *time.Time    `gorm:"index" json:"deleted_at,omitempty"`
+	Fields          []CustomField `json:"fields,omitempty"`
+	Tasks           []Task        `json:"tasks,omitempty"`
+}
+
+// ChecklistShare gives another user access to a checklist and its tasks. Viewers can
+// read the checklist and its tasks while editors can also modify them; only the owner
+// of the checklist can share, unshare, or delete it.
+type ChecklistShare struct {
+	ChecklistID uint      `gorm:"primary_key;auto_increment:false" json:"checklist"`
+	Checklist   Checklist `json:"-"`
+	UserID      uint      `gorm:"primary_key;auto_increment:false" json:"-"`
+	User        User      `json:"-"`
+	Username    string    `gorm:"-" json:"user"`
+	Role        string    `gorm:"not null;size:15" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CustomField is a typed field of metadata defined by a checklist, e.g. the customer,
+// ticket number, or environment, that each task in the checklist can store a value
+// for. Fields are text, number, date, enum, or boolean typed and enum fields restrict
+// their values to the options of the field. Field names are unique in the checklist.
+type CustomField struct {
+	ID          uint         `gorm:"primary_key" json:"id,omitempty"`
+	ChecklistID uint         `gorm:"unique_index:idx_custom_fields_checklist_name;not null" json:"checklist"`
+	Checklist   Checklist    `json:"-" binding:"-"`
+	Name        string       `gorm:"unique_index:idx_custom_fields_checklist_name;not null;size:255" json:"name" binding:"required"`
+	Type        string       `gorm:"not null;size:15" json:"type" binding:"required"`
+	Options     FieldOptions `gorm:"type:text;not null" json:"options,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// FieldValue is the value of a custom field of a task. Values are stored as text in the
+// canonical form of the type of the field so that tasks can be filtered by value.
+type FieldValue struct {
+	TaskID    uint        `gorm:"primary_key;auto_increment:false" json:"task"`
+	Task      Task        `json:"-"`
+	FieldID   uint        `gorm:"primary_key;auto_increment:false" json:"field"`
+	Field     CustomField `json:"-"`
+	Value     string      `gorm:"not null;size:4095" json:"value"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Project groups related checklists, e.g. all of the checklists for a product or an
+// area of responsibility, forming a project, checklist, task hierarchy. Like checklists,
+// the progress of a project is not stored in the database but is aggregated from the
+// tasks of its checklists on demand. Deleting a project does not delete its checklists.
+// Projects belong to the workspace they were created in and only group its checklists.
+type Project struct {
+	ID            uint        `gorm:"primary_key" json:"id,omitempty"`
+	UserID        uint        `gorm:"index;not null" json:"-"`
+	User          User        `json:"-"`
+	WorkspaceID   *uint       `gorm:"index" json:"workspace,omitempty"`
+	Title         string      `gorm:"not null;size:255" json:"title,omitempty" binding:"required"`
+	Details       string      `gorm:"not null;size:4095" json:"details,omitempty"`
+	Deadline      *time.Time  `json:"deadline,omitempty"`
+	NumChecklists uint        `gorm:"-" json:"num_checklists"`
+	Size          uint        `gorm:"-" json:"size"`
+	Completed     uint        `gorm:"-" json:"completed,omitempty"`
+	Archived      uint        `gorm:"-" json:"archived,omitempty"`
+	Done          bool        `gorm:"-" json:"done"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Checklists    []Checklist `json:"checklists,omitempty"`
+}
+
+// ChecklistTemplate is a reusable blueprint for checklists that are run repeatedly,
+// e.g. onboarding or release checklists. Templates are saved from an existing checklist
+// and instantiated into new checklists. Deadlines are stored relative to an anchor time
+// so that the deadlines of the new checklist are computed from the anchor it is given.
+type ChecklistTemplate struct {
+	ID             uint           `gorm:"primary_key" json:"id,omitempty"`
+	UserID         uint           `gorm:"index;not null" json:"-"`
+	User           User           `json:"-"`
+	Title          string         `gorm:"not null;size:255" json:"title,omitempty"`
+	Details        string         `gorm:"not null;size:4095" json:"details,omitempty"`
+	DeadlineOffset *int64         `json:"deadline_offset,omitempty"`
+	Tasks          []TemplateTask `gorm:"foreignkey:TemplateID" json:"tasks,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TemplateTask is a task that is created when its template is instantiated. Tasks are
+// created in the order of their position and the deadline offset is the number of
+// seconds from the anchor time to the deadline of the task (negative if before).
+type TemplateTask struct {
+	ID             uint     `gorm:"primary_key" json:"id,omitempty"`
+	TemplateID     uint     `gorm:"index;not null" json:"-"`
+	Position       int      `gorm:"not null;default:0" json:"position"`
+	Title          string   `gorm:"not null;size:255" json:"title"`
+	Details        string   `gorm:"not null;size:4095" json:"details,omitempty"`
+	Priority       Priority `gorm:"not null;default:0" json:"priority,omitempty"`
+	Estimate       uint     `gorm:"not null;default:0" json:"estimate,omitempty"`
+	DeadlineOffset *int64   `json:"deadline_offset,omitempty"`
+}
+
+// SavedView is a named filter and sort of the task list, e.g. "Overdue work tasks", so
+// that a list the user returns to often does not have to be specified again. Views are
+// owned by the user that created them and are evaluated against the tasks of the active
+// workspace when they are used. View names are unique for each user.
+type SavedView struct {
 	ID        uint       `gorm:"primary_key" json:"id,omitempty"`
-	UserID    uint       `json:"-"`
-	User      User       `json:"-"`
-	Username  string     `gorm:"-" json:"user,omitempty"`
-	Title     string     `gorm:"not null;size:255" json:"title,omitempty"`
-	Details   string     `gorm:"not null;size:4095" json:"details,omitempty"`
-	Completed uint       `gorm:"-" json:"completed,omitempty"`
-	Archived  uint       `gorm:"-" json:"archived,omitempty"`
-	Size      uint       `gorm:"-" json:"size"`
-	Deadline  *time.Time `json:"deadline,omitempty"`
+	UserID    uint       `gorm:"unique_index:idx_saved_views_user_name;not null" json:"-"`
+	User      User       `json:"-" binding:"-"`
+	Name      string     `gorm:"unique_index:idx_saved_views_user_name;not null;size:255" json:"name" binding:"required"`
+	Filter    ViewFilter `gorm:"type:text;not null" json:"filter"`
+	Count     int        `gorm:"-" json:"count"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
-	Tasks     []Task     `json:"tasks,omitempty"`
+}
+
+// Workspace partitions the tasks and checklists of a team so that a single server can
+// host several teams. Users select the active workspace with a header or when they log
+// in; tasks and checklists that are not in a workspace are in the personal space of
+// their user. Workspace owners manage the members of the workspace.
+type Workspace struct {
+	ID        uint              `gorm:"primary_key" json:"id,omitempty"`
+	Name      string            `gorm:"not null;size:255" json:"name,omitempty" binding:"required"`
+	Role      string            `gorm:"-" json:"role,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Members   []WorkspaceMember `json:"members,omitempty" binding:"-"`
+}
+
+// WorkspaceMember gives a user access to a workspace either as an owner, who can
+// manage the workspace and its members, or as a member.
+type WorkspaceMember struct {
+	WorkspaceID uint      `gorm:"primary_key;auto_increment:false" json:"-"`
+	Workspace   Workspace `json:"-"`
+	UserID      uint      `gorm:"primary_key;auto_increment:false" json:"-"`
+	User        User      `json:"-"`
+	Username    string    `gorm:"-" json:"user"`
+	Role        string    `gorm:"not null;size:15" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // User is primarily used for authentication and storing json web tokens. Each user in
 // the system manages their own tasks and checklists through the API. This is the
-// primary partitioning mechanism between tasks.
+// primary partitioning mechanism between tasks. Admin users are global server admins
+// regardless of the workspaces they are members of.
 type User struct {
 	ID            uint        `gorm:"primary_key" json:"id"`
 	Username      string      `gorm:"unique;not null;size:255" json:"username"`
@@ -71,16 +348,19 @@ type User struct {
 	UpdatedAt     time.Time   `json:"updated_at"`
 	Tasks         []Task      `json:"-"`
 	Lists         []Checklist `json:"-"`
+	Tags          []Tag       `json:"-"`
 	Tokens        []Token     `json:"-"`
 }
 
 // Token holds an access and refresh tokens, which are granted after authentication and
 // used to authorize further requests using a Bearer header. The refresh token is used
-// to update authentication without having to submit a login and password again.
+// to update authentication without having to submit a login and password again. The
+// token can select the active workspace for requests that do not specify one.
 type Token struct {
 	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
 	UserID       uint      `gorm:"not null" json:"user_id"`
 	User         User      `json:"-"`
+	WorkspaceID  *uint     `json:"workspace,omitempty"`
 	IssuedAt     time.Time `json:"issued_at"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	RefreshBy    time.Time `json:"refresh_by"`
@@ -91,16 +371,65 @@ type Token struct {
 // Migrate the schema based on the models defined below.
 func Migrate(db *gorm.DB) (err error) {
 	// Migrate auth models
-	db.AutoMigrate(&User{}, &Token{})
+	db.AutoMigrate(&User{}, &Workspace{}, &WorkspaceMember{}, &Token{})
 	db.Model(&Token{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+	db.Model(&Token{}).AddForeignKey("workspace_id", "workspaces(id)", "CASCADE", "RESTRICT")
+	db.Model(&WorkspaceMember{}).AddForeignKey("workspace_id", "workspaces(id)", "CASCADE", "RESTRICT")
+	db.Model(&WorkspaceMember{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
 
 	// Migrate todos models
-	db.AutoMigrate(&Task{}, &Checklist{})
+	db.AutoMigrate(&Task{}, &Checklist{}, &ChecklistShare{}, &CustomField{}, &FieldValue{}, &Project{}, &Tag{}, &Dependency{}, &Comment{}, &Attachment{}, &History{}, &TimeEntry{}, &ChecklistTemplate{}, &TemplateTask{}, &SavedView{})
 	db.Model(&Task{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
 	db.Model(&Task{}).AddForeignKey("checklist_id", "checklists(id)", "CASCADE", "RESTRICT")
+	db.Model(&Task{}).AddForeignKey("parent_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&Task{}).AddForeignKey("assignee_id", "users(id)", "SET NULL", "RESTRICT")
+	db.Model(&Task{}).AddForeignKey("workspace_id", "workspaces(id)", "RESTRICT", "RESTRICT")
 	db.Model(&Checklist{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&Checklist{}).AddForeignKey("project_id", "projects(id)", "SET NULL", "RESTRICT")
+	db.Model(&Checklist{}).AddForeignKey("workspace_id", "workspaces(id)", "RESTRICT", "RESTRICT")
+	db.Model(&ChecklistShare{}).AddForeignKey("checklist_id", "checklists(id)", "CASCADE", "RESTRICT")
+	db.Model(&ChecklistShare{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
+	db.Model(&CustomField{}).AddForeignKey("checklist_id", "checklists(id)", "CASCADE", "RESTRICT")
+	db.Model(&FieldValue{}).AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&FieldValue{}).AddForeignKey("field_id", "custom_fields(id)", "CASCADE", "RESTRICT")
+	db.Model(&Project{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&Project{}).AddForeignKey("workspace_id", "workspaces(id)", "RESTRICT", "RESTRICT")
+	db.Model(&Tag{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Table("task_tags").AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Table("task_tags").AddForeignKey("tag_id", "tags(id)", "CASCADE", "RESTRICT")
+	db.Model(&Dependency{}).AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&Dependency{}).AddForeignKey("blocker_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&Comment{}).AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&Comment{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&Attachment{}).AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&Attachment{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&History{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&TimeEntry{}).AddForeignKey("task_id", "tasks(id)", "CASCADE", "RESTRICT")
+	db.Model(&TimeEntry{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&ChecklistTemplate{}).AddForeignKey("user_id", "users(id)", "RESTRICT", "RESTRICT")
+	db.Model(&TemplateTask{}).AddForeignKey("template_id", "checklist_templates(id)", "CASCADE", "RESTRICT")
+	db.Model(&SavedView{}).AddForeignKey("user_id", "users(id)", "CASCADE", "RESTRICT")
 	db.Model(&User{}).AddForeignKey("default_list_id", "checklists(id)", "CASCADE", "RESTRICT")
 
+	// Tasks created before manual ordering must be positioned before positions are unique
+	if err = backfillPositions(db); err != nil {
+		return err
+	}
+	db.Model(&Task{}).AddUniqueIndex("idx_tasks_checklist_position", "checklist_id", "position")
+
+	// Tasks closed before completion and archive times were recorded use their last update
+	if err = backfillClosedAt(db); err != nil {
+		return err
+	}
+
+	// Each user can only have one running timer
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_running ON time_entries (user_id) WHERE stopped IS NULL")
+
+	// Index the titles and details of tasks and checklists for full-text search
+	if err = migrateSearch(db); err != nil {
+		return err
+	}
+
 	errors := db.GetErrors()
 	if len(errors) > 1 {
 		return fmt.Errorf("%d errors occurred during migration", len(errors))
@@ -0,0 +1,342 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Tasks in a checklist are ordered by sparse positions so that a task can usually be
+// moved by updating only its own position to the midpoint of its new neighbors. When
+// there is no room left between two neighbors, the checklist is rebalanced so that all
+// positions are separated by the gap again. Tasks in the trash keep their positions so
+// that the unique index on checklist positions also holds for them.
+const positionGap int64 = 1 << 16
+
+var (
+	errNoChecklist      = errors.New("task must belong to a checklist to be ordered")
+	errMoveTarget       = errors.New("specify exactly one task to move before or after")
+	errMoveSelf         = errors.New("cannot move a task relative to itself")
+	errIncompleteOrder  = errors.New("ordering must contain every task in the checklist exactly once")
+	errDifferentList    = errors.New("tasks must belong to the same checklist to be ordered")
+	errChecklistMissing = errors.New("checklist does not exist")
+)
+
+//===========================================================================
+// Ordering Handlers
+//===========================================================================
+
+// MoveTask changes the position of the task within its checklist so that it is placed
+// immediately before or after another task in the same checklist.
+func (s *API) MoveTask(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	var req MoveTaskRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if (req.Before == 0) == (req.After == 0) {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errMoveTarget))
+		return
+	}
+
+	if task.ChecklistID == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errNoChecklist))
+		return
+	}
+
+	var position int64
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = lockChecklist(tx, *task.ChecklistID); err != nil {
+			return err
+		}
+
+		target, before := req.After, false
+		if req.Before > 0 {
+			target, before = req.Before, true
+		}
+
+		position, err = moveTask(tx, &task, target, before)
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, MoveTaskResponse{Success: true, Position: position})
+}
+
+// ReorderChecklist sets the order of all of the tasks in the checklist at once. The
+// request must contain the id of every task in the checklist exactly once.
+func (s *API) ReorderChecklist(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	var req ReorderChecklistRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = lockChecklist(tx, list.ID); err != nil {
+			return err
+		}
+
+		var ids []uint
+		if err = tx.Model(&Task{}).Where("checklist_id = ?", list.ID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) != len(req.Tasks) {
+			return errIncompleteOrder
+		}
+
+		var trashed []uint
+		if err = tx.Unscoped().Model(&Task{}).Where("checklist_id = ? AND deleted_at IS NOT NULL", list.ID).Order("position").Pluck("id", &trashed).Error; err != nil {
+			return err
+		}
+
+		members := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			members[id] = true
+		}
+
+		for _, id := range req.Tasks {
+			if !members[id] {
+				return errIncompleteOrder
+			}
+			delete(members, id)
+		}
+
+		// Tasks in the trash are placed after the ordered tasks
+		return setPositions(tx, append(req.Tasks, trashed...))
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ReorderChecklistResponse{Success: true})
+}
+
+//===========================================================================
+// Ordering Helpers
+//===========================================================================
+
+// BeforeCreate assigns new tasks that belong to a checklist to the end of the list.
+func (t *Task) BeforeCreate(scope *gorm.Scope) (err error) {
+	if t.ChecklistID == nil || t.Position != 0 {
+		return nil
+	}
+
+	db := scope.NewDB()
+	if err = lockChecklist(db, *t.ChecklistID); err != nil {
+		return err
+	}
+
+	var position int64
+	if position, err = nextPosition(db, *t.ChecklistID); err != nil {
+		return err
+	}
+	return scope.SetColumn("Position", position)
+}
+
+// moveToChecklist assigns the task to the end of the specified checklist, which must be
+// in the same workspace as the task; the caller must ensure the user can edit the
+// checklist. If the checklist is nil, the task is removed from its checklist.
+func moveToChecklist(tx *gorm.DB, task *Task, checklistID *uint) (err error) {
+	var position int64
+	if checklistID != nil {
+		query := inWorkspace(tx, "checklists", task.WorkspaceID)
+		if err = query.Select("id").Where("id = ?", *checklistID).First(&Checklist{}).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return errChecklistMissing
+			}
+			return err
+		}
+
+		if err = lockChecklist(tx, *checklistID); err != nil {
+			return err
+		}
+
+		if position, err = nextPosition(tx, *checklistID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(task).UpdateColumns(map[string]interface{}{"checklist_id": checklistID, "position": position}).Error
+}
+
+// sameChecklist returns true if both checklist ids refer to the same checklist.
+func sameChecklist(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// lockChecklist serializes modifications to the ordering of the tasks in a checklist
+// by locking the checklist row until the end of the transaction. SQLite does not
+// support row locking but serializes all writes, so no lock is required.
+func lockChecklist(tx *gorm.DB, checklistID uint) (err error) {
+	query := tx.Select("id").Where("id = ?", checklistID)
+	if tx.Dialect().GetName() == "postgres" {
+		query = query.Set("gorm:query_option", "FOR UPDATE")
+	}
+
+	if err = query.First(&Checklist{}).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return errChecklistMissing
+		}
+		return err
+	}
+	return nil
+}
+
+// nextPosition returns the position after the last task in the checklist, including the
+// tasks of the checklist that are in the trash.
+func nextPosition(tx *gorm.DB, checklistID uint) (_ int64, err error) {
+	var row struct{ Position *int64 }
+	if err = tx.Unscoped().Model(&Task{}).Select("MAX(position) AS position").Where("checklist_id = ?", checklistID).Scan(&row).Error; err != nil {
+		return 0, err
+	}
+
+	if row.Position == nil {
+		return positionGap, nil
+	}
+	return *row.Position + positionGap, nil
+}
+
+// moveTask positions the task immediately before or after the target task, which must
+// be in the same checklist. If there is no room between the target and its neighbor,
+// the checklist is rebalanced first. The checklist should be locked by the caller.
+func moveTask(tx *gorm.DB, task *Task, targetID uint, before bool) (position int64, err error) {
+	if targetID == task.ID {
+		return 0, errMoveSelf
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var target Task
+		if err = tx.Select("id, checklist_id, position").Where("id = ? AND user_id = ?", targetID, task.UserID).First(&target).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return 0, fmt.Errorf("task %d does not exist", targetID)
+			}
+			return 0, err
+		}
+
+		if target.ChecklistID == nil || *target.ChecklistID != *task.ChecklistID {
+			return 0, errDifferentList
+		}
+
+		// Find the neighbor on the other side of the target, ignoring the moving task but
+		// not the tasks in the trash, whose positions are still taken
+		var neighbor struct{ Position *int64 }
+		query := tx.Unscoped().Model(&Task{}).Where("checklist_id = ? AND id <> ?", *task.ChecklistID, task.ID)
+		if before {
+			query = query.Select("MAX(position) AS position").Where("position < ?", target.Position)
+		} else {
+			query = query.Select("MIN(position) AS position").Where("position > ?", target.Position)
+		}
+
+		if err = query.Scan(&neighbor).Error; err != nil {
+			return 0, err
+		}
+
+		switch {
+		case neighbor.Position == nil && before:
+			position = target.Position - positionGap
+		case neighbor.Position == nil:
+			position = target.Position + positionGap
+		default:
+			lo, hi := *neighbor.Position, target.Position
+			if !before {
+				lo, hi = target.Position, *neighbor.Position
+			}
+
+			if hi-lo < 2 {
+				// No room between the neighbors, rebalance and try again
+				if err = rebalance(tx, *task.ChecklistID); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			position = lo + (hi-lo)/2
+		}
+
+		if err = tx.Model(task).UpdateColumn("position", position).Error; err != nil {
+			return 0, err
+		}
+		return position, nil
+	}
+	return 0, errors.New("could not find room to move task after rebalancing")
+}
+
+// rebalance evenly spaces the positions of all the tasks in the checklist, including the
+// tasks in the trash, while preserving their current order.
+func rebalance(tx *gorm.DB, checklistID uint) (err error) {
+	var ids []uint
+	if err = tx.Unscoped().Model(&Task{}).Where("checklist_id = ?", checklistID).Order("position").Order("id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return setPositions(tx, ids)
+}
+
+// setPositions assigns evenly spaced positions to the tasks in the specified order. So
+// that the unique index on checklist positions is never violated mid-update, the tasks
+// are first moved to temporary negative positions before being assigned their final
+// positions. The ids must include every task in the checklist, including the tasks in
+// the trash.
+func setPositions(tx *gorm.DB, ids []uint) (err error) {
+	for i, id := range ids {
+		if err = tx.Unscoped().Model(&Task{}).Where("id = ?", id).UpdateColumn("position", -int64(i+1)).Error; err != nil {
+			return err
+		}
+	}
+
+	for i, id := range ids {
+		if err = tx.Unscoped().Model(&Task{}).Where("id = ?", id).UpdateColumn("position", int64(i+1)*positionGap).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillPositions rebalances any checklists whose tasks share positions, e.g. tasks
+// that were created before tasks could be ordered. It is run during migration before
+// the unique index on checklist positions is created.
+func backfillPositions(db *gorm.DB) (err error) {
+	var checklists []uint
+	err = db.Unscoped().Model(&Task{}).
+		Where("checklist_id IS NOT NULL").
+		Group("checklist_id").
+		Having("COUNT(*) > COUNT(DISTINCT position)").
+		Pluck("checklist_id", &checklists).Error
+	if err != nil {
+		return err
+	}
+
+	for _, checklistID := range checklists {
+		if err = db.Transaction(func(tx *gorm.DB) error {
+			return rebalance(tx, checklistID)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
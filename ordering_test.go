@@ -0,0 +1,96 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTaskOrdering() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "ordering"}, &list))
+
+	// Tasks are appended to the end of the checklist as they are created
+	ids := make([]uint, 5)
+	for i := range ids {
+		var rep CreateTaskResponse
+		task := Task{Title: fmt.Sprintf("step %d", i+1), ChecklistID: &list.ChecklistID}
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids[i] = rep.TaskID
+	}
+
+	order := func() []uint {
+		var rep ListTasksResponse
+		path := fmt.Sprintf("/v1/tasks?checklist=%d", list.ChecklistID)
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &rep))
+
+		positions := make(map[int64]bool)
+		for _, task := range rep.Tasks {
+			require.False(s.T(), positions[task.Position], "duplicate task position")
+			positions[task.Position] = true
+		}
+		return taskIDs(rep.Tasks)
+	}
+	require.Equal(s.T(), ids, order())
+
+	move := func(id uint, req MoveTaskRequest) int {
+		return s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d/move", id), req, nil)
+	}
+
+	// Move tasks to the front, the back and between other tasks
+	require.Equal(s.T(), http.StatusOK, move(ids[4], MoveTaskRequest{Before: ids[0]}))
+	require.Equal(s.T(), []uint{ids[4], ids[0], ids[1], ids[2], ids[3]}, order())
+	require.Equal(s.T(), http.StatusOK, move(ids[4], MoveTaskRequest{After: ids[3]}))
+	require.Equal(s.T(), ids, order())
+	require.Equal(s.T(), http.StatusOK, move(ids[0], MoveTaskRequest{After: ids[2]}))
+	require.Equal(s.T(), []uint{ids[1], ids[2], ids[0], ids[3], ids[4]}, order())
+
+	// Repeatedly moving into the same gap exhausts it and requires a rebalance
+	expected := []uint{ids[1], ids[2], ids[0], ids[3], ids[4]}
+	for i := 0; i < 40; i++ {
+		last := expected[len(expected)-1]
+		require.Equal(s.T(), http.StatusOK, move(last, MoveTaskRequest{After: expected[0]}))
+		expected = append([]uint{expected[0], last}, expected[1:len(expected)-1]...)
+	}
+	require.Equal(s.T(), expected, order())
+
+	// Invalid moves are rejected
+	require.Equal(s.T(), http.StatusBadRequest, move(ids[0], MoveTaskRequest{}))
+	require.Equal(s.T(), http.StatusBadRequest, move(ids[0], MoveTaskRequest{Before: ids[1], After: ids[2]}))
+	require.Equal(s.T(), http.StatusBadRequest, move(ids[0], MoveTaskRequest{Before: ids[0]}))
+
+	var other CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "unlisted"}, &other))
+	require.Equal(s.T(), http.StatusBadRequest, move(ids[0], MoveTaskRequest{Before: other.TaskID}))
+	require.Equal(s.T(), http.StatusBadRequest, move(other.TaskID, MoveTaskRequest{Before: ids[0]}))
+
+	// Send the full ordering of the checklist
+	reorder := func(tasks []uint) int {
+		return s.Do(http.MethodPut, fmt.Sprintf("/v1/lists/%d/order", list.ChecklistID), ReorderChecklistRequest{Tasks: tasks}, nil)
+	}
+
+	reversed := []uint{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	require.Equal(s.T(), http.StatusOK, reorder(reversed))
+	require.Equal(s.T(), reversed, order())
+	require.Equal(s.T(), http.StatusBadRequest, reorder(ids[:4]))
+	require.Equal(s.T(), http.StatusBadRequest, reorder([]uint{ids[0], ids[0], ids[1], ids[2], ids[3]}))
+	require.Equal(s.T(), http.StatusBadRequest, reorder([]uint{ids[0], ids[1], ids[2], ids[3], other.TaskID}))
+
+	// The checklist detail returns its tasks in order
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, &detail))
+	require.Equal(s.T(), reversed, taskIDs(detail.Checklist.Tasks))
+
+	// Moving a task into the checklist places it at the end
+	update := map[string]interface{}{"checklist": list.ChecklistID}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", other.TaskID), update, nil))
+	require.Equal(s.T(), append(reversed, other.TaskID), order())
+
+	// The position of new tasks is assigned by the server
+	var last CreateTaskResponse
+	task := Task{Title: "step 6", ChecklistID: &list.ChecklistID, Position: detail.Checklist.Tasks[0].Position}
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &last))
+	require.Equal(s.T(), append(reversed, other.TaskID, last.TaskID), order())
+}
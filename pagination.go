@@ -0,0 +1,179 @@
+package todos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Page size limits for list requests: if per_page is not specified the default is
+// used, larger page sizes are reduced to the maximum.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+var (
+	errInvalidPage   = errors.New("page and per_page must not be negative")
+	errInvalidCursor = errors.New("invalid pagination cursor")
+	errPageAndCursor = errors.New("specify either a page or a cursor, not both")
+)
+
+// pagination limits a list query to a single page of results. In offset mode the page
+// is selected by number and the total number of pages is counted. In cursor mode the
+// page starts after the row the cursor was created from, which is stable even if rows
+// are added or removed while the client is paging through the results.
+type pagination struct {
+	Page     int
+	PerPage  int
+	NumPages int
+	cursor   *pageCursor
+}
+
+// pageCursor is the position of the last row of a page in the order of the list: the
+// values of the columns the list is sorted by and the id that breaks ties. It is encoded
+// as opaque base64 JSON so that clients do not depend on its contents.
+type pageCursor struct {
+	Order  string            `json:"o"`
+	ID     uint              `json:"i"`
+	Values []json.RawMessage `json:"v,omitempty"`
+}
+
+// newPagination validates the pagination parameters of a list request. If a cursor is
+// specified it must have been created for the same order of the list.
+func newPagination(page, perPage int, cursor, order string) (p *pagination, err error) {
+	if page < 0 || perPage < 0 {
+		return nil, errInvalidPage
+	}
+
+	if page > 0 && cursor != "" {
+		return nil, errPageAndCursor
+	}
+
+	p = &pagination{Page: page, PerPage: perPage}
+	if p.PerPage == 0 {
+		p.PerPage = DefaultPageSize
+	}
+
+	if p.PerPage > MaxPageSize {
+		p.PerPage = MaxPageSize
+	}
+
+	if cursor != "" {
+		if p.cursor, err = decodeCursor(cursor, order); err != nil {
+			return nil, err
+		}
+		p.Page = 0
+		return p, nil
+	}
+
+	if p.Page == 0 {
+		p.Page = 1
+	}
+	return p, nil
+}
+
+// Cursor returns the cursor the page starts after or nil if paginating by page number.
+func (p *pagination) Cursor() *pageCursor {
+	return p.cursor
+}
+
+// Apply limits the ordered query to the page. In offset mode the rows of the model are
+// counted to compute the number of pages. In cursor mode the query must already be
+// filtered to the rows after the cursor; one extra row is fetched to determine if there
+// is a next page.
+func (p *pagination) Apply(query *gorm.DB, model interface{}) (*gorm.DB, error) {
+	if p.cursor != nil {
+		return query.Limit(p.PerPage + 1), nil
+	}
+
+	var total int
+	if err := query.Model(model).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	p.NumPages = (total + p.PerPage - 1) / p.PerPage
+	return query.Offset((p.Page - 1) * p.PerPage).Limit(p.PerPage), nil
+}
+
+// More reports if there are rows after the page, given the number of rows fetched. If
+// so, the caller should trim the rows to PerPage and return a cursor to the next page.
+func (p *pagination) More(rows int) bool {
+	if p.cursor != nil {
+		return rows > p.PerPage
+	}
+	return p.Page < p.NumPages && rows > 0
+}
+
+// Link sets the Link header (RFC 8288) of the response with the URLs of the related
+// pages. In offset mode the first, previous, next, and last pages are linked, in cursor
+// mode only the next page can be linked.
+func (p *pagination) Link(c *gin.Context, next string) {
+	links := make([]string, 0, 4)
+	link := func(rel, param, value string) {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Del("page")
+		query.Del("cursor")
+		query.Set(param, value)
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel))
+	}
+
+	if p.cursor != nil {
+		if next != "" {
+			link("next", "cursor", next)
+		}
+	} else {
+		if p.NumPages > 0 {
+			link("first", "page", "1")
+		}
+		if p.Page > 1 && p.NumPages > 0 {
+			prev := p.Page - 1
+			if prev > p.NumPages {
+				prev = p.NumPages
+			}
+			link("prev", "page", strconv.Itoa(prev))
+		}
+		if p.Page < p.NumPages {
+			link("next", "page", strconv.Itoa(p.Page+1))
+		}
+		if p.NumPages > 0 {
+			link("last", "page", strconv.Itoa(p.NumPages))
+		}
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+//===========================================================================
+// Cursor Helpers
+//===========================================================================
+
+// Encode the cursor as an opaque URL safe string.
+func (c pageCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses the cursor and checks that it was created for the order.
+func decodeCursor(cursor, order string) (c *pageCursor, err error) {
+	var data []byte
+	if data, err = base64.RawURLEncoding.DecodeString(cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	c = &pageCursor{}
+	if err = json.Unmarshal(data, c); err != nil || c.Order != order || c.ID == 0 {
+		return nil, errInvalidCursor
+	}
+	return c, nil
+}
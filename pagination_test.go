@@ -0,0 +1,122 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestPagination() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "chores"}, &list))
+
+	ids := make([]uint, 0, 5)
+	deadline := time.Now().Add(48 * time.Hour)
+	for i, priority := range []Priority{PriorityLow, PriorityHigh, PriorityLow, PriorityNone, PriorityHigh} {
+		task := Task{Title: fmt.Sprintf("chore %d", i), ChecklistID: &list.ChecklistID, Priority: priority}
+		if i%2 == 0 {
+			task.Deadline = &deadline
+		}
+
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids = append(ids, rep.TaskID)
+	}
+
+	// Tasks in a checklist are paginated by page number in checklist order
+	var tasks ListTasksResponse
+	path := fmt.Sprintf("/v1/tasks?checklist=%d&per_page=2", list.ChecklistID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &tasks))
+	require.Equal(s.T(), ids[:2], taskIDs(tasks.Tasks))
+	require.Equal(s.T(), 1, tasks.Page)
+	require.Equal(s.T(), 3, tasks.NumPages)
+	require.NotEmpty(s.T(), tasks.NextCursor)
+
+	tasks = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"&page=3", nil, &tasks))
+	require.Equal(s.T(), ids[4:], taskIDs(tasks.Tasks))
+	require.Empty(s.T(), tasks.NextCursor)
+
+	tasks = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"&page=4", nil, &tasks))
+	require.Empty(s.T(), tasks.Tasks)
+
+	// The Link header references the related pages of the list
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path+"&page=2", nil)
+	req.Header.Set("Authorization", "Bearer "+s.Login(false))
+	s.router.ServeHTTP(w, req)
+	require.Equal(s.T(), http.StatusOK, w.Code)
+	link := w.Header().Get("Link")
+	require.Contains(s.T(), link, fmt.Sprintf(`</v1/tasks?checklist=%d&page=1&per_page=2>; rel="first"`, list.ChecklistID))
+	require.Contains(s.T(), link, fmt.Sprintf(`</v1/tasks?checklist=%d&page=1&per_page=2>; rel="prev"`, list.ChecklistID))
+	require.Contains(s.T(), link, fmt.Sprintf(`</v1/tasks?checklist=%d&page=3&per_page=2>; rel="next"`, list.ChecklistID))
+	require.Contains(s.T(), link, fmt.Sprintf(`</v1/tasks?checklist=%d&page=3&per_page=2>; rel="last"`, list.ChecklistID))
+
+	// Paging with the cursor returns the same tasks as the triage order of all tasks
+	var all ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks?per_page=%d", MaxPageSize), nil, &all))
+	require.Empty(s.T(), all.NextCursor)
+
+	paged := make([]Task, 0, len(all.Tasks))
+	cursor := ""
+	for {
+		tasks = ListTasksResponse{}
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?per_page=2&cursor="+cursor, nil, &tasks))
+		require.LessOrEqual(s.T(), len(tasks.Tasks), 2)
+		paged = append(paged, tasks.Tasks...)
+		if tasks.NextCursor == "" {
+			break
+		}
+		cursor = tasks.NextCursor
+	}
+	require.Equal(s.T(), taskIDs(all.Tasks), taskIDs(paged))
+
+	// The cursor remains stable when earlier tasks are removed
+	tasks = ListTasksResponse{}
+	path = fmt.Sprintf("/v1/tasks?checklist=%d&per_page=2", list.ChecklistID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &tasks))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", ids[0]), nil, nil))
+
+	cursor = tasks.NextCursor
+	tasks = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"&cursor="+cursor, nil, &tasks))
+	require.Equal(s.T(), ids[2:4], taskIDs(tasks.Tasks))
+	require.Zero(s.T(), tasks.Page)
+
+	// Invalid pagination parameters are rejected
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?page=-1", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?page=2&cursor="+cursor, nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?cursor=notacursor", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/tasks?cursor="+cursor, nil, nil))
+
+	// Checklists are paginated in the order they were created
+	var second CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "groceries"}, &second))
+
+	var lists ListChecklistsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists?per_page=%d", MaxPageSize+1), nil, &lists))
+	require.GreaterOrEqual(s.T(), len(lists.Checklists), 2)
+	require.Equal(s.T(), 1, lists.NumPages)
+	count := len(lists.Checklists)
+
+	lists = ListChecklistsResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists?per_page=1&page=%d", count-1), nil, &lists))
+	require.Equal(s.T(), list.ChecklistID, lists.Checklists[0].ID)
+	require.Equal(s.T(), count, lists.NumPages)
+
+	cursor = lists.NextCursor
+	lists = ListChecklistsResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/lists?per_page=1&cursor="+cursor, nil, &lists))
+	require.Len(s.T(), lists.Checklists, 1)
+	require.Equal(s.T(), second.ChecklistID, lists.Checklists[0].ID)
+	require.Empty(s.T(), lists.NextCursor)
+
+	// Clean up so that other tests are not affected by the checklists
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", second.ChecklistID), nil, nil))
+}
@@ -0,0 +1,243 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MIMEMergePatch is the content type of a JSON merge patch (RFC 7396). Tasks and
+// checklists are updated with merge patches: only the fields in the patch are modified
+// and null removes the value of a field.
+const MIMEMergePatch = "application/merge-patch+json"
+
+var (
+	errMergePatchType   = fmt.Errorf("updates must be sent as %s or %s", MIMEMergePatch, binding.MIMEJSON)
+	errMergePatchObject = errors.New("merge patch must be a JSON object")
+)
+
+// patchField is a field that can be modified by a merge patch. The field's value is
+// parsed from its JSON value and stored in the column; null values are rejected unless
+// the field is nullable, in which case null is parsed into the value that removes it.
+type patchField struct {
+	column   string
+	nullable bool
+	parse    func(interface{}) (interface{}, error)
+}
+
+// patchSchema is the allow-list of the fields of an object that can be modified by a
+// merge patch, keyed by JSON name. Fields that are read-only are ignored rather than
+// rejected so that an object fetched from the API can be sent back as a patch; any
+// other field in the patch is rejected so that clients cannot modify server-managed
+// columns such as the owner or the creation time of the object.
+type patchSchema struct {
+	fields   map[string]patchField
+	readOnly map[string]struct{}
+}
+
+// taskPatch is the schema of task updates. Parent, checklist, assignee, tags, and
+// custom fields are not columns of the task, they are updated by the handler.
+var taskPatch = patchSchema{
+	fields: map[string]patchField{
+		"title":        {column: "title", parse: patchText("title", 255, true)},
+		"details":      {column: "details", nullable: true, parse: patchText("details", 4095, false)},
+		"completed":    {column: "completed", parse: patchBool("completed")},
+		"archived":     {column: "archived", parse: patchBool("archived")},
+		"priority":     {column: "priority", nullable: true, parse: func(v interface{}) (interface{}, error) { return ParsePriority(v) }},
+		"estimate":     {column: "estimate", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseEstimate(v) }},
+		"deadline":     {column: "deadline", nullable: true, parse: patchTime("deadline")},
+		"start":        {column: "start_at", nullable: true, parse: patchTime("start")},
+		"repeat":       {column: "repeat", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseRepeat(v) }},
+		"checklist":    {column: "checklist_id", nullable: true, parse: patchID(errChecklistMissing)},
+		"checklist_id": {column: "checklist_id", nullable: true, parse: patchID(errChecklistMissing)},
+		"parent":       {column: "parent_id", nullable: true, parse: patchID(errParentNotFound)},
+		"parent_id":    {column: "parent_id", nullable: true, parse: patchID(errParentNotFound)},
+		"assignee":     {column: "assignee", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseAssignee(v) }},
+		"tags":         {column: "tags", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseTagsInput(v) }},
+		"fields":       {column: "fields", parse: func(v interface{}) (interface{}, error) { return parseFieldsInput(v) }},
+	},
+	readOnly: readOnlyFields(
+		"id", "user", "workspace", "completed_at", "archived_at", "position", "children",
+		"subtasks", "subtasks_completed", "blocked", "blocked_by", "comments",
+		"time_tracked", "created_at", "updated_at", "deleted_at",
+	),
+}
+
+// checklistPatch is the schema of checklist updates. Custom fields are modified
+// individually and tasks are modified or moved with the task endpoints.
+var checklistPatch = patchSchema{
+	fields: map[string]patchField{
+		"title":      {column: "title", parse: patchText("title", 255, true)},
+		"details":    {column: "details", nullable: true, parse: patchText("details", 4095, false)},
+		"deadline":   {column: "deadline", nullable: true, parse: patchTime("deadline")},
+		"project":    {column: "project_id", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseProject(v) }},
+		"project_id": {column: "project_id", nullable: true, parse: func(v interface{}) (interface{}, error) { return parseProject(v) }},
+	},
+	readOnly: readOnlyFields(
+		"id", "user", "workspace", "role", "completed", "archived", "size", "done",
+		"effort", "effort_completed", "effort_remaining", "time_tracked", "created_at",
+		"updated_at", "deleted_at", "fields", "tasks",
+	),
+}
+
+// projectPatch is the schema of project updates. Checklists are added to or removed
+// from the project by updating the checklist.
+var projectPatch = patchSchema{
+	fields: map[string]patchField{
+		"title":    {column: "title", parse: patchText("title", 255, true)},
+		"details":  {column: "details", nullable: true, parse: patchText("details", 4095, false)},
+		"deadline": {column: "deadline", nullable: true, parse: patchTime("deadline")},
+	},
+	readOnly: readOnlyFields(
+		"id", "workspace", "num_checklists", "size", "completed", "archived", "done", "created_at",
+		"updated_at", "checklists",
+	),
+}
+
+// workspacePatch is the schema of workspace updates. Members are managed with the
+// members endpoints.
+var workspacePatch = patchSchema{
+	fields: map[string]patchField{
+		"name": {column: "name", parse: patchText("name", 255, true)},
+	},
+	readOnly: readOnlyFields("id", "role", "created_at", "updated_at", "members"),
+}
+
+// Parse validates the fields of the merge patch against the schema and returns the
+// parsed values keyed by column. An error is returned if the patch contains a field
+// that cannot be modified, a value of the wrong type, or null for a required field.
+func (s patchSchema) Parse(patch map[string]interface{}) (values map[string]interface{}, err error) {
+	values = make(map[string]interface{}, len(patch))
+	names := make(map[string]string, len(patch))
+	for name, val := range patch {
+		field, ok := s.fields[name]
+		if !ok {
+			if _, ok = s.readOnly[name]; ok {
+				continue
+			}
+			return nil, fmt.Errorf("%q is not a field that can be updated", name)
+		}
+
+		if prev, ok := names[field.column]; ok {
+			return nil, fmt.Errorf("%q and %q cannot both be updated", prev, name)
+		}
+		names[field.column] = name
+
+		if val == nil && !field.nullable {
+			return nil, fmt.Errorf("%s cannot be null", name)
+		}
+
+		if values[field.column], err = field.parse(val); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// bindMergePatch decodes the merge patch from the body of the request. PATCH requests
+// must be merge patches or JSON, PUT requests are decoded as JSON regardless of their
+// content type so that earlier clients can still update tasks and checklists.
+func bindMergePatch(c *gin.Context) (patch map[string]interface{}, err error) {
+	if c.Request.Method == http.MethodPatch {
+		if ct := c.ContentType(); ct != MIMEMergePatch && ct != binding.MIMEJSON {
+			return nil, errMergePatchType
+		}
+	}
+
+	var body interface{}
+	if err = c.ShouldBindJSON(&body); err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if patch, ok = body.(map[string]interface{}); !ok {
+		return nil, errMergePatchObject
+	}
+	return patch, nil
+}
+
+// readOnlyFields returns the set of read-only fields of a patch schema.
+func readOnlyFields(names ...string) map[string]struct{} {
+	fields := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		fields[name] = struct{}{}
+	}
+	return fields
+}
+
+// patchText parses strings of at most max bytes, null removes the text. If the text is
+// required it cannot be empty.
+func patchText(name string, max int, required bool) func(interface{}) (interface{}, error) {
+	return func(val interface{}) (interface{}, error) {
+		if val == nil {
+			return "", nil
+		}
+
+		text, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string", name)
+		}
+
+		if required && text == "" {
+			return nil, fmt.Errorf("%s cannot be empty", name)
+		}
+
+		if len(text) > max {
+			return nil, fmt.Errorf("%s cannot be longer than %d characters", name, max)
+		}
+		return text, nil
+	}
+}
+
+// patchBool parses booleans, booleans are never nullable.
+func patchBool(name string) func(interface{}) (interface{}, error) {
+	return func(val interface{}) (interface{}, error) {
+		b, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s must be true or false", name)
+		}
+		return b, nil
+	}
+}
+
+// patchTime parses RFC 3339 timestamps, null removes the time.
+func patchTime(name string) func(interface{}) (interface{}, error) {
+	return func(val interface{}) (interface{}, error) {
+		if val == nil {
+			return (*time.Time)(nil), nil
+		}
+
+		text, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+
+		ts, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		return &ts, nil
+	}
+}
+
+// patchID parses the id of a related object, null removes the relation. The error is
+// returned if the value is not a valid id.
+func patchID(invalid error) func(interface{}) (interface{}, error) {
+	return func(val interface{}) (interface{}, error) {
+		if val == nil {
+			return (*uint)(nil), nil
+		}
+
+		id, ok := val.(float64)
+		if !ok || id <= 0 || id != float64(uint(id)) {
+			return nil, invalid
+		}
+
+		pk := uint(id)
+		return &pk, nil
+	}
+}
@@ -0,0 +1,118 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestMergePatch() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "paperwork", Details: "taxes and forms"}, &list))
+
+	deadline := time.Now().Add(48 * time.Hour)
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "file taxes", Details: "use the new form", ChecklistID: &list.ChecklistID, Deadline: &deadline}, &task))
+	path := fmt.Sprintf("/v1/tasks/%d", task.TaskID)
+
+	// patch sends a merge patch with the content type and returns the response code
+	patch := func(path, contentType, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+s.Login(false))
+		s.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	var before DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &before))
+
+	// Only the fields in the patch are modified
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPatch, path, map[string]interface{}{"completed": true}, nil))
+	require.Equal(s.T(), http.StatusOK, patch(path, MIMEMergePatch, `{"title": "file the taxes"}`))
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), "file the taxes", detail.Task.Title)
+	require.Equal(s.T(), "use the new form", detail.Task.Details)
+	require.True(s.T(), detail.Task.Completed)
+	require.NotNil(s.T(), detail.Task.Deadline)
+	require.Equal(s.T(), list.ChecklistID, *detail.Task.ChecklistID)
+
+	// Null clears the deadline, checklist, and details of the task
+	require.Equal(s.T(), http.StatusOK, patch(path, MIMEMergePatch, `{"deadline": null, "checklist": null, "details": null}`))
+	detail = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Nil(s.T(), detail.Task.Deadline)
+	require.Nil(s.T(), detail.Task.ChecklistID)
+	require.Empty(s.T(), detail.Task.Details)
+	require.Equal(s.T(), "file the taxes", detail.Task.Title)
+
+	// Deadlines are validated and stored as timestamps
+	require.Equal(s.T(), http.StatusOK, patch(path, MIMEMergePatch, `{"deadline": "2030-04-15T17:00:00Z"}`))
+	detail = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.True(s.T(), time.Date(2030, 4, 15, 17, 0, 0, 0, time.UTC).Equal(*detail.Task.Deadline))
+
+	// Read-only fields are ignored, server-managed columns cannot be modified
+	require.Equal(s.T(), http.StatusOK, patch(path, MIMEMergePatch, `{"id": 1, "created_at": "2000-01-01T00:00:00Z"}`))
+	require.Equal(s.T(), http.StatusBadRequest, patch(path, MIMEMergePatch, `{"user_id": 2}`))
+	require.Equal(s.T(), http.StatusBadRequest, patch(path, MIMEMergePatch, `{"workspace_id": 2}`))
+	require.Equal(s.T(), http.StatusBadRequest, patch(path, MIMEMergePatch, `{"assignee_id": 2}`))
+
+	detail = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), task.TaskID, detail.Task.ID)
+	require.True(s.T(), before.Task.CreatedAt.Equal(detail.Task.CreatedAt))
+
+	// Values must have the type of the field and only optional fields can be null
+	for _, body := range []string{
+		`{"title": 42}`,
+		`{"title": ""}`,
+		`{"title": null}`,
+		`{"completed": "yes"}`,
+		`{"completed": null}`,
+		`{"deadline": "tomorrow"}`,
+		`{"checklist": "paperwork"}`,
+		`{"checklist": 1, "checklist_id": 1}`,
+		`{"tags": "urgent"}`,
+		`{"fields": null}`,
+		`["title"]`,
+		`not json`,
+	} {
+		require.Equal(s.T(), http.StatusBadRequest, patch(path, MIMEMergePatch, body), body)
+	}
+
+	// Patches must be JSON
+	require.Equal(s.T(), http.StatusUnsupportedMediaType, patch(path, "text/plain", `{"title": "file taxes"}`))
+
+	// Checklists are patched in the same manner
+	lpath := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+	require.Equal(s.T(), http.StatusOK, patch(lpath, MIMEMergePatch, `{"deadline": "2030-04-15T17:00:00Z"}`))
+	require.Equal(s.T(), http.StatusOK, patch(lpath, MIMEMergePatch, `{"details": null}`))
+
+	var checklist DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, lpath, nil, &checklist))
+	require.Equal(s.T(), "paperwork", checklist.Checklist.Title)
+	require.Empty(s.T(), checklist.Checklist.Details)
+	require.NotNil(s.T(), checklist.Checklist.Deadline)
+
+	require.Equal(s.T(), http.StatusOK, patch(lpath, MIMEMergePatch, `{"deadline": null}`))
+	checklist = DetailChecklistResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, lpath, nil, &checklist))
+	require.Nil(s.T(), checklist.Checklist.Deadline)
+
+	require.Equal(s.T(), http.StatusBadRequest, patch(lpath, MIMEMergePatch, `{"user_id": 2}`))
+	require.Equal(s.T(), http.StatusBadRequest, patch(lpath, MIMEMergePatch, `{"deadline": 5}`))
+	require.Equal(s.T(), http.StatusBadRequest, patch(lpath, MIMEMergePatch, `{"title": ""}`))
+
+	// Clean up so that other tests are not affected by the task and checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, lpath, nil, nil))
+}
@@ -0,0 +1,123 @@
+package todos
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Priority describes how urgent a task is. Priorities are stored in the database as
+// small integers so that they can be used for ordering, but are represented by their
+// names in JSON and YAML, e.g. "high" rather than 3.
+type Priority uint8
+
+// Priority levels from least to most urgent; by default tasks have no priority.
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityCritical
+)
+
+var priorityNames = [...]string{"none", "low", "medium", "high", "critical"}
+
+// ParsePriority returns the priority from its name or integer level. It accepts the
+// values that can be decoded from JSON (strings and float64) as well as integers and
+// returns an error if the value is not a valid priority.
+func ParsePriority(val interface{}) (Priority, error) {
+	switch v := val.(type) {
+	case nil:
+		return PriorityNone, nil
+	case Priority:
+		return v, v.Validate()
+	case string:
+		name := strings.ToLower(strings.TrimSpace(v))
+		if name == "" {
+			return PriorityNone, nil
+		}
+
+		for i, pname := range priorityNames {
+			if name == pname {
+				return Priority(i), nil
+			}
+		}
+
+		if level, err := strconv.ParseUint(name, 10, 8); err == nil {
+			p := Priority(level)
+			return p, p.Validate()
+		}
+		return PriorityNone, fmt.Errorf("%q is not a valid priority", v)
+	case float64:
+		if v != float64(int64(v)) || v < 0 || v > float64(PriorityCritical) {
+			return PriorityNone, fmt.Errorf("%v is not a valid priority", v)
+		}
+		return Priority(v), nil
+	case int:
+		if v < 0 || v > int(PriorityCritical) {
+			return PriorityNone, fmt.Errorf("%d is not a valid priority", v)
+		}
+		return Priority(v), nil
+	case int64:
+		if v < 0 || v > int64(PriorityCritical) {
+			return PriorityNone, fmt.Errorf("%d is not a valid priority", v)
+		}
+		return Priority(v), nil
+	default:
+		return PriorityNone, fmt.Errorf("cannot parse priority from %T", val)
+	}
+}
+
+// Validate returns an error if the priority is not one of the known levels.
+func (p Priority) Validate() error {
+	if int(p) >= len(priorityNames) {
+		return fmt.Errorf("%d is not a valid priority", p)
+	}
+	return nil
+}
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	if p.Validate() != nil {
+		return strconv.Itoa(int(p))
+	}
+	return priorityNames[p]
+}
+
+// MarshalJSON encodes the priority as its name.
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON decodes the priority from its name or integer level.
+func (p *Priority) UnmarshalJSON(data []byte) (err error) {
+	var val interface{}
+	if err = json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+
+	*p, err = ParsePriority(val)
+	return err
+}
+
+// MarshalYAML encodes the priority as its name for CLI output.
+func (p Priority) MarshalYAML() (interface{}, error) {
+	return p.String(), nil
+}
+
+// Scan implements sql.Scanner so that the priority can be read from the database or
+// set from the generic update maps used by the update handlers.
+func (p *Priority) Scan(src interface{}) (err error) {
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	*p, err = ParsePriority(src)
+	return err
+}
+
+// Value implements driver.Valuer so that the priority is stored as an integer.
+func (p Priority) Value() (driver.Value, error) {
+	return int64(p), nil
+}
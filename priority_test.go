@@ -0,0 +1,99 @@
+package todos_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in       interface{}
+		expected Priority
+	}{
+		{nil, PriorityNone},
+		{"", PriorityNone},
+		{"low", PriorityLow},
+		{"Medium", PriorityMedium},
+		{" high ", PriorityHigh},
+		{"critical", PriorityCritical},
+		{"3", PriorityHigh},
+		{float64(4), PriorityCritical},
+		{2, PriorityMedium},
+	}
+
+	for _, tc := range tests {
+		p, err := ParsePriority(tc.in)
+		require.NoError(t, err, "could not parse %v", tc.in)
+		require.Equal(t, tc.expected, p)
+	}
+
+	for _, in := range []interface{}{"urgent", float64(1.5), float64(5), -1, "9", true, float64(256), float64(259), float64(513), 259, int64(256), "259"} {
+		_, err := ParsePriority(in)
+		require.Error(t, err, "expected %v to be invalid", in)
+	}
+}
+
+func TestPriorityJSON(t *testing.T) {
+	data, err := json.Marshal(PriorityHigh)
+	require.NoError(t, err)
+	require.Equal(t, `"high"`, string(data))
+
+	var p Priority
+	require.NoError(t, json.Unmarshal([]byte(`"critical"`), &p))
+	require.Equal(t, PriorityCritical, p)
+
+	require.NoError(t, json.Unmarshal([]byte(`1`), &p))
+	require.Equal(t, PriorityLow, p)
+
+	require.Error(t, json.Unmarshal([]byte(`"whenever"`), &p))
+}
+
+func (s *TodosTestSuite) TestPriorityOrdering() {
+	soon := time.Now().Add(1 * time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+	tags := []Tag{{Name: "priority-ordering"}}
+
+	fixtures := []Task{
+		{Title: "no priority", Tags: tags},
+		{Title: "high later", Priority: PriorityHigh, Deadline: &later, Tags: tags},
+		{Title: "low", Priority: PriorityLow, Tags: tags},
+		{Title: "high soon", Priority: PriorityHigh, Deadline: &soon, Tags: tags},
+		{Title: "high no deadline", Priority: PriorityHigh, Tags: tags},
+		{Title: "critical", Priority: PriorityCritical, Tags: tags},
+	}
+
+	for _, task := range fixtures {
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, nil))
+	}
+
+	// Invalid priorities are rejected on create and update
+	code := s.Do(http.MethodPost, "/v1/tasks", map[string]interface{}{"title": "bad", "priority": "asap"}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	var rep ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks?tag=priority-ordering", nil, &rep))
+
+	titles := make([]string, 0, len(rep.Tasks))
+	for _, task := range rep.Tasks {
+		titles = append(titles, task.Title)
+	}
+	require.Equal(s.T(), []string{"critical", "high soon", "high later", "high no deadline", "low", "no priority"}, titles)
+
+	// Update the priority of the last task by name
+	last := rep.Tasks[len(rep.Tasks)-1].ID
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", last), map[string]interface{}{"priority": "medium"}, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", last), map[string]interface{}{"priority": "asap"}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", last), nil, &detail))
+	require.Equal(s.T(), PriorityMedium, detail.Task.Priority)
+}
@@ -0,0 +1,137 @@
+package todos
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//===========================================================================
+// Checklist Progress Helpers
+//===========================================================================
+
+// checklistProgress computes the number of tasks in each checklist, how many of them are
+// archived and how many of the rest are completed, and the estimated, completed, and
+// remaining effort of the tasks that are not archived in a single aggregate query. A
+// checklist is done when it has tasks and all of them are either completed or archived.
+func checklistProgress(db *gorm.DB, lists []Checklist) (err error) {
+	if len(lists) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(lists))
+	for _, list := range lists {
+		ids = append(ids, list.ID)
+	}
+
+	var rows []struct {
+		ChecklistID     uint
+		Size            uint
+		Completed       uint
+		Archived        uint
+		Effort          uint
+		EffortCompleted uint
+	}
+
+	err = db.Model(&Task{}).
+		Select(`checklist_id, COUNT(*) AS size,
+			SUM(CASE WHEN completed AND NOT archived THEN 1 ELSE 0 END) AS completed,
+			SUM(CASE WHEN archived THEN 1 ELSE 0 END) AS archived,
+			COALESCE(SUM(CASE WHEN archived THEN 0 ELSE estimate END), 0) AS effort,
+			COALESCE(SUM(CASE WHEN completed AND NOT archived THEN estimate ELSE 0 END), 0) AS effort_completed`).
+		Where("checklist_id IN (?)", ids).
+		Group("checklist_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	rollups := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		rollups[row.ChecklistID] = i
+	}
+
+	for i := range lists {
+		if idx, ok := rollups[lists[i].ID]; ok {
+			row := rows[idx]
+			lists[i].Size = row.Size
+			lists[i].Completed = row.Completed
+			lists[i].Archived = row.Archived
+			lists[i].Done = row.Size > 0 && row.Completed+row.Archived == row.Size
+			lists[i].Effort = row.Effort
+			lists[i].EffortCompleted = row.EffortCompleted
+			lists[i].EffortRemaining = row.Effort - row.EffortCompleted
+		}
+	}
+	return nil
+}
+
+// closedAt returns the current time as the time a task was completed or archived if the
+// state is true, otherwise nil to clear the timestamp when the task is reopened.
+func closedAt(state bool) *time.Time {
+	if !state {
+		return nil
+	}
+	now := time.Now()
+	return &now
+}
+
+// backfillClosedAt sets the completion and archive timestamps of tasks that were closed
+// before the timestamps were recorded to the last time the task was updated.
+func backfillClosedAt(db *gorm.DB) (err error) {
+	if err = db.Model(&Task{}).Where("completed = ? AND completed_at IS NULL", true).UpdateColumn("completed_at", gorm.Expr("updated_at")).Error; err != nil {
+		return err
+	}
+	return db.Model(&Task{}).Where("archived = ? AND archived_at IS NULL", true).UpdateColumn("archived_at", gorm.Expr("updated_at")).Error
+}
+
+// projectProgress computes the number of checklists in each project and the number of
+// tasks in those checklists that are archived and completed in a single aggregate
+// query. A project is done when it has tasks and all of them are completed or archived.
+func projectProgress(db *gorm.DB, projects []Project) (err error) {
+	if len(projects) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+
+	var rows []struct {
+		ProjectID     uint
+		NumChecklists uint
+		Size          uint
+		Completed     uint
+		Archived      uint
+	}
+
+	err = db.Table("checklists").
+		Select(`checklists.project_id, COUNT(DISTINCT checklists.id) AS num_checklists, COUNT(tasks.id) AS size,
+			SUM(CASE WHEN tasks.completed AND NOT tasks.archived THEN 1 ELSE 0 END) AS completed,
+			SUM(CASE WHEN tasks.archived THEN 1 ELSE 0 END) AS archived`).
+		Joins("LEFT JOIN tasks ON tasks.checklist_id = checklists.id AND tasks.deleted_at IS NULL").
+		Where("checklists.project_id IN (?) AND checklists.deleted_at IS NULL", ids).
+		Group("checklists.project_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	rollups := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		rollups[row.ProjectID] = i
+	}
+
+	for i := range projects {
+		if idx, ok := rollups[projects[i].ID]; ok {
+			row := rows[idx]
+			projects[i].NumChecklists = row.NumChecklists
+			projects[i].Size = row.Size
+			projects[i].Completed = row.Completed
+			projects[i].Archived = row.Archived
+			projects[i].Done = row.Size > 0 && row.Completed+row.Archived == row.Size
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestChecklistProgress() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "packing"}, &list))
+	path := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+
+	// Empty checklists are not done
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), uint(0), detail.Checklist.Size)
+	require.False(s.T(), detail.Checklist.Done)
+
+	ids := make([]uint, 3)
+	for i, title := range []string{"passport", "charger", "umbrella"} {
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: title, ChecklistID: &list.ChecklistID}, &rep))
+		ids[i] = rep.TaskID
+	}
+
+	// Completing and archiving tasks records when they were closed
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[0]), map[string]interface{}{"completed": true}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[2]), map[string]interface{}{"archived": true}, nil))
+
+	var task DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", ids[0]), nil, &task))
+	require.NotNil(s.T(), task.Task.CompletedAt)
+	require.Nil(s.T(), task.Task.ArchivedAt)
+
+	detail = DetailChecklistResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), uint(3), detail.Checklist.Size)
+	require.Equal(s.T(), uint(1), detail.Checklist.Completed)
+	require.Equal(s.T(), uint(1), detail.Checklist.Archived)
+	require.False(s.T(), detail.Checklist.Done)
+	require.Len(s.T(), detail.Checklist.Tasks, 3)
+	require.NotNil(s.T(), detail.Checklist.Tasks[2].ArchivedAt)
+
+	// The checklist is done when all of its tasks are completed or archived
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[1]), map[string]interface{}{"completed": true}, nil))
+
+	var lists ListChecklistsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/lists", nil, &lists))
+	found := false
+	for _, item := range lists.Checklists {
+		if item.ID == list.ChecklistID {
+			found = true
+			require.Equal(s.T(), uint(2), item.Completed)
+			require.True(s.T(), item.Done)
+		}
+	}
+	require.True(s.T(), found)
+
+	// Reopening a task clears its completion time; timestamps cannot be set directly
+	update := map[string]interface{}{"completed": false, "archived_at": "2020-01-01T00:00:00Z"}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[0]), update, nil))
+
+	task = DetailTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", ids[0]), nil, &task))
+	require.Nil(s.T(), task.Task.CompletedAt)
+	require.Nil(s.T(), task.Task.ArchivedAt)
+
+	detail = DetailChecklistResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.False(s.T(), detail.Checklist.Done)
+}
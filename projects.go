@@ -0,0 +1,202 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var errProjectMissing = errors.New("project must be a valid project id")
+
+//===========================================================================
+// Viewset for Project objects
+//===========================================================================
+
+// ListProjects returns all projects that belong to the authenticated user in the active
+// workspace along with their progress aggregated from the tasks of their checklists.
+func (s *API) ListProjects(c *gin.Context) {
+	var projects []Project
+	user := c.Value(ctxUserKey).(User)
+	query := inWorkspace(s.db, "projects", activeWorkspace(c))
+	if err := query.Where("user_id = ?", user.ID).Find(&projects).Error; err != nil {
+		logger.Printf("could not fetch projects: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := projectProgress(s.db, projects); err != nil {
+		logger.Printf("could not compute project progress: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListProjectsResponse{Success: true, Projects: projects})
+}
+
+// CreateProject creates a new grouping of checklists for the user in the active workspace.
+func (s *API) CreateProject(c *gin.Context) {
+	// Parse the user input
+	project := Project{}
+	if err := c.ShouldBind(&project); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// Checklists are added to the project by updating the checklist
+	user := c.Value(ctxUserKey).(User)
+	project.ID = 0
+	project.UserID = user.ID
+	project.WorkspaceID = activeWorkspace(c)
+	project.Checklists = nil
+
+	if err := s.db.Create(&project).Error; err != nil {
+		logger.Printf("could not create project: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateProjectResponse{Success: true, ProjectID: project.ID})
+}
+
+// DetailProject returns the project with its checklists and the progress of each.
+func (s *API) DetailProject(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	project, ok := s.userProject(c, user)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Where("project_id = ?", project.ID).Order("id").Find(&project.Checklists).Error; err != nil {
+		logger.Printf("could not fetch project checklists: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := checklistProgress(s.db, project.Checklists); err != nil {
+		logger.Printf("could not compute checklist progress: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	detail := []Project{project}
+	if err := projectProgress(s.db, detail); err != nil {
+		logger.Printf("could not compute project progress: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DetailProjectResponse{Success: true, Project: detail[0]})
+}
+
+// UpdateProject modifies the title, details, or deadline of the project with a JSON
+// merge patch (RFC 7396), in the same manner as checklists.
+func (s *API) UpdateProject(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	project, ok := s.userProject(c, user)
+	if !ok {
+		return
+	}
+
+	// Parse user input, only the fields in the allow-list can be modified
+	patch, err := bindMergePatch(c)
+	if err != nil {
+		if err == errMergePatchType {
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	var values map[string]interface{}
+	if values, err = projectPatch.Parse(patch); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if len(values) > 0 {
+		if err = s.db.Model(&project).Update(values).Error; err != nil {
+			logger.Printf("could not update project: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, UpdateProjectResponse{Success: true})
+}
+
+// DeleteProject removes the project; its checklists, including those in the trash, are
+// kept but no longer belong to a project.
+func (s *API) DeleteProject(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	project, ok := s.userProject(c, user)
+	if !ok {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Unscoped().Model(&Checklist{}).Where("project_id = ?", project.ID).Update("project_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&project).Error
+	})
+
+	if err != nil {
+		logger.Printf("could not delete project: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteProjectResponse{Success: true})
+}
+
+//===========================================================================
+// Project Helpers
+//===========================================================================
+
+// userProject fetches the project in the URL if it belongs to the user and the active
+// workspace, otherwise the error response is written and ok is false.
+func (s *API) userProject(c *gin.Context, user User) (project Project, ok bool) {
+	query := inWorkspace(s.db, "projects", activeWorkspace(c))
+	if err := query.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&project).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return project, false
+		}
+		logger.Printf("could not find project: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return project, false
+	}
+	return project, true
+}
+
+// parseProject validates the project of a checklist update, null removes the checklist
+// from its project.
+func parseProject(val interface{}) (*uint, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	id, ok := val.(float64)
+	if !ok || id <= 0 || id != float64(uint(id)) {
+		return nil, errProjectMissing
+	}
+	pid := uint(id)
+	return &pid, nil
+}
+
+// validateProject ensures that the project exists and belongs to the user and to the
+// workspace of the checklist that is added to it.
+func validateProject(db *gorm.DB, userID uint, workspace *uint, projectID uint) (err error) {
+	if err = inWorkspace(db, "projects", workspace).Select("id").Where("id = ? AND user_id = ?", projectID, userID).First(&Project{}).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("project %d does not exist", projectID)
+		}
+		logger.Printf("could not find project: %s", err)
+		return errInternal
+	}
+	return nil
+}
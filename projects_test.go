@@ -0,0 +1,73 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestProjects() {
+	var project CreateProjectResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/projects", Project{Title: "website"}, &project))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/projects", Project{}, nil))
+	path := fmt.Sprintf("/v1/projects/%d", project.ProjectID)
+
+	// Checklists can be created in a project or moved into one
+	var design, launch, other CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "design", ProjectID: &project.ProjectID}, &design))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "launch"}, &launch))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "other"}, &other))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/lists/%d", launch.ChecklistID), map[string]interface{}{"project": project.ProjectID}, nil))
+
+	missing := uint(99999)
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "orphan", ProjectID: &missing}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, fmt.Sprintf("/v1/lists/%d", other.ChecklistID), map[string]interface{}{"project": missing}, nil))
+
+	for i, list := range []uint{design.ChecklistID, design.ChecklistID, launch.ChecklistID} {
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: fmt.Sprintf("step %d", i), ChecklistID: &list}, &rep))
+		if i == 0 {
+			require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", rep.TaskID), map[string]interface{}{"completed": true}, nil))
+		}
+	}
+
+	// Progress is aggregated from the tasks of the project checklists
+	var detail DetailProjectResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), "website", detail.Project.Title)
+	require.Equal(s.T(), uint(2), detail.Project.NumChecklists)
+	require.Equal(s.T(), uint(3), detail.Project.Size)
+	require.Equal(s.T(), uint(1), detail.Project.Completed)
+	require.False(s.T(), detail.Project.Done)
+	require.Len(s.T(), detail.Project.Checklists, 2)
+	require.Equal(s.T(), uint(2), detail.Project.Checklists[0].Size)
+
+	var projects ListProjectsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/projects", nil, &projects))
+	require.NotEmpty(s.T(), projects.Projects)
+
+	// Checklists can be filtered by project
+	var lists ListChecklistsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists?project=%d", project.ProjectID), nil, &lists))
+	require.Len(s.T(), lists.Checklists, 2)
+
+	// Update the project, then delete it without deleting its checklists
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, path, map[string]interface{}{"title": "homepage"}, nil))
+	detail = DetailProjectResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), "homepage", detail.Project.Title)
+
+	// Only the title, details, and deadline of the project can be updated
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, path, map[string]interface{}{"user_id": 2}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, path, map[string]interface{}{"title": ""}, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, path, nil, nil))
+
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, path, nil, nil))
+
+	var list DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", design.ChecklistID), nil, &list))
+	require.Nil(s.T(), list.Checklist.ProjectID)
+}
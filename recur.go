@@ -0,0 +1,427 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Recurrence frequencies supported from the iCalendar RRULE specification.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// The maximum number of periods searched for the next occurrence of a rule; this
+// prevents rules whose BYDAY constraints can never be satisfied from looping forever.
+const maxRecurrencePeriods = 1000
+
+var (
+	weekdayNames = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+	weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+)
+
+// Recurrence is a parsed subset of an iCalendar RRULE (RFC 5545) that describes how a
+// task repeats. Tasks can repeat daily, weekly, or monthly at an interval, optionally
+// on specific days of the week and for a limited number of occurrences (COUNT) or
+// until a specific time (UNTIL). Weeks start on Monday.
+type Recurrence struct {
+	Freq     string          // one of DAILY, WEEKLY, or MONTHLY
+	Interval int             // the number of periods between occurrences (default 1)
+	ByDay    []RecurrenceDay // the days of the week the task occurs on (optional)
+	Count    int             // the number of remaining occurrences, including the current one
+	Until    *time.Time      // the last time an occurrence can happen (optional)
+}
+
+// RecurrenceDay is a BYDAY entry in a recurrence rule. The ordinal is only used for
+// monthly rules, e.g. 1MO is the first Monday of the month and -1FR is the last Friday.
+type RecurrenceDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// ParseRecurrence parses an RRULE string, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR".
+// The RRULE: prefix is optional and the shorthands daily, weekly, and monthly are also
+// accepted. An error is returned if the rule uses unsupported parts or is invalid.
+func ParseRecurrence(rule string) (r *Recurrence, err error) {
+	rule = strings.TrimSpace(rule)
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	// Handle shorthand frequencies
+	if !strings.Contains(rule, "=") {
+		rule = "FREQ=" + rule
+	}
+
+	r = &Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("could not parse recurrence rule part %q", part)
+		}
+
+		key, val := kv[0], kv[1]
+		if seen[key] {
+			return nil, fmt.Errorf("recurrence rule part %s specified more than once", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch val {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				r.Freq = val
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", val)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(val); err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval %q", val)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(val); err != nil || r.Count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count %q", val)
+			}
+		case "UNTIL":
+			var until time.Time
+			if until, err = parseRecurrenceTime(val); err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				var rd RecurrenceDay
+				if rd, err = parseRecurrenceDay(day); err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, rd)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if err = r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate the recurrence rule, ensuring that the combination of parts is supported.
+func (r *Recurrence) Validate() error {
+	if r.Freq == "" {
+		return errors.New("recurrence rule requires a frequency")
+	}
+
+	if r.Interval < 1 {
+		return errors.New("recurrence interval must be positive")
+	}
+
+	if r.Count > 0 && r.Until != nil {
+		return errors.New("recurrence rule cannot specify both COUNT and UNTIL")
+	}
+
+	for _, day := range r.ByDay {
+		if day.Ordinal != 0 && r.Freq != FreqMonthly {
+			return errors.New("BYDAY ordinals are only supported for monthly recurrence")
+		}
+		if day.Ordinal < -5 || day.Ordinal > 5 {
+			return fmt.Errorf("invalid BYDAY ordinal %d", day.Ordinal)
+		}
+	}
+	return nil
+}
+
+// String returns the normalized RRULE representation of the recurrence.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the BYDAY representation of the day, e.g. MO or -1FR.
+func (d RecurrenceDay) String() string {
+	if d.Ordinal != 0 {
+		return strconv.Itoa(d.Ordinal) + weekdayCodes[d.Weekday]
+	}
+	return weekdayCodes[d.Weekday]
+}
+
+// Next returns the first occurrence of the rule that is strictly after the specified
+// time, keeping the time of day of the input. If the rule has no more occurrences,
+// either because the count is exhausted or the next occurrence is after UNTIL, then
+// false is returned. Note that the count includes the occurrence at the input time.
+func (r *Recurrence) Next(after time.Time) (next time.Time, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	for k := 0; k < maxRecurrencePeriods; k++ {
+		candidates := r.period(after, k)
+		for _, candidate := range candidates {
+			if !candidate.After(after) {
+				continue
+			}
+
+			if r.Until != nil && candidate.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+
+		// Stop searching once the period has passed the until date
+		if r.Until != nil && len(candidates) > 0 && candidates[0].After(*r.Until) {
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
+// Advance returns the rule for the next occurrence, e.g. with the count decremented.
+func (r *Recurrence) Advance() *Recurrence {
+	next := *r
+	if next.Count > 1 {
+		next.Count--
+	}
+	return &next
+}
+
+// period returns the sorted candidate occurrences in the kth period after the period
+// that contains the base time.
+func (r *Recurrence) period(base time.Time, k int) (candidates []time.Time) {
+	y, m, d := base.Date()
+	hh, mm, ss := base.Clock()
+	loc := base.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hh, mm, ss, base.Nanosecond(), loc)
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		day := at(y, m, d+k*r.Interval)
+		if len(r.ByDay) == 0 || r.onDay(day.Weekday()) {
+			candidates = append(candidates, day)
+		}
+
+	case FreqWeekly:
+		// Weeks start on Monday
+		offset := (int(base.Weekday()) + 6) % 7
+		start := at(y, m, d-offset+k*7*r.Interval)
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(start.Year(), start.Month(), start.Day()+offset)}
+		}
+
+		for i := 0; i < 7; i++ {
+			day := at(start.Year(), start.Month(), start.Day()+i)
+			if r.onDay(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+		}
+
+	case FreqMonthly:
+		first := time.Date(y, m+time.Month(k*r.Interval), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		days := daysIn(year, month)
+
+		if len(r.ByDay) == 0 {
+			// Months that do not have the day are skipped as specified by RFC 5545
+			if d <= days {
+				candidates = append(candidates, at(year, month, d))
+			}
+			return candidates
+		}
+
+		for day := 1; day <= days; day++ {
+			date := at(year, month, day)
+			for _, rd := range r.ByDay {
+				if rd.Weekday != date.Weekday() {
+					continue
+				}
+
+				nth := (day-1)/7 + 1
+				fromEnd := -((days-day)/7 + 1)
+				if rd.Ordinal == 0 || rd.Ordinal == nth || rd.Ordinal == fromEnd {
+					candidates = append(candidates, date)
+					break
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+func (r *Recurrence) onDay(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func parseRecurrenceDay(val string) (day RecurrenceDay, err error) {
+	if len(val) < 2 {
+		return day, fmt.Errorf("invalid BYDAY value %q", val)
+	}
+
+	code := val[len(val)-2:]
+	weekday, ok := weekdayNames[code]
+	if !ok {
+		return day, fmt.Errorf("invalid BYDAY weekday %q", val)
+	}
+	day.Weekday = weekday
+
+	if ordinal := strings.TrimPrefix(val[:len(val)-2], "+"); ordinal != "" {
+		if day.Ordinal, err = strconv.Atoi(ordinal); err != nil || day.Ordinal == 0 {
+			return day, fmt.Errorf("invalid BYDAY ordinal %q", val)
+		}
+	}
+	return day, nil
+}
+
+func parseRecurrenceTime(val string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if ts, err := time.Parse(layout, val); err == nil {
+			// Dates without times include the entire day
+			if layout == "20060102" {
+				ts = ts.Add(24*time.Hour - time.Second)
+			}
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse recurrence until %q", val)
+}
+
+// parseRepeat validates and normalizes the recurrence rule of a task update, an empty
+// rule or null stops the repetition of the task.
+func parseRepeat(val interface{}) (string, error) {
+	rule, ok := val.(string)
+	if !ok && val != nil {
+		return "", errors.New("repeat must be a recurrence rule string")
+	}
+
+	if rule == "" {
+		return "", nil
+	}
+
+	recurrence, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	return recurrence.String(), nil
+}
+
+// nextOccurrence creates the next occurrence of a repeating task after it has been
+// completed. The new task copies the title, details, priority, estimate, checklist,
+// parent, tags, and custom field values of the completed task and its deadline is
+// shifted to the next occurrence of the recurrence rule (from now if the task has no
+// deadline). If the task was deferred, the next occurrence starts the same amount of
+// time before its deadline. The completed task no longer repeats, so that reopening and
+// completing it again does not create duplicates. If the task does not repeat or the
+// rule has no more occurrences, nil is returned.
+func nextOccurrence(db *gorm.DB, taskID uint) (next *Task, err error) {
+	var task Task
+	if err = db.Preload("Tags").Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, err
+	}
+
+	if task.Repeat == "" {
+		return nil, nil
+	}
+
+	var rule *Recurrence
+	if rule, err = ParseRecurrence(task.Repeat); err != nil {
+		return nil, err
+	}
+
+	base := time.Now()
+	if task.Deadline != nil {
+		base = *task.Deadline
+	}
+
+	// The completed task no longer repeats, the next occurrence carries the rule
+	if err = db.Model(&task).Update("repeat", "").Error; err != nil {
+		return nil, err
+	}
+
+	deadline, ok := rule.Next(base)
+	if !ok {
+		return nil, nil
+	}
+
+	next = &Task{
+		UserID:      task.UserID,
+		WorkspaceID: task.WorkspaceID,
+		AssigneeID:  task.AssigneeID,
+		Title:       task.Title,
+		Details:     task.Details,
+		Priority:    task.Priority,
+		Estimate:    task.Estimate,
+		ChecklistID: task.ChecklistID,
+		ParentID:    task.ParentID,
+		Deadline:    &deadline,
+		Repeat:      rule.Advance().String(),
+	}
+
+	if task.StartAt != nil {
+		startAt := deadline.Add(-base.Sub(*task.StartAt))
+		next.StartAt = &startAt
+	}
+
+	if err = db.Create(next).Error; err != nil {
+		return nil, err
+	}
+
+	if len(task.Tags) > 0 {
+		if err = db.Model(next).Association("Tags").Replace(task.Tags).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err = copyFieldValues(db, task.ID, next.ID); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
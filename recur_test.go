@@ -0,0 +1,131 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	valid := map[string]string{
+		"daily": "FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR": "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+		"freq=monthly;byday=-1fr;count=3":          "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+		"FREQ=DAILY;INTERVAL=1;UNTIL=20201231":     "FREQ=DAILY;UNTIL=20201231T235959Z",
+	}
+
+	for in, expected := range valid {
+		rule, err := ParseRecurrence(in)
+		require.NoError(t, err, "could not parse %q", in)
+		require.Equal(t, expected, rule.String())
+	}
+
+	invalid := []string{
+		"",
+		"yearly",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20201231",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=DAILY;BYHOUR=9",
+		"INTERVAL=2",
+	}
+
+	for _, in := range invalid {
+		_, err := ParseRecurrence(in)
+		require.Error(t, err, "expected %q to be invalid", in)
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// Monday, January 6, 2020 at 9am
+	base := time.Date(2020, time.January, 6, 9, 0, 0, 0, time.UTC)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2020, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule     string
+		base     time.Time
+		expected []time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=3", base, []time.Time{date(1, 9), date(1, 12), date(1, 15)}},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", date(1, 9), []time.Time{date(1, 10), date(1, 13), date(1, 14)}},
+		{"FREQ=WEEKLY", base, []time.Time{date(1, 13), date(1, 20)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", base, []time.Time{date(1, 10), date(1, 20), date(1, 24)}},
+		{"FREQ=MONTHLY", date(1, 31), []time.Time{date(3, 31), date(5, 31)}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", base, []time.Time{date(1, 31), date(2, 28), date(3, 27)}},
+		{"FREQ=MONTHLY;BYDAY=1MO", base, []time.Time{date(2, 3), date(3, 2)}},
+	}
+
+	for _, tc := range tests {
+		rule, err := ParseRecurrence(tc.rule)
+		require.NoError(t, err)
+
+		current := tc.base
+		for _, expected := range tc.expected {
+			next, ok := rule.Next(current)
+			require.True(t, ok, "no next occurrence for %q", tc.rule)
+			require.Equal(t, expected, next, "unexpected occurrence for %q", tc.rule)
+			current = next
+		}
+	}
+
+	// Count limits the number of occurrences
+	rule, err := ParseRecurrence("FREQ=DAILY;COUNT=2")
+	require.NoError(t, err)
+	_, ok := rule.Next(base)
+	require.True(t, ok)
+	_, ok = rule.Advance().Next(base)
+	require.False(t, ok)
+
+	// Until limits the occurrences
+	rule, err = ParseRecurrence("FREQ=WEEKLY;UNTIL=20200115")
+	require.NoError(t, err)
+	next, ok := rule.Next(base)
+	require.True(t, ok)
+	_, ok = rule.Next(next)
+	require.False(t, ok)
+}
+
+func (s *TodosTestSuite) TestRecurringTasks() {
+	deadline := time.Date(2020, time.January, 6, 9, 0, 0, 0, time.UTC)
+	task := Task{Title: "weekly report", Deadline: &deadline, Repeat: "FREQ=WEEKLY;COUNT=2", Tags: []Tag{{Name: "reports"}}}
+
+	var created CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &created))
+
+	// Invalid rules are rejected
+	bad := Task{Title: "bad", Repeat: "FREQ=HOURLY"}
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/tasks", bad, nil))
+
+	// Completing the task creates the next occurrence
+	var rep UpdateTaskResponse
+	code := s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", created.TaskID), map[string]interface{}{"completed": true}, &rep)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.NotZero(s.T(), rep.NextTaskID)
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", rep.NextTaskID), nil, &detail))
+	require.Equal(s.T(), "weekly report", detail.Task.Title)
+	require.False(s.T(), detail.Task.Completed)
+	require.True(s.T(), deadline.AddDate(0, 0, 7).Equal(*detail.Task.Deadline))
+	require.Equal(s.T(), "FREQ=WEEKLY;COUNT=1", detail.Task.Repeat)
+	require.Len(s.T(), detail.Task.Tags, 1)
+
+	// The original task no longer repeats
+	var original DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", created.TaskID), nil, &original))
+	require.True(s.T(), original.Task.Completed)
+	require.Empty(s.T(), original.Task.Repeat)
+
+	// Completing the last occurrence does not create another task
+	var last UpdateTaskResponse
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", rep.NextTaskID), map[string]interface{}{"completed": true}, &last)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.Zero(s.T(), last.NextTaskID)
+}
@@ -0,0 +1,272 @@
+package todos
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+//===========================================================================
+// Viewset for SavedView objects
+//===========================================================================
+
+// ListViews returns all of the saved views of the authenticated user ordered by name,
+// along with the number of tasks in the active workspace that match each view.
+func (s *API) ListViews(c *gin.Context) {
+	var views []SavedView
+	user := c.Value(ctxUserKey).(User)
+
+	if err := s.db.Where("user_id = ?", user.ID).Order("name").Find(&views).Error; err != nil {
+		logger.Printf("could not fetch saved views: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := countViews(s.db, user.ID, activeWorkspace(c), views, time.Now()); err != nil {
+		logger.Printf("could not count saved view tasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListViewsResponse{Success: true, Views: views})
+}
+
+// CreateView saves a named filter and sort of the task list for the authenticated user.
+// The filter is validated when the view is saved so that it can always be evaluated;
+// view names must be unique for the user, otherwise a conflict is returned.
+func (s *API) CreateView(c *gin.Context) {
+	// Parse the user input
+	view := SavedView{}
+	if err := c.ShouldBind(&view); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// Add the user to the view and validate it
+	user := c.Value(ctxUserKey).(User)
+	view.ID = 0
+	view.UserID = user.ID
+
+	if err := view.Validate(s.db); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// Ensure the view does not already exist
+	var count int
+	if err := s.db.Model(&SavedView{}).Where("user_id = ? AND name = ?", user.ID, view.Name).Count(&count).Error; err != nil {
+		logger.Printf("could not count saved views: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse(fmt.Errorf("view %q already exists", view.Name)))
+		return
+	}
+
+	// Create the view in the database
+	if err := s.db.Create(&view).Error; err != nil {
+		logger.Printf("could not create saved view: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateViewResponse{Success: true, ViewID: view.ID})
+}
+
+// DetailView returns the saved view if it belongs to the authenticated user, along with
+// the number of tasks in the active workspace that match it.
+func (s *API) DetailView(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	view, ok := s.userView(c, user)
+	if !ok {
+		return
+	}
+
+	views := []SavedView{view}
+	if err := countViews(s.db, user.ID, activeWorkspace(c), views, time.Now()); err != nil {
+		logger.Printf("could not count saved view tasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DetailViewResponse{Success: true, View: views[0]})
+}
+
+// UpdateView replaces the name and the filter of the saved view, ensuring the new name
+// does not collide with another view of the user.
+func (s *API) UpdateView(c *gin.Context) {
+	// Fetch the view to update
+	user := c.Value(ctxUserKey).(User)
+	view, ok := s.userView(c, user)
+	if !ok {
+		return
+	}
+
+	// Parse the user input
+	var input SavedView
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	input.UserID = user.ID
+	if err := input.Validate(s.db); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	var count int
+	if err := s.db.Model(&SavedView{}).Where("user_id = ? AND name = ? AND id <> ?", user.ID, input.Name, view.ID).Count(&count).Error; err != nil {
+		logger.Printf("could not count saved views: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse(fmt.Errorf("view %q already exists", input.Name)))
+		return
+	}
+
+	view.Name = input.Name
+	view.Filter = input.Filter
+	if err := s.db.Save(&view).Error; err != nil {
+		logger.Printf("could not update saved view: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateViewResponse{Success: true})
+}
+
+// DeleteView deletes the saved view, the tasks that match the view are not modified.
+func (s *API) DeleteView(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	view, ok := s.userView(c, user)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Delete(&view).Error; err != nil {
+		logger.Printf("could not delete saved view: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteViewResponse{Success: true})
+}
+
+// EvaluateView returns the tasks that match the saved view exactly as they would be
+// returned by the task list with the filter and sort of the view. Only the page, number
+// of tasks per page, and cursor can be specified in the query.
+func (s *API) EvaluateView(c *gin.Context) {
+	var paging ListTasksRequest
+	if err := c.ShouldBindQuery(&paging); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	user := c.Value(ctxUserKey).(User)
+	view, ok := s.userView(c, user)
+	if !ok {
+		return
+	}
+
+	req := ListTasksRequest(view.Filter)
+	req.Page, req.PerPage, req.Cursor = paging.Page, paging.PerPage, paging.Cursor
+	s.listTasks(c, req)
+}
+
+//===========================================================================
+// SavedView Helpers
+//===========================================================================
+
+var errViewName = errors.New("view name must be between 1 and 255 characters")
+
+// ViewFilter is the filter and sort of the task list that a saved view is evaluated
+// with, stored in the database as a JSON encoded text column.
+type ViewFilter ListTasksRequest
+
+// Scan implements sql.Scanner to decode the filter from JSON.
+func (f *ViewFilter) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = ViewFilter{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case []byte:
+		return json.Unmarshal(v, f)
+	default:
+		return fmt.Errorf("cannot scan view filter from %T", src)
+	}
+}
+
+// Value implements driver.Valuer to encode the filter as JSON.
+func (f ViewFilter) Value() (driver.Value, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Validate the name and filter of the view, normalizing the view in place. Views are
+// paginated when they are evaluated, so the pagination of the filter is not saved. The
+// filter is applied to a query that is never executed to ensure it can be evaluated.
+func (v *SavedView) Validate(db *gorm.DB) (err error) {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" || len(v.Name) > 255 {
+		return errViewName
+	}
+
+	v.Filter.Page, v.Filter.PerPage, v.Filter.Cursor = 0, 0, ""
+	req := ListTasksRequest(v.Filter)
+	if _, err = parseTaskOrder(req.Sort, req.Checklist > 0); err != nil {
+		return err
+	}
+
+	if _, err = filterTaskList(db, db.Model(&Task{}), v.UserID, req, time.Now()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// userView fetches the saved view in the URL if it belongs to the user, otherwise the
+// error response is written and ok is false.
+func (s *API) userView(c *gin.Context, user User) (view SavedView, ok bool) {
+	if err := s.db.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&view).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return view, false
+		}
+		logger.Printf("could not find saved view: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return view, false
+	}
+	return view, true
+}
+
+// countViews sets the count of each view to the number of tasks visible to the user in
+// the workspace that match the filter of the view.
+func countViews(db *gorm.DB, userID uint, workspace *uint, views []SavedView, now time.Time) (err error) {
+	for i := range views {
+		var query *gorm.DB
+		if query, err = filterTaskList(db, visibleTasks(db.Model(&Task{}), userID, workspace), userID, ListTasksRequest(views[i].Filter), now); err != nil {
+			return err
+		}
+
+		if err = query.Count(&views[i].Count).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestSavedViews() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "consulting"}, &list))
+
+	yesterday, tomorrow := time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour)
+	tasks := []Task{
+		{Title: "invoice client", Deadline: &yesterday, Tags: []Tag{{Name: "client-work"}}},
+		{Title: "draft proposal", Deadline: &tomorrow, Tags: []Tag{{Name: "client-work"}}},
+		{Title: "water plants", Deadline: &yesterday},
+		{Title: "send contract", Deadline: &yesterday, Tags: []Tag{{Name: "client-work"}}},
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		task.ChecklistID = &list.ChecklistID
+
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids = append(ids, rep.TaskID)
+	}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[3]), map[string]interface{}{"completed": true}, nil))
+
+	// Save a view of the overdue work tasks, the pagination of the filter is not saved
+	view := map[string]interface{}{
+		"name":   "Overdue work tasks",
+		"filter": map[string]interface{}{"tags": []string{"client-work"}, "overdue": true, "page": 3},
+	}
+
+	var created CreateViewResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/views", view, &created))
+	require.NotZero(s.T(), created.ViewID)
+	path := fmt.Sprintf("/v1/views/%d", created.ViewID)
+
+	// View names are unique and the filters of views are validated when saved
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPost, "/v1/views", view, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/views", map[string]interface{}{"name": "  "}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/views", map[string]interface{}{"name": "colors", "filter": map[string]interface{}{"sort": []string{"color"}}}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/views", map[string]interface{}{"name": "fields", "filter": map[string]interface{}{"fields": []string{"size"}}}, nil))
+
+	// Views are listed with the number of tasks that match them, as is the overview
+	var views ListViewsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/views", nil, &views))
+	require.Len(s.T(), views.Views, 1)
+	require.Equal(s.T(), "Overdue work tasks", views.Views[0].Name)
+	require.Equal(s.T(), 1, views.Views[0].Count)
+
+	var overview OverviewResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/", nil, &overview))
+	require.Equal(s.T(), map[string]int{"Overdue work tasks": 1}, overview.Views)
+
+	var detail DetailViewResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), []string{"client-work"}, detail.View.Filter.Tags)
+	require.True(s.T(), detail.View.Filter.Overdue)
+	require.Zero(s.T(), detail.View.Filter.Page)
+	require.Equal(s.T(), 1, detail.View.Count)
+
+	// Evaluating the view returns its tasks like the task list
+	var rep ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"/tasks", nil, &rep))
+	require.Equal(s.T(), []uint{ids[0]}, taskIDs(rep.Tasks))
+
+	// Update the view to the open work tasks sorted by title and page through them
+	view = map[string]interface{}{
+		"name":   "Open work tasks",
+		"filter": map[string]interface{}{"tags": []string{"client-work"}, "completed": false, "sort": []string{"-title"}},
+	}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, path, view, nil))
+
+	paged := make([]uint, 0, 2)
+	cursor := ""
+	for {
+		rep = ListTasksResponse{}
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path+"/tasks?per_page=1&cursor="+cursor, nil, &rep))
+		paged = append(paged, taskIDs(rep.Tasks)...)
+		if rep.NextCursor == "" {
+			break
+		}
+		cursor = rep.NextCursor
+	}
+	require.Equal(s.T(), []uint{ids[0], ids[1]}, paged)
+
+	// Views cannot be renamed to the name of another view
+	var other CreateViewResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/views", map[string]interface{}{"name": "Everything"}, &other))
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPut, path, map[string]interface{}{"name": "Everything"}, nil))
+
+	// Other users cannot see or evaluate the view
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, path+"/tasks", nil, nil))
+
+	// Deleted views are not found
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, path+"/tasks", nil, nil))
+
+	// Clean up so that other tests are not affected by the views, tasks, and tags
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/views/%d", other.ViewID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+
+	var tags ListTagsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tags", nil, &tags))
+	for _, tag := range tags.Tags {
+		if tag.Name == "client-work" {
+			require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tags/%d", tag.ID), nil, nil))
+		}
+	}
+}
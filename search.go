@@ -0,0 +1,386 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Search result types, by default both tasks and checklists are searched.
+const (
+	SearchTask      = "task"
+	SearchChecklist = "checklist"
+)
+
+// Highlight markers that surround the matching words of search snippets. Snippets are
+// HTML: the text of the snippet is escaped before the markers are added.
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// DefaultSearchLimit is the number of results returned if no limit is requested.
+const DefaultSearchLimit = 20
+
+// Full-text search backends: Postgres uses tsvector expressions with GIN indices, SQLite
+// uses FTS5 tables if the module is compiled in (build with the sqlite_fts5 tag),
+// otherwise matching rows are scanned and ranked by the server.
+const (
+	searchPostgres = "postgres"
+	searchFTS5     = "fts5"
+	searchScan     = "scan"
+)
+
+const (
+	snippetWords   = 16   // maximum number of words in a search snippet
+	snippetContext = 4    // number of words before the first match in a snippet
+	maxSearchScan  = 1000 // maximum number of rows ranked when scanning for matches
+	ellipsis       = "…"
+)
+
+// Placeholder markers that the database surrounds matching words with so that the
+// snippets can be escaped before the highlight markers are added.
+const (
+	snippetStart = "\x02"
+	snippetStop  = "\x03"
+)
+
+var (
+	errSearchQuery = errors.New("search query must contain at least one word")
+	errSearchType  = errors.New("search type must be task or checklist")
+)
+
+// searchTables describes the tables that can be searched by result type.
+var searchTables = map[string]searchTable{
+	SearchTask:      {name: "tasks", checklist: "tasks.checklist_id"},
+	SearchChecklist: {name: "checklists", checklist: "NULL"},
+}
+
+// searchTable is a table whose title and details can be searched. The checklist is the
+// column selected as the checklist of the result.
+type searchTable struct {
+	name      string
+	checklist string
+}
+
+// searchRow is a single ranked match fetched from a search table.
+type searchRow struct {
+	ID          uint
+	Title       string
+	Details     string
+	ChecklistID *uint
+	Score       float64
+	Snippet     string
+}
+
+// Search finds the tasks and checklists in the active workspace that the user can see
+// whose title or details contain the words of the query. Results from both tables are
+// ranked together by relevance, titles are weighted more heavily than details, and each
+// result contains a snippet with the matching words highlighted.
+func (s *API) Search(c *gin.Context) {
+	var req SearchRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	terms := searchTerms(req.Query)
+	if len(terms) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errSearchQuery))
+		return
+	}
+
+	types := []string{SearchTask, SearchChecklist}
+	if req.Type != "" {
+		if _, ok := searchTables[req.Type]; !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse(errSearchType))
+			return
+		}
+		types = []string{req.Type}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	user := c.Value(ctxUserKey).(User)
+	workspace := activeWorkspace(c)
+	backend := searchBackend(s.db)
+
+	results := make([]SearchResult, 0, limit)
+	for _, kind := range types {
+		var query *gorm.DB
+		switch kind {
+		case SearchTask:
+			query = visibleTasks(s.db, user.ID, workspace)
+		case SearchChecklist:
+			query = visibleChecklists(s.db, user.ID, workspace)
+		}
+
+		rows, err := searchTables[kind].search(query, backend, terms, limit)
+		if err != nil {
+			logger.Printf("could not search %s: %s", searchTables[kind].name, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+
+		for _, row := range rows {
+			results = append(results, SearchResult{
+				Type:        kind,
+				ID:          row.ID,
+				Title:       row.Title,
+				ChecklistID: row.ChecklistID,
+				Rank:        row.Score,
+				Snippet:     row.Snippet,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{Success: true, Results: results})
+}
+
+// search returns the best matching rows of the table for the terms using the backend.
+func (t searchTable) search(query *gorm.DB, backend string, terms []string, limit int) (rows []searchRow, err error) {
+	query = query.Table(t.name).Where(t.name + ".deleted_at IS NULL")
+
+	switch backend {
+	case searchPostgres:
+		text := strings.Join(terms, " ")
+		vector := searchVector(t.name + ".")
+		err = query.
+			Select(
+				fmt.Sprintf("%[1]s.id, %[1]s.title, %[2]s AS checklist_id, ts_rank(%[3]s, plainto_tsquery('english', ?)) AS score, ts_headline('english', %[1]s.title || ' ' || COALESCE(%[1]s.details, ''), plainto_tsquery('english', ?), ?) AS snippet", t.name, t.checklist, vector),
+				text, text, fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d", snippetStart, snippetStop, snippetWords, snippetContext*2),
+			).
+			Where(vector+" @@ plainto_tsquery('english', ?)", text).
+			Order("score DESC").Order(t.name + ".id").Limit(limit).
+			Scan(&rows).Error
+		return markSnippets(rows), err
+
+	case searchFTS5:
+		fts := t.name + "_fts"
+		err = query.
+			Select(
+				fmt.Sprintf("%[1]s.id, %[1]s.title, %[2]s AS checklist_id, -bm25(%[3]s, 10.0, 1.0) AS score, snippet(%[3]s, -1, ?, ?, ?, %[4]d) AS snippet", t.name, t.checklist, fts, snippetWords),
+				snippetStart, snippetStop, ellipsis,
+			).
+			Joins(fmt.Sprintf("JOIN %[2]s ON %[2]s.rowid = %[1]s.id", t.name, fts)).
+			Where(fts+" MATCH ?", ftsQuery(terms)).
+			Order("score DESC").Order(t.name + ".id").Limit(limit).
+			Scan(&rows).Error
+		return markSnippets(rows), err
+
+	default:
+		for _, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			query = query.Where(fmt.Sprintf(`LOWER(%[1]s.title) LIKE ? ESCAPE '\' OR LOWER(%[1]s.details) LIKE ? ESCAPE '\'`, t.name), pattern, pattern)
+		}
+
+		if err = query.Select(fmt.Sprintf("%[1]s.id, %[1]s.title, %[1]s.details, %[2]s AS checklist_id", t.name, t.checklist)).
+			Order(t.name + ".id DESC").Limit(maxSearchScan).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range rows {
+			rows[i].Score = scoreMatch(rows[i], terms)
+			rows[i].Snippet = highlight(rows[i].Title+" "+rows[i].Details, terms)
+		}
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Score > rows[j].Score
+		})
+
+		if len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return rows, nil
+	}
+}
+
+//===========================================================================
+// Search Helpers
+//===========================================================================
+
+// searchBackend returns the full-text search backend supported by the database.
+func searchBackend(db *gorm.DB) string {
+	switch db.Dialect().GetName() {
+	case "postgres":
+		return searchPostgres
+	case "sqlite3":
+		if db.Dialect().HasTable("tasks_fts") {
+			return searchFTS5
+		}
+	}
+	return searchScan
+}
+
+// searchVector is the weighted tsvector of the title and details of a row, prefixed by
+// the table name in queries. The GIN indices are created on the same expression.
+func searchVector(prefix string) string {
+	return fmt.Sprintf("(setweight(to_tsvector('english', %[1]stitle), 'A') || setweight(to_tsvector('english', COALESCE(%[1]sdetails, '')), 'B'))", prefix)
+}
+
+// migrateSearch creates the full-text search indices of the tasks and checklists. In
+// Postgres GIN indices are created on the search vector. In SQLite external content FTS5
+// tables are created and kept up to date with triggers; if the FTS5 module is not
+// available, search falls back to scanning the tables.
+func migrateSearch(db *gorm.DB) (err error) {
+	switch db.Dialect().GetName() {
+	case "postgres":
+		for _, table := range []string{"tasks", "checklists"} {
+			if err = db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%[1]s_search ON %[1]s USING GIN (%[2]s)", table, searchVector(""))).Error; err != nil {
+				return err
+			}
+		}
+
+	case "sqlite3":
+		for _, table := range []string{"tasks", "checklists"} {
+			fts := table + "_fts"
+			if db.Dialect().HasTable(fts) {
+				continue
+			}
+
+			if err = db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE %[2]s USING fts5(title, details, content='%[1]s', content_rowid='id', tokenize='porter unicode61')", table, fts)).Error; err != nil {
+				if strings.Contains(err.Error(), "no such module") {
+					return nil
+				}
+				return err
+			}
+
+			statements := []string{
+				"CREATE TRIGGER IF NOT EXISTS %[2]s_insert AFTER INSERT ON %[1]s BEGIN INSERT INTO %[2]s (rowid, title, details) VALUES (new.id, new.title, new.details); END",
+				"CREATE TRIGGER IF NOT EXISTS %[2]s_delete AFTER DELETE ON %[1]s BEGIN INSERT INTO %[2]s (%[2]s, rowid, title, details) VALUES ('delete', old.id, old.title, old.details); END",
+				"CREATE TRIGGER IF NOT EXISTS %[2]s_update AFTER UPDATE OF title, details ON %[1]s BEGIN INSERT INTO %[2]s (%[2]s, rowid, title, details) VALUES ('delete', old.id, old.title, old.details); INSERT INTO %[2]s (rowid, title, details) VALUES (new.id, new.title, new.details); END",
+				"INSERT INTO %[2]s (%[2]s) VALUES ('rebuild')",
+			}
+
+			for _, stmt := range statements {
+				if err = db.Exec(fmt.Sprintf(stmt, table, fts)).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// searchTerms splits the query into lower case words, ignoring punctuation and
+// duplicate words, so that the query can be safely passed to any backend.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	seen := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		if _, ok := seen[word]; !ok {
+			seen[word] = struct{}{}
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// ftsQuery returns an FTS5 query that matches rows containing all of the terms.
+func ftsQuery(terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+term+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// scoreMatch ranks a scanned row by the number of times each term occurs, where matches
+// in the title are weighted more heavily than matches in the details.
+func scoreMatch(row searchRow, terms []string) (score float64) {
+	title, details := strings.ToLower(row.Title), strings.ToLower(row.Details)
+	for _, term := range terms {
+		score += 3*float64(strings.Count(title, term)) + float64(strings.Count(details, term))
+	}
+	return score
+}
+
+// markSnippets escapes the snippets selected by the database and replaces the
+// placeholder markers around their matching words with the highlight markers.
+func markSnippets(rows []searchRow) []searchRow {
+	marker := strings.NewReplacer(snippetStart, HighlightStart, snippetStop, HighlightStop)
+	for i := range rows {
+		rows[i].Snippet = marker.Replace(html.EscapeString(rows[i].Snippet))
+	}
+	return rows
+}
+
+// highlight returns an HTML excerpt of the text around the first word that contains one
+// of the terms, with each escaped matching word surrounded by the highlight markers.
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+	matches := make([]bool, len(words))
+	first := -1
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				matches[i] = true
+				break
+			}
+		}
+
+		if matches[i] && first < 0 {
+			first = i
+		}
+	}
+
+	if first < 0 {
+		return ""
+	}
+
+	start := first - snippetContext
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	excerpt := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		if matches[i] {
+			excerpt = append(excerpt, HighlightStart+html.EscapeString(words[i])+HighlightStop)
+		} else {
+			excerpt = append(excerpt, html.EscapeString(words[i]))
+		}
+	}
+
+	snippet := strings.Join(excerpt, " ")
+	if start > 0 {
+		snippet = ellipsis + snippet
+	}
+
+	if end < len(words) {
+		snippet += ellipsis
+	}
+	return snippet
+}
@@ -0,0 +1,84 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestSearch() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "Vacation planning", Details: "book the lighthouse cottage"}, &list))
+
+	var cottage, ferry, trashed CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "Pay the cottage deposit", ChecklistID: &list.ChecklistID}, &cottage))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "Reserve ferry tickets", Details: "the ferry leaves from the pier next to the cottage at noon"}, &ferry))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "Cancel the cottage", ChecklistID: &list.ChecklistID}, &trashed))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", trashed.TaskID), nil, nil))
+
+	// Results are ranked across tasks and checklists with titles weighted over details
+	var rep SearchResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=cottage", nil, &rep))
+	require.Len(s.T(), rep.Results, 3)
+	require.Equal(s.T(), SearchTask, rep.Results[0].Type)
+	require.Equal(s.T(), cottage.TaskID, rep.Results[0].ID)
+	require.Equal(s.T(), list.ChecklistID, *rep.Results[0].ChecklistID)
+	require.Contains(s.T(), rep.Results[0].Snippet, HighlightStart+"cottage"+HighlightStop)
+
+	types := make(map[string][]uint)
+	for _, result := range rep.Results {
+		types[result.Type] = append(types[result.Type], result.ID)
+	}
+	require.ElementsMatch(s.T(), []uint{cottage.TaskID, ferry.TaskID}, types[SearchTask])
+	require.Equal(s.T(), []uint{list.ChecklistID}, types[SearchChecklist])
+
+	// All of the words must match, ignoring case and punctuation
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=FERRY,+cottage!", nil, &rep))
+	require.Len(s.T(), rep.Results, 1)
+	require.Equal(s.T(), ferry.TaskID, rep.Results[0].ID)
+	require.Contains(s.T(), rep.Results[0].Snippet, HighlightStart+"ferry"+HighlightStop)
+
+	// Snippets are escaped so that titles and details cannot inject markup
+	var markup CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: `Pack <img src=x onerror="alert(1)"> snorkel`}, &markup))
+
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=snorkel", nil, &rep))
+	require.Len(s.T(), rep.Results, 1)
+	require.NotContains(s.T(), rep.Results[0].Snippet, "<img")
+	require.Contains(s.T(), rep.Results[0].Snippet, "&lt;img")
+	require.Contains(s.T(), rep.Results[0].Snippet, HighlightStart+"snorkel"+HighlightStop)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", markup.TaskID), nil, nil))
+
+	// Results can be limited by type and number
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=cottage&type=checklist", nil, &rep))
+	require.Len(s.T(), rep.Results, 1)
+	require.Equal(s.T(), list.ChecklistID, rep.Results[0].ID)
+
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=cottage&limit=1", nil, &rep))
+	require.Len(s.T(), rep.Results, 1)
+
+	// Updated titles are searchable and other users cannot find the tasks
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ferry.TaskID), map[string]interface{}{"title": "Reserve kayak rental"}, nil))
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/search?q=kayak", nil, &rep))
+	require.Len(s.T(), rep.Results, 1)
+
+	rep = SearchResponse{}
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/search?q=kayak", nil, &rep))
+	require.Empty(s.T(), rep.Results)
+
+	// Invalid searches are rejected
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/search", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/search?q=!!!", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodGet, "/v1/search?q=cottage&type=project", nil, nil))
+
+	// Clean up so that other tests are not affected by the tasks
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", ferry.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+}
@@ -8,6 +8,7 @@ import (
 
 const (
 	tokenCleanupSvcInterval = 1 * time.Hour
+	trashCleanupSvcInterval = 1 * time.Hour
 )
 
 // TokensCleanupService is a go routine that runs the TokenCleanup function every hour,
@@ -59,3 +60,55 @@ func TokensCleanup(db *gorm.DB) (rows int, err error) {
 	})
 	return rows, err
 }
+
+// TrashCleanupService is a go routine that runs the TrashCleanup function every hour,
+// logging the results to disk. It is run when it is first called, then every hour.
+func (s *API) TrashCleanupService() {
+	logger.Printf("starting trash cleanup service with %s retention", s.conf.TrashRetention)
+	ticker := time.NewTicker(trashCleanupSvcInterval)
+
+	for {
+		// Execute the trash cleanup command and log the results
+		rows, err := TrashCleanup(s.db, s.storage, s.conf.TrashRetention)
+		if err != nil {
+			logger.Printf("could not clean up trash: %s", err)
+		} else if rows > 0 {
+			logger.Printf("purged %d tasks and checklists from the trash", rows)
+		} else {
+			// TODO: make this a lower log level when log leveling is a thing.
+			logger.Printf("no tasks or checklists to purge from the trash")
+		}
+
+		// Block until the next scheduled service run
+		<-ticker.C
+	}
+}
+
+// TrashCleanup permanently deletes the tasks and checklists that have been in the trash
+// for longer than the retention period along with their comments, time entries, and
+// attachments, whose contents are removed from the storage. It returns the number of
+// tasks and checklists deleted. Note that this function is run inside of a transaction
+// so that items are not partially deleted.
+func TrashCleanup(db *gorm.DB, storage Storage, retention time.Duration) (rows int, err error) {
+	var blobs []string
+	before := time.Now().Add(-retention)
+	err = db.Transaction(func(tx *gorm.DB) (err error) {
+		var tasks, lists int
+		tasks, lists, blobs, err = purgeTrash(tx, func(db *gorm.DB, table string) *gorm.DB {
+			return db.Where(table+".deleted_at < ?", before)
+		})
+		rows = tasks + lists
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range blobs {
+		if err = storage.Delete(key); err != nil {
+			logger.Printf("could not delete attachment %s from storage: %s", key, err)
+		}
+	}
+	return rows, nil
+}
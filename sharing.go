@@ -0,0 +1,357 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// Roles that a user can have on a checklist. Owners can do anything, including sharing
+// and deleting the checklist, editors can modify the checklist and its tasks, and
+// viewers can only read them.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// roleRanks orders the roles so that a role allows everything the lower roles allow.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+var (
+	errForbidden   = errors.New("you do not have permission to modify this checklist")
+	errInvalidRole = errors.New("role must be viewer or editor")
+	errShareOwner  = errors.New("checklists cannot be shared with their owner")
+	errTaskMissing = errors.New("task does not exist")
+)
+
+//===========================================================================
+// Viewset for ChecklistShare objects
+//===========================================================================
+
+// ListShares returns the users the checklist is shared with and their roles.
+func (s *API) ListShares(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	var shares []ChecklistShare
+	if err := s.db.Preload("User").Where("checklist_id = ?", list.ID).Order("created_at").Find(&shares).Error; err != nil {
+		logger.Printf("could not fetch shares: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	for i := range shares {
+		shares[i].Username = shares[i].User.Username
+	}
+
+	c.JSON(http.StatusOK, ListSharesResponse{Success: true, Shares: shares})
+}
+
+// ShareChecklist shares the checklist with another user as a viewer or editor. If the
+// checklist is already shared with the user, their role is changed. Only the owner of
+// the checklist can share it.
+func (s *API) ShareChecklist(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleOwner)
+	if !ok {
+		return
+	}
+
+	var req ShareChecklistRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if req.Role != RoleViewer && req.Role != RoleEditor {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errInvalidRole))
+		return
+	}
+
+	var collaborator User
+	if err := s.db.Where("username = ?", req.Username).First(&collaborator).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusBadRequest, ErrorResponse(fmt.Errorf("user %q does not exist", req.Username)))
+			return
+		}
+		logger.Printf("could not find user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if collaborator.ID == list.UserID {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errShareOwner))
+		return
+	}
+
+	// Checklists in a workspace can only be shared with the members of the workspace
+	if list.WorkspaceID != nil {
+		if err := checkMember(s.db, collaborator, *list.WorkspaceID); err != nil {
+			if err == errNotMember {
+				c.JSON(http.StatusBadRequest, ErrorResponse(errCollaboratorMissing))
+				return
+			}
+			logger.Printf("could not check workspace membership: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+	}
+
+	share := ChecklistShare{ChecklistID: list.ID, UserID: collaborator.ID}
+	if err := s.db.Where(share).Assign(ChecklistShare{Role: req.Role}).FirstOrCreate(&share).Error; err != nil {
+		logger.Printf("could not share checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ShareChecklistResponse{Success: true})
+}
+
+// UnshareChecklist stops sharing the checklist with the user in the URL. The owner can
+// remove any user and collaborators can remove themselves to leave the checklist.
+func (s *API) UnshareChecklist(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	required := RoleOwner
+	if c.Param("username") == user.Username {
+		required = RoleViewer
+	}
+
+	list, _, ok := s.userChecklist(c, user, required)
+	if !ok {
+		return
+	}
+
+	users := s.db.Table("users").Select("id").Where("username = ?", c.Param("username"))
+	query := s.db.Where("checklist_id = ? AND user_id IN ?", list.ID, users.SubQuery()).Delete(&ChecklistShare{})
+	if err := query.Error; err != nil {
+		logger.Printf("could not unshare checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if query.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, UnshareChecklistResponse{Success: true})
+}
+
+//===========================================================================
+// Access Helpers
+//===========================================================================
+
+// userTask fetches the task specified by the id url parameter if it is in the active
+// workspace and the user has at least the required role on it. If the task cannot be
+// found or the user does not have access to it, the error response is written and
+// false is returned.
+func (s *API) userTask(c *gin.Context, user User, required string) (task Task, ok bool) {
+	query := inWorkspace(s.db, "tasks", activeWorkspace(c))
+	if err := query.Where("id = ?", c.Param("id")).First(&task).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return task, false
+		}
+		logger.Printf("could not find task: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return task, false
+	}
+
+	role, err := taskRole(s.db, user.ID, task)
+	if err != nil {
+		logger.Printf("could not check task role: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return task, false
+	}
+	return task, checkRole(c, role, required)
+}
+
+// userChecklist fetches the checklist specified by the id url parameter if it is in the
+// active workspace and the user has at least the required role on it and returns the
+// role of the user. If the checklist cannot be found or the user does not have access
+// to it, the error response is written and false is returned.
+func (s *API) userChecklist(c *gin.Context, user User, required string) (list Checklist, role string, ok bool) {
+	query := inWorkspace(s.db, "checklists", activeWorkspace(c))
+	if err := query.Where("id = ?", c.Param("id")).First(&list).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return list, "", false
+		}
+		logger.Printf("could not find checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return list, "", false
+	}
+
+	var err error
+	if role, err = checklistRole(s.db, user.ID, list); err != nil {
+		logger.Printf("could not check checklist role: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return list, "", false
+	}
+	return list, role, checkRole(c, role, required)
+}
+
+// checkRole writes a not found response if the user has no role, since objects that are
+// not shared with the user should not be revealed, or a forbidden response if the role
+// is not sufficient and returns false. If the role is sufficient, true is returned.
+func checkRole(c *gin.Context, role, required string) bool {
+	if role == "" {
+		c.JSON(http.StatusNotFound, notFound)
+		return false
+	}
+
+	if !hasRole(role, required) {
+		c.JSON(http.StatusForbidden, ErrorResponse(errForbidden))
+		return false
+	}
+	return true
+}
+
+// hasRole returns true if the role allows everything the required role allows.
+func hasRole(role, required string) bool {
+	return role != "" && roleRanks[role] >= roleRanks[required]
+}
+
+// checklistRole returns the role of the user on the checklist or an empty string if the
+// checklist is not shared with the user.
+func checklistRole(db *gorm.DB, userID uint, list Checklist) (_ string, err error) {
+	if list.UserID == userID {
+		return RoleOwner, nil
+	}
+
+	var share ChecklistShare
+	if err = db.Where("checklist_id = ? AND user_id = ?", list.ID, userID).First(&share).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return share.Role, nil
+}
+
+// taskRole returns the role of the user on the task: owners of the task are owners and
+// otherwise users have the role they were given on the checklist of the task.
+func taskRole(db *gorm.DB, userID uint, task Task) (_ string, err error) {
+	if task.UserID == userID {
+		return RoleOwner, nil
+	}
+
+	if task.ChecklistID == nil {
+		return "", nil
+	}
+
+	var list Checklist
+	if err = db.Select("id, user_id").Where("id = ?", *task.ChecklistID).First(&list).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return checklistRole(db, userID, list)
+}
+
+// requireChecklistRole ensures the user has at least the required role on the checklist
+// for use in transactions; the checklist must exist in the workspace and be accessible
+// to the user.
+func requireChecklistRole(db *gorm.DB, userID uint, workspace *uint, checklistID uint, required string) (list Checklist, err error) {
+	if err = inWorkspace(db, "checklists", workspace).Where("id = ?", checklistID).First(&list).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return list, errChecklistMissing
+		}
+		return list, err
+	}
+
+	var role string
+	if role, err = checklistRole(db, userID, list); err != nil {
+		return list, err
+	}
+
+	switch {
+	case role == "":
+		return list, errChecklistMissing
+	case !hasRole(role, required):
+		return list, errForbidden
+	}
+	return list, nil
+}
+
+// requireTaskRole ensures the user has at least the required role on the task for use
+// in transactions; the task must exist in the workspace and be accessible to the user.
+func requireTaskRole(db *gorm.DB, userID uint, workspace *uint, taskID uint, required string) (task Task, err error) {
+	if err = inWorkspace(db, "tasks", workspace).Where("id = ?", taskID).First(&task).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return task, errTaskMissing
+		}
+		return task, err
+	}
+
+	var role string
+	if role, err = taskRole(db, userID, task); err != nil {
+		return task, err
+	}
+
+	switch {
+	case role == "":
+		return task, errTaskMissing
+	case !hasRole(role, required):
+		return task, errForbidden
+	}
+	return task, nil
+}
+
+// sharedChecklists returns a subquery of the ids of the checklists shared with the user.
+func sharedChecklists(db *gorm.DB, userID uint) interface{} {
+	return db.Table("checklist_shares").Select("checklist_id").Where("user_id = ?", userID).SubQuery()
+}
+
+// editableChecklists returns a subquery of the ids of the checklists shared with the
+// user as an editor.
+func editableChecklists(db *gorm.DB, userID uint) interface{} {
+	return db.Table("checklist_shares").Select("checklist_id").Where("user_id = ? AND role = ?", userID, RoleEditor).SubQuery()
+}
+
+// visibleTasks filters the query to the tasks in the workspace that the user owns or
+// that are in checklists shared with the user.
+func visibleTasks(db *gorm.DB, userID uint, workspace *uint) *gorm.DB {
+	return inWorkspace(db, "tasks", workspace).Where("tasks.user_id = ? OR tasks.checklist_id IN ?", userID, sharedChecklists(db, userID))
+}
+
+// visibleChecklists filters the query to the checklists in the workspace that the user
+// owns or that are shared with the user.
+func visibleChecklists(db *gorm.DB, userID uint, workspace *uint) *gorm.DB {
+	return inWorkspace(db, "checklists", workspace).Where("checklists.user_id = ? OR checklists.id IN ?", userID, sharedChecklists(db, userID))
+}
+
+// checklistRoles sets the role of the user on each of the checklists, fetching the
+// roles of the shared checklists in a single query.
+func checklistRoles(db *gorm.DB, userID uint, lists []Checklist) (err error) {
+	var shares []ChecklistShare
+	if err = db.Where("user_id = ?", userID).Find(&shares).Error; err != nil {
+		return err
+	}
+
+	roles := make(map[uint]string, len(shares))
+	for _, share := range shares {
+		roles[share.ChecklistID] = share.Role
+	}
+
+	for i := range lists {
+		if lists[i].UserID == userID {
+			lists[i].Role = RoleOwner
+		} else {
+			lists[i].Role = roles[lists[i].ID]
+		}
+	}
+	return nil
+}
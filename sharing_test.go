@@ -0,0 +1,94 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestSharing() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "household"}, &list))
+
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "groceries", ChecklistID: &list.ChecklistID}, &task))
+
+	listPath := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+	taskPath := fmt.Sprintf("/v1/tasks/%d", task.TaskID)
+	sharesPath := listPath + "/shares"
+
+	// The checklist is not visible to other users until it is shared with them
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, listPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, taskPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodPut, sharesPath, ShareChecklistRequest{Username: "jane", Role: RoleEditor}, nil))
+
+	// Only viewers and editors can be shared with and the owner cannot be shared with
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "admin", Role: RoleOwner}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "jane", Role: RoleViewer}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "nobody", Role: RoleViewer}, nil))
+
+	// Viewers can read the checklist and its tasks but cannot modify them
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "admin", Role: RoleViewer}, nil))
+
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, listPath, nil, &detail))
+	require.Equal(s.T(), RoleViewer, detail.Checklist.Role)
+	require.Len(s.T(), detail.Checklist.Tasks, 1)
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, taskPath, nil, nil))
+
+	var lists ListChecklistsResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/lists", nil, &lists))
+	require.Len(s.T(), lists.Checklists, 1)
+	require.Equal(s.T(), RoleViewer, lists.Checklists[0].Role)
+
+	var tasks ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/tasks", nil, &tasks))
+	require.Equal(s.T(), []uint{task.TaskID}, taskIDs(tasks.Tasks))
+
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodPut, taskPath, map[string]interface{}{"completed": true}, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodPost, "/v1/tasks", Task{Title: "chores", ChecklistID: &list.ChecklistID}, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodDelete, taskPath, nil, nil))
+
+	// Editors can create and modify tasks, which belong to the owner of the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, sharesPath, ShareChecklistRequest{Username: "admin", Role: RoleEditor}, nil))
+
+	var shares ListSharesResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, sharesPath, nil, &shares))
+	require.Len(s.T(), shares.Shares, 1)
+	require.Equal(s.T(), "admin", shares.Shares[0].Username)
+	require.Equal(s.T(), RoleEditor, shares.Shares[0].Role)
+
+	var created CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.DoAs(true, http.MethodPost, "/v1/tasks", Task{Title: "chores", ChecklistID: &list.ChecklistID}, &created))
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodPut, taskPath, map[string]interface{}{"completed": true}, nil))
+
+	detail = DetailChecklistResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, listPath, nil, &detail))
+	require.Equal(s.T(), RoleOwner, detail.Checklist.Role)
+	require.Equal(s.T(), []uint{task.TaskID, created.TaskID}, taskIDs(detail.Checklist.Tasks))
+	require.Equal(s.T(), uint(1), detail.Checklist.Completed)
+
+	// Editors can move their own tasks into the checklist
+	var moved CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.DoAs(true, http.MethodPost, "/v1/tasks", Task{Title: "laundry"}, &moved))
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodPut, fmt.Sprintf("/v1/tasks/%d", moved.TaskID), map[string]interface{}{"checklist": list.ChecklistID}, nil))
+
+	detail = DetailChecklistResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, listPath, nil, &detail))
+	require.Equal(s.T(), []uint{task.TaskID, created.TaskID, moved.TaskID}, taskIDs(detail.Checklist.Tasks))
+
+	// Only the owner can share or delete the checklist
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodPut, sharesPath, ShareChecklistRequest{Username: "admin", Role: RoleViewer}, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodDelete, listPath, nil, nil))
+
+	// Collaborators can leave the checklist, after which it is no longer visible
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodDelete, sharesPath+"/admin", nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodDelete, sharesPath+"/admin", nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, listPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, taskPath, nil, nil))
+
+	// Clean up so that other tests are not affected by the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, listPath, nil, nil))
+}
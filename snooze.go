@@ -0,0 +1,98 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var errSnoozeDuration = errors.New("snooze duration must be positive")
+
+// SnoozeTask defers the task by pushing its start date back by the requested duration.
+// If the task has not started yet, the duration is added to its current start date,
+// otherwise the task is hidden from now until the duration has passed.
+func (s *API) SnoozeTask(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	var req SnoozeTaskRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	delay, err := ParseSnooze(req.For)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	startAt := time.Now()
+	if task.StartAt != nil && task.StartAt.After(startAt) {
+		startAt = *task.StartAt
+	}
+	startAt = startAt.Add(delay)
+
+	err = s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var before, after snapshot
+		if before, err = taskSnapshot(tx, task.ID); err != nil {
+			return err
+		}
+
+		if err = tx.Model(&task).Update("start_at", startAt).Error; err != nil {
+			return err
+		}
+
+		if after, err = taskSnapshot(tx, task.ID); err != nil {
+			return err
+		}
+		return recordHistory(tx, HistoryTask, task.ID, user.ID, HistoryUpdate, before, after)
+	})
+
+	if err != nil {
+		logger.Printf("could not snooze task: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, SnoozeTaskResponse{Success: true, StartAt: startAt})
+}
+
+// ParseSnooze parses how long to snooze a task for. In addition to the units accepted
+// by time.ParseDuration (e.g. 90m or 4h), whole days and weeks such as 3d or 2w are
+// accepted since tasks are usually deferred for longer than a few hours.
+func ParseSnooze(s string) (d time.Duration, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	if unit > 0 {
+		var n int
+		if n, err = strconv.Atoi(s[:len(s)-1]); err != nil {
+			return 0, fmt.Errorf("could not parse snooze duration %q", s)
+		}
+		d = time.Duration(n) * unit
+	} else if d, err = time.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("could not parse snooze duration %q", s)
+	}
+
+	if d <= 0 {
+		return 0, errSnoozeDuration
+	}
+	return d, nil
+}
@@ -0,0 +1,71 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnooze(t *testing.T) {
+	valid := map[string]time.Duration{
+		"3d":   72 * time.Hour,
+		"1W":   168 * time.Hour,
+		"4h":   4 * time.Hour,
+		"90m":  90 * time.Minute,
+		" 2d ": 48 * time.Hour,
+	}
+
+	for in, expected := range valid {
+		d, err := ParseSnooze(in)
+		require.NoError(t, err, "could not parse %q", in)
+		require.Equal(t, expected, d)
+	}
+
+	for _, in := range []string{"", "d", "xd", "0d", "-1h", "soon"} {
+		_, err := ParseSnooze(in)
+		require.Error(t, err, "expected %q to be invalid", in)
+	}
+}
+
+func (s *TodosTestSuite) TestSnoozeTasks() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "someday"}, &list))
+
+	started := time.Now().Add(-time.Hour)
+	ids := make([]uint, 2)
+	for i, task := range []Task{{Title: "now"}, {Title: "later", StartAt: &started}} {
+		var rep CreateTaskResponse
+		task.ChecklistID = &list.ChecklistID
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids[i] = rep.TaskID
+	}
+
+	// Snoozing a task that has started defers it from now
+	path := fmt.Sprintf("/v1/tasks/%d/snooze", ids[1])
+	var snoozed SnoozeTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, path, SnoozeTaskRequest{For: "3d"}, &snoozed))
+	require.WithinDuration(s.T(), time.Now().Add(72*time.Hour), snoozed.StartAt, time.Minute)
+
+	// Snoozing a deferred task pushes its start date further back
+	first := snoozed.StartAt
+	snoozed = SnoozeTaskResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, path, SnoozeTaskRequest{For: "1d"}, &snoozed))
+	require.WithinDuration(s.T(), first.Add(24*time.Hour), snoozed.StartAt, time.Second)
+
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, path, SnoozeTaskRequest{For: "someday"}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, path, SnoozeTaskRequest{}, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodPost, "/v1/tasks/99999/snooze", SnoozeTaskRequest{For: "1d"}, nil))
+
+	// Deferred tasks are hidden unless requested
+	var rep ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks?checklist=%d", list.ChecklistID), nil, &rep))
+	require.Equal(s.T(), []uint{ids[0]}, taskIDs(rep.Tasks))
+
+	rep = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks?checklist=%d&deferred=true", list.ChecklistID), nil, &rep))
+	require.Equal(s.T(), ids, taskIDs(rep.Tasks))
+}
@@ -0,0 +1,132 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound is returned by storage backends when the blob does not exist.
+var ErrBlobNotFound = errors.New("blob not found in storage")
+
+// Storage is a blob store for the contents of file attachments. The database stores
+// the metadata of each attachment, including its key, and the storage backend stores
+// the contents. Keys are generated by the API and are slash separated paths, e.g.
+// "42/c0ffee". Implementations must be safe for concurrent use.
+type Storage interface {
+	// Put writes the contents of the reader to the blob with the specified key,
+	// returning the number of bytes written.
+	Put(key string, r io.Reader) (int64, error)
+
+	// Get opens the blob with the specified key for reading; the caller must close it.
+	Get(key string) (io.ReadCloser, error)
+
+	// Delete removes the blob with the specified key; deleting a missing blob is not
+	// an error so that deletes can be retried.
+	Delete(key string) error
+}
+
+// NewStorage creates the storage backend described by the settings. Currently only
+// local filesystem storage is supported.
+func NewStorage(conf Settings) (Storage, error) {
+	if conf.StorageDir == "" {
+		return nil, errors.New("a storage directory is required to store attachments")
+	}
+	return NewLocalStorage(conf.StorageDir)
+}
+
+// LocalStorage stores blobs as files in a directory on the local filesystem.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates the storage directory if it does not already exist.
+func NewLocalStorage(root string) (_ *LocalStorage, err error) {
+	if root, err = filepath.Abs(root); err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("could not create storage directory: %s", err)
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Put writes the blob to a temporary file that is renamed once it is completely
+// written, so that partial uploads are never visible.
+func (s *LocalStorage) Put(key string, r io.Reader) (n int64, err error) {
+	var path string
+	if path, err = s.path(key); err != nil {
+		return 0, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+
+	var f *os.File
+	if f, err = ioutil.TempFile(filepath.Dir(path), ".upload-*"); err != nil {
+		return 0, err
+	}
+
+	if n, err = io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return n, err
+	}
+
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+
+	if err = os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+	return n, nil
+}
+
+// Get opens the file for the blob.
+func (s *LocalStorage) Get(key string) (_ io.ReadCloser, err error) {
+	var path string
+	if path, err = s.path(key); err != nil {
+		return nil, err
+	}
+
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes the file for the blob.
+func (s *LocalStorage) Delete(key string) (err error) {
+	var path string
+	if path, err = s.path(key); err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path converts the key into a path inside of the storage directory, ensuring that the
+// key cannot be used to read or write files outside of it.
+func (s *LocalStorage) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return path, nil
+}
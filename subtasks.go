@@ -0,0 +1,208 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// The maximum number of levels in a task hierarchy, including the root task, e.g. a
+// depth of 3 allows a task to have subtasks that themselves have subtasks.
+const maxTaskDepth = 3
+
+var (
+	errTaskCycle      = errors.New("a task cannot be a subtask of itself or its subtasks")
+	errTaskTooDeep    = fmt.Errorf("subtasks cannot be nested more than %d levels deep", maxTaskDepth)
+	errHasSubtasks    = errors.New("task has subtasks, specify cascade to delete them")
+	errParentNotFound = errors.New("parent task does not exist")
+)
+
+// validateParent checks that the task can be made a subtask of the specified parent.
+// The parent must exist and belong to the same user as the task, the task cannot be
+// its own ancestor, and the resulting hierarchy cannot exceed the maximum depth. If the
+// task has not been created yet (e.g. its ID is zero), it is treated as a leaf. Database
+// errors are logged and errInternal is returned.
+func validateParent(db *gorm.DB, task *Task, parentID uint) (err error) {
+	if task.ID > 0 && task.ID == parentID {
+		return errTaskCycle
+	}
+
+	// Walk up the ancestors of the parent to compute its depth and detect cycles.
+	depth := 0
+	next := parentID
+	for next > 0 {
+		var ancestor Task
+		if err = db.Select("id, user_id, workspace_id, parent_id").Where("id = ?", next).First(&ancestor).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return errParentNotFound
+			}
+			logger.Printf("could not find parent task: %s", err)
+			return errInternal
+		}
+
+		// The parent must belong to the same user and workspace as the task
+		if depth == 0 && (ancestor.UserID != task.UserID || !sameWorkspace(ancestor.WorkspaceID, task.WorkspaceID)) {
+			return errParentNotFound
+		}
+
+		if task.ID > 0 && ancestor.ID == task.ID {
+			return errTaskCycle
+		}
+
+		depth++
+		if depth >= maxTaskDepth {
+			return errTaskTooDeep
+		}
+
+		next = 0
+		if ancestor.ParentID != nil {
+			next = *ancestor.ParentID
+		}
+	}
+
+	// Compute the height of the subtree rooted at the task being moved.
+	height := 1
+	if task.ID > 0 {
+		var levels [][]uint
+		if levels, err = descendantLevels(db, task.ID); err != nil {
+			logger.Printf("could not find subtasks: %s", err)
+			return errInternal
+		}
+		height += len(levels)
+	}
+
+	if depth+height > maxTaskDepth {
+		return errTaskTooDeep
+	}
+	return nil
+}
+
+// descendantLevels returns the ids of all of the subtasks of the task grouped by their
+// level below the task, e.g. the first element contains the ids of the direct children.
+// Traversal is limited to the maximum depth to protect against corrupted hierarchies.
+func descendantLevels(db *gorm.DB, taskID uint) (levels [][]uint, err error) {
+	frontier := []uint{taskID}
+	for i := 0; i < maxTaskDepth && len(frontier) > 0; i++ {
+		var children []uint
+		if err = db.Model(&Task{}).Where("parent_id IN (?)", frontier).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		if len(children) > 0 {
+			levels = append(levels, children)
+		}
+		frontier = children
+	}
+	return levels, nil
+}
+
+// descendants flattens the descendant levels of the task into a single list of ids.
+func descendants(db *gorm.DB, taskID uint) (ids []uint, err error) {
+	var levels [][]uint
+	if levels, err = descendantLevels(db, taskID); err != nil {
+		return nil, err
+	}
+
+	for _, level := range levels {
+		ids = append(ids, level...)
+	}
+	return ids, nil
+}
+
+// archiveSubtasks archives all of the open descendants of the task, recording the change
+// in the history of each subtask.
+func archiveSubtasks(db *gorm.DB, taskID, userID uint) (err error) {
+	var ids []uint
+	if ids, err = descendants(db, taskID); err != nil || len(ids) == 0 {
+		return err
+	}
+
+	if err = db.Model(&Task{}).Where("id IN (?) AND archived = ?", ids, false).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	if err = db.Model(&Task{}).Where("id IN (?)", ids).Updates(map[string]interface{}{"archived": true, "archived_at": closedAt(true)}).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err = recordHistory(db, HistoryTask, id, userID, HistoryUpdate, snapshot{"archived": false}, snapshot{"archived": true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSubtasks populates the children of the task recursively, along with their tags,
+// and computes the completion rollups at every level of the tree. The depth is the
+// level of the task in the tree (starting at 1) and bounds the recursion.
+func loadSubtasks(db *gorm.DB, task *Task, depth int) (err error) {
+	if depth >= maxTaskDepth {
+		return nil
+	}
+
+	if err = db.Preload("Tags").Where("parent_id = ?", task.ID).Order("id").Find(&task.Children).Error; err != nil {
+		return err
+	}
+
+	for i := range task.Children {
+		if err = loadSubtasks(db, &task.Children[i], depth+1); err != nil {
+			return err
+		}
+	}
+
+	task.Subtasks, task.SubtasksCompleted = 0, 0
+	for _, child := range task.Children {
+		if child.Archived {
+			continue
+		}
+
+		task.Subtasks++
+		if child.Completed {
+			task.SubtasksCompleted++
+		}
+	}
+	return nil
+}
+
+// subtaskRollups computes the number of direct, unarchived subtasks and how many of
+// them are completed for each of the tasks using a single aggregate query.
+func subtaskRollups(db *gorm.DB, tasks []Task) (err error) {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	var rows []struct {
+		ParentID  uint
+		Total     uint
+		Completed uint
+	}
+
+	err = db.Model(&Task{}).
+		Select("parent_id, COUNT(*) AS total, SUM(CASE WHEN completed THEN 1 ELSE 0 END) AS completed").
+		Where("parent_id IN (?) AND archived = ?", ids, false).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	rollups := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		rollups[row.ParentID] = i
+	}
+
+	for i := range tasks {
+		if idx, ok := rollups[tasks[i].ID]; ok {
+			tasks[i].Subtasks = rows[idx].Total
+			tasks[i].SubtasksCompleted = rows[idx].Completed
+		}
+	}
+	return nil
+}
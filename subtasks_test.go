@@ -0,0 +1,85 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestSubtasks() {
+	create := func(title string, parent uint) uint {
+		task := Task{Title: title}
+		if parent > 0 {
+			task.ParentID = &parent
+		}
+
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep), "could not create %q", title)
+		return rep.TaskID
+	}
+
+	root := create("release", 0)
+	build := create("build", root)
+	deploy := create("deploy", root)
+	binaries := create("binaries", build)
+
+	// The hierarchy cannot be deeper than the maximum depth
+	var rep CreateTaskResponse
+	code := s.Do(http.MethodPost, "/v1/tasks", Task{Title: "too deep", ParentID: &binaries}, &rep)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// Parents must exist
+	missing := uint(99999)
+	code = s.Do(http.MethodPost, "/v1/tasks", Task{Title: "orphan", ParentID: &missing}, &rep)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// A task cannot be moved beneath its own subtasks
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", root), map[string]interface{}{"parent": binaries}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// Moving build beneath deploy would make binaries too deep
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", build), map[string]interface{}{"parent": deploy}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// Complete a subtask and check the rollup
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", deploy), map[string]interface{}{"completed": true}, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+
+	var detail DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", root), nil, &detail))
+	require.Equal(s.T(), uint(2), detail.Task.Subtasks)
+	require.Equal(s.T(), uint(1), detail.Task.SubtasksCompleted)
+	require.Len(s.T(), detail.Task.Children, 2)
+	require.Equal(s.T(), build, detail.Task.Children[0].ID)
+	require.Len(s.T(), detail.Task.Children[0].Children, 1)
+	require.Equal(s.T(), binaries, detail.Task.Children[0].Children[0].ID)
+
+	// Rollups are also computed when listing tasks
+	var list ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks", nil, &list))
+	for _, task := range list.Tasks {
+		if task.ID == root {
+			require.Equal(s.T(), uint(2), task.Subtasks)
+			require.Equal(s.T(), uint(1), task.SubtasksCompleted)
+		}
+	}
+
+	// Archiving a task archives its subtasks
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", build), map[string]interface{}{"archived": true}, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", binaries), nil, &detail))
+	require.True(s.T(), detail.Task.Archived)
+
+	// Tasks with subtasks cannot be deleted without cascade
+	code = s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", root), nil, nil)
+	require.Equal(s.T(), http.StatusConflict, code)
+
+	code = s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d?cascade=true", root), nil, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+
+	for _, id := range []uint{root, build, deploy, binaries} {
+		require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", id), nil, nil))
+	}
+}
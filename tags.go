@@ -0,0 +1,263 @@
+package todos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+//===========================================================================
+// Viewset for Tag objects
+//===========================================================================
+
+// ListTags returns all tags defined by the authenticated user ordered by name.
+func (s *API) ListTags(c *gin.Context) {
+	var tags []Tag
+	user := c.Value(ctxUserKey).(User)
+
+	if err := s.db.Where("user_id = ?", user.ID).Order("name").Find(&tags).Error; err != nil {
+		logger.Printf("could not fetch tags: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListTagsResponse{Success: true, Tags: tags})
+}
+
+// CreateTag creates a new tag for the authenticated user. Tag names must be unique for
+// the user, if the tag already exists a bad request is returned.
+func (s *API) CreateTag(c *gin.Context) {
+	// Parse the user input
+	tag := Tag{}
+	if err := c.ShouldBind(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if tag.Name = strings.TrimSpace(tag.Name); tag.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errEmptyTagName))
+		return
+	}
+
+	// Add the user to the tag
+	user := c.Value(ctxUserKey).(User)
+	tag.ID = 0
+	tag.UserID = user.ID
+
+	// Ensure the tag does not already exist
+	var count int
+	if err := s.db.Model(&Tag{}).Where("user_id = ? AND name = ?", user.ID, tag.Name).Count(&count).Error; err != nil {
+		logger.Printf("could not count tags: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse(fmt.Errorf("tag %q already exists", tag.Name)))
+		return
+	}
+
+	// Create the tag in the database
+	if err := s.db.Create(&tag).Error; err != nil {
+		logger.Printf("could not create tag: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateTagResponse{Success: true, TagID: tag.ID})
+}
+
+// DetailTag returns the tag if it belongs to the authenticated user.
+func (s *API) DetailTag(c *gin.Context) {
+	var tag Tag
+	user := c.Value(ctxUserKey).(User)
+	if err := s.db.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&tag).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return
+		}
+		logger.Printf("could not find tag: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DetailTagResponse{Success: true, Tag: tag})
+}
+
+// UpdateTag renames the tag, ensuring the new name does not collide with another tag.
+func (s *API) UpdateTag(c *gin.Context) {
+	// Fetch the tag to update
+	tag := Tag{}
+	user := c.Value(ctxUserKey).(User)
+	if err := s.db.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&tag).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return
+		}
+		logger.Printf("could not find tag: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	// Parse the user input
+	var input Tag
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if input.Name = strings.TrimSpace(input.Name); input.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errEmptyTagName))
+		return
+	}
+
+	var count int
+	if err := s.db.Model(&Tag{}).Where("user_id = ? AND name = ? AND id <> ?", user.ID, input.Name, tag.ID).Count(&count).Error; err != nil {
+		logger.Printf("could not count tags: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse(fmt.Errorf("tag %q already exists", input.Name)))
+		return
+	}
+
+	if err := s.db.Model(&tag).Update("name", input.Name).Error; err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateTagResponse{Success: true})
+}
+
+// DeleteTag removes the tag from all of its tasks and then deletes it. The tasks
+// themselves are not modified.
+func (s *API) DeleteTag(c *gin.Context) {
+	var tag Tag
+	user := c.Value(ctxUserKey).(User)
+	if err := s.db.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&tag).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return
+		}
+		logger.Printf("could not find tag: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&tag).Association("Tasks").Clear().Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteTagResponse{Success: true})
+}
+
+//===========================================================================
+// Tag Helpers
+//===========================================================================
+
+var errEmptyTagName = errors.New("tag name cannot be empty")
+
+// resolveTags looks up the tags specified by the user so that they can be associated
+// with a task. Tags can be referenced either by id or by name; tags referenced by name
+// that do not exist yet are created for the user. An error is returned if a tag id does
+// not belong to the user. The returned tags are deduplicated and all have primary keys.
+func resolveTags(db *gorm.DB, user uint, in []Tag) (tags []Tag, err error) {
+	seen := make(map[uint]struct{}, len(in))
+	tags = make([]Tag, 0, len(in))
+
+	for _, ref := range in {
+		tag := Tag{}
+		if ref.ID > 0 {
+			if err = db.Where("id = ? AND user_id = ?", ref.ID, user).First(&tag).Error; err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					return nil, fmt.Errorf("tag %d does not exist", ref.ID)
+				}
+				logger.Printf("could not find tag: %s", err)
+				return nil, errInternal
+			}
+		} else {
+			name := strings.TrimSpace(ref.Name)
+			if name == "" {
+				return nil, errEmptyTagName
+			}
+
+			if err = db.Where(Tag{UserID: user, Name: name}).FirstOrCreate(&tag).Error; err != nil {
+				logger.Printf("could not create tag: %s", err)
+				return nil, errInternal
+			}
+		}
+
+		if _, ok := seen[tag.ID]; ok {
+			continue
+		}
+		seen[tag.ID] = struct{}{}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// parseTagsInput converts the tags value from a generic update map into tag references.
+// Tags may be specified as objects with an id or name, or simply as the tag name.
+func parseTagsInput(val interface{}) (tags []Tag, err error) {
+	items, ok := val.([]interface{})
+	if !ok && val != nil {
+		return nil, errors.New("tags must be a list of tag names or objects")
+	}
+
+	tags = make([]Tag, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			tags = append(tags, Tag{Name: v})
+		case map[string]interface{}:
+			var data []byte
+			if data, err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+
+			var tag Tag
+			if err = json.Unmarshal(data, &tag); err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		default:
+			return nil, errors.New("tags must be a list of tag names or objects")
+		}
+	}
+
+	return tags, nil
+}
+
+// normalizeTagNames trims and deduplicates tag names used to filter queries.
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out
+}
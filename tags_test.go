@@ -0,0 +1,73 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTags() {
+	// Create a tag directly
+	var created CreateTagResponse
+	code := s.Do(http.MethodPost, "/v1/tags", Tag{Name: "work"}, &created)
+	require.Equal(s.T(), http.StatusCreated, code)
+	require.NotZero(s.T(), created.TagID)
+
+	// Duplicate tags are not allowed
+	code = s.Do(http.MethodPost, "/v1/tags", Tag{Name: "work"}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// Create tasks with tags by name and by id
+	var task CreateTaskResponse
+	code = s.Do(http.MethodPost, "/v1/tasks", Task{Title: "write report", Tags: []Tag{{ID: created.TagID}, {Name: "urgent"}}}, &task)
+	require.Equal(s.T(), http.StatusCreated, code)
+	both := task.TaskID
+
+	code = s.Do(http.MethodPost, "/v1/tasks", Task{Title: "file expenses", Tags: []Tag{{Name: "work"}}}, &task)
+	require.Equal(s.T(), http.StatusCreated, code)
+	work := task.TaskID
+
+	// Tags that belong to other users cannot be referenced
+	code = s.Do(http.MethodPost, "/v1/tasks", Task{Title: "sneaky", Tags: []Tag{{ID: 9999}}}, nil)
+	require.Equal(s.T(), http.StatusBadRequest, code)
+
+	// Filtering by a single tag returns both tasks
+	var list ListTasksResponse
+	code = s.Do(http.MethodGet, "/v1/tasks?tag=work", nil, &list)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.ElementsMatch(s.T(), []uint{both, work}, taskIDs(list.Tasks))
+
+	// Filtering by multiple tags returns only tasks with all of the tags
+	code = s.Do(http.MethodGet, "/v1/tasks?tag=work&tag=urgent", nil, &list)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.Equal(s.T(), []uint{both}, taskIDs(list.Tasks))
+	require.Len(s.T(), list.Tasks[0].Tags, 2)
+
+	// Replace the tags on a task with an update
+	code = s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", work), map[string]interface{}{"tags": []string{"urgent"}}, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+
+	code = s.Do(http.MethodGet, "/v1/tasks?tag=urgent", nil, &list)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.ElementsMatch(s.T(), []uint{both, work}, taskIDs(list.Tasks))
+
+	// Deleting a tag removes it from the tasks
+	code = s.Do(http.MethodDelete, fmt.Sprintf("/v1/tags/%d", created.TagID), nil, nil)
+	require.Equal(s.T(), http.StatusOK, code)
+
+	var detail DetailTaskResponse
+	code = s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", both), nil, &detail)
+	require.Equal(s.T(), http.StatusOK, code)
+	require.Len(s.T(), detail.Task.Tags, 1)
+	require.Equal(s.T(), "urgent", detail.Task.Tags[0].Name)
+}
+
+func taskIDs(tasks []Task) []uint {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
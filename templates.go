@@ -0,0 +1,256 @@
+package todos
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+//===========================================================================
+// Viewset for ChecklistTemplate objects
+//===========================================================================
+
+// ListTemplates returns the checklist templates of the authenticated user. The tasks of
+// the templates are not included, they are returned by the template detail.
+func (s *API) ListTemplates(c *gin.Context) {
+	var templates []ChecklistTemplate
+	user := c.Value(ctxUserKey).(User)
+	if err := s.db.Where("user_id = ?", user.ID).Order("title").Order("id").Find(&templates).Error; err != nil {
+		logger.Printf("could not fetch templates: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ListTemplatesResponse{Success: true, Templates: templates})
+}
+
+// SaveTemplate saves the checklist as a template with a copy of each of its tasks that
+// have not been archived. The deadlines of the checklist and its tasks are stored as
+// offsets from the anchor so they can be recomputed when the template is instantiated.
+func (s *API) SaveTemplate(c *gin.Context) {
+	var list Checklist
+	user := c.Value(ctxUserKey).(User)
+	query := s.db.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Where("archived = ?", false).Order("position").Order("id")
+	})
+
+	query = inWorkspace(query, "checklists", activeWorkspace(c))
+	if err := query.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&list).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return
+		}
+		logger.Printf("could not find checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	// The request body is optional
+	var req SaveTemplateRequest
+	if err := c.ShouldBind(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	anchor := list.CreatedAt
+	switch {
+	case req.Anchor != nil:
+		anchor = *req.Anchor
+	case list.Deadline != nil:
+		anchor = *list.Deadline
+	}
+
+	template := ChecklistTemplate{
+		UserID:         user.ID,
+		Title:          req.Title,
+		Details:        list.Details,
+		DeadlineOffset: deadlineOffset(list.Deadline, anchor),
+		Tasks:          make([]TemplateTask, 0, len(list.Tasks)),
+	}
+
+	if template.Title == "" {
+		template.Title = list.Title
+	}
+
+	for i, task := range list.Tasks {
+		template.Tasks = append(template.Tasks, TemplateTask{
+			Position:       i,
+			Title:          task.Title,
+			Details:        task.Details,
+			Priority:       task.Priority,
+			Estimate:       task.Estimate,
+			DeadlineOffset: deadlineOffset(task.Deadline, anchor),
+		})
+	}
+
+	if err := s.db.Create(&template).Error; err != nil {
+		logger.Printf("could not create template: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateTemplateResponse{Success: true, TemplateID: template.ID})
+}
+
+// DetailTemplate returns the template with its tasks in order.
+func (s *API) DetailTemplate(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	template, ok := s.userTemplate(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, DetailTemplateResponse{Success: true, Template: template})
+}
+
+// InstantiateTemplate creates a new checklist for the authenticated user in the active
+// workspace with a task for each of the tasks in the template. Deadlines are computed
+// from the anchor time.
+func (s *API) InstantiateTemplate(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	template, ok := s.userTemplate(c, user)
+	if !ok {
+		return
+	}
+
+	// The request body is optional
+	var req InstantiateTemplateRequest
+	if err := c.ShouldBind(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	anchor := time.Now()
+	if req.Anchor != nil {
+		anchor = *req.Anchor
+	}
+
+	list := Checklist{
+		UserID:      user.ID,
+		WorkspaceID: activeWorkspace(c),
+		Title:       req.Title,
+		Details:     template.Details,
+		Deadline:    offsetDeadline(anchor, template.DeadlineOffset),
+	}
+
+	if list.Title == "" {
+		list.Title = template.Title
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Create(&list).Error; err != nil {
+			return err
+		}
+
+		var after snapshot
+		if after, err = checklistSnapshot(tx, list.ID); err != nil {
+			return err
+		}
+
+		if err = recordHistory(tx, HistoryChecklist, list.ID, user.ID, HistoryCreate, nil, after); err != nil {
+			return err
+		}
+
+		// Tasks are created in order so that they are positioned as in the template
+		for _, item := range template.Tasks {
+			task := Task{
+				UserID:      user.ID,
+				WorkspaceID: list.WorkspaceID,
+				Title:       item.Title,
+				Details:     item.Details,
+				Priority:    item.Priority,
+				Estimate:    item.Estimate,
+				ChecklistID: &list.ID,
+				Deadline:    offsetDeadline(anchor, item.DeadlineOffset),
+			}
+
+			if err = tx.Create(&task).Error; err != nil {
+				return err
+			}
+
+			if err = recordTaskCreate(tx, task.ID, user.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		logger.Printf("could not instantiate template: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateChecklistResponse{Success: true, ChecklistID: list.ID})
+}
+
+// DeleteTemplate removes the template and its tasks. Checklists that were instantiated
+// from the template are not affected.
+func (s *API) DeleteTemplate(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	template, ok := s.userTemplate(c, user)
+	if !ok {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Where("template_id = ?", template.ID).Delete(&TemplateTask{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	})
+
+	if err != nil {
+		logger.Printf("could not delete template: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteTemplateResponse{Success: true})
+}
+
+//===========================================================================
+// ChecklistTemplate Helpers
+//===========================================================================
+
+// userTemplate fetches the template in the URL with its tasks if it belongs to the user,
+// otherwise the error response is written and ok is false.
+func (s *API) userTemplate(c *gin.Context, user User) (template ChecklistTemplate, ok bool) {
+	query := s.db.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position").Order("id")
+	})
+
+	if err := query.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).First(&template).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return template, false
+		}
+		logger.Printf("could not find template: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return template, false
+	}
+	return template, true
+}
+
+// deadlineOffset returns the number of seconds from the anchor to the deadline or nil
+// if there is no deadline.
+func deadlineOffset(deadline *time.Time, anchor time.Time) *int64 {
+	if deadline == nil {
+		return nil
+	}
+	offset := int64(deadline.Sub(anchor) / time.Second)
+	return &offset
+}
+
+// offsetDeadline computes a deadline from the anchor and an offset in seconds or nil if
+// there is no offset.
+func offsetDeadline(anchor time.Time, offset *int64) *time.Time {
+	if offset == nil {
+		return nil
+	}
+	deadline := anchor.Add(time.Duration(*offset) * time.Second)
+	return &deadline
+}
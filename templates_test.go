@@ -0,0 +1,76 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestChecklistTemplates() {
+	// Create a release checklist whose deadlines are relative to the release date
+	release := time.Date(2020, 7, 15, 17, 0, 0, 0, time.UTC)
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "release", Deadline: &release}, &list))
+
+	freeze := release.Add(-72 * time.Hour)
+	tasks := []Task{
+		{Title: "code freeze", Deadline: &freeze, Estimate: 1},
+		{Title: "changelog", Priority: PriorityHigh},
+		{Title: "tag release", Deadline: &release},
+		{Title: "abandoned"},
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		var rep CreateTaskResponse
+		task.ChecklistID = &list.ChecklistID
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", task, &rep))
+		ids[i] = rep.TaskID
+	}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d", ids[3]), map[string]interface{}{"archived": true}, nil))
+
+	// Save the checklist as a template, archived tasks are not included
+	var saved CreateTemplateResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, fmt.Sprintf("/v1/lists/%d/template", list.ChecklistID), nil, &saved))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodPost, "/v1/lists/99999/template", nil, nil))
+
+	var detail DetailTemplateResponse
+	path := fmt.Sprintf("/v1/templates/%d", saved.TemplateID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, path, nil, &detail))
+	require.Equal(s.T(), "release", detail.Template.Title)
+	require.Equal(s.T(), int64(0), *detail.Template.DeadlineOffset)
+	require.Len(s.T(), detail.Template.Tasks, 3)
+	require.Equal(s.T(), int64(-259200), *detail.Template.Tasks[0].DeadlineOffset)
+	require.Nil(s.T(), detail.Template.Tasks[1].DeadlineOffset)
+	require.Equal(s.T(), PriorityHigh, detail.Template.Tasks[1].Priority)
+
+	var templates ListTemplatesResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/templates", nil, &templates))
+	require.NotEmpty(s.T(), templates.Templates)
+
+	// Instantiate the template for the next release
+	anchor := time.Date(2020, 9, 1, 17, 0, 0, 0, time.UTC)
+	var created CreateChecklistResponse
+	req := InstantiateTemplateRequest{Title: "release v2", Anchor: &anchor}
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, path+"/instantiate", req, &created))
+	require.NotEqual(s.T(), list.ChecklistID, created.ChecklistID)
+
+	var next DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", created.ChecklistID), nil, &next))
+	require.Equal(s.T(), "release v2", next.Checklist.Title)
+	require.True(s.T(), anchor.Equal(*next.Checklist.Deadline))
+	require.Len(s.T(), next.Checklist.Tasks, 3)
+	require.Equal(s.T(), "code freeze", next.Checklist.Tasks[0].Title)
+	require.True(s.T(), anchor.Add(-72*time.Hour).Equal(*next.Checklist.Tasks[0].Deadline))
+	require.Equal(s.T(), uint(1), next.Checklist.Tasks[0].Estimate)
+	require.Nil(s.T(), next.Checklist.Tasks[1].Deadline)
+	require.True(s.T(), anchor.Equal(*next.Checklist.Tasks[2].Deadline))
+
+	// Deleting the template does not delete the checklists created from it
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, path, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", created.ChecklistID), nil, nil))
+}
@@ -0,0 +1,288 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	errNoRunningTimer  = errors.New("no timer is running")
+	errTimeRange       = errors.New("the stop time must be after the start time")
+	errTimeEntryFields = errors.New("specify the stop time or the number of seconds, not both")
+)
+
+//===========================================================================
+// Viewset for TimeEntry objects
+//===========================================================================
+
+// ListTimeEntries returns the time entries of the authenticated user that were started
+// in the specified time range, optionally filtered by task or checklist. Entries are
+// ordered by when they were started so that they can be summarized by day.
+func (s *API) ListTimeEntries(c *gin.Context) {
+	var req ListTimeEntriesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	user := c.Value(ctxUserKey).(User)
+	query := s.db.Preload("Task").Where("time_entries.user_id = ?", user.ID)
+
+	if req.Task > 0 {
+		query = query.Where("time_entries.task_id = ?", req.Task)
+	}
+
+	if req.Checklist > 0 {
+		query = query.Joins("JOIN tasks ON tasks.id = time_entries.task_id").Where("tasks.checklist_id = ?", req.Checklist)
+	}
+
+	if !req.From.IsZero() {
+		query = query.Where("time_entries.started >= ?", req.From)
+	}
+
+	if !req.To.IsZero() {
+		query = query.Where("time_entries.started < ?", req.To)
+	}
+
+	var entries []TimeEntry
+	if err := query.Order("time_entries.started").Order("time_entries.id").Find(&entries).Error; err != nil {
+		logger.Printf("could not fetch time entries: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	rep := ListTimeEntriesResponse{Success: true, TimeEntries: entries}
+	for i := range rep.TimeEntries {
+		rep.TimeEntries[i].setTask()
+		rep.Total += rep.TimeEntries[i].Elapsed(time.Now())
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// CreateTimeEntry manually records time spent on a task. The entry must specify when
+// the work started and either when it stopped or how many seconds were spent.
+func (s *API) CreateTimeEntry(c *gin.Context) {
+	var entry TimeEntry
+	if err := c.ShouldBind(&entry); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if entry.Started.IsZero() {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errors.New("the start time is required")))
+		return
+	}
+
+	switch {
+	case entry.Stopped != nil && entry.Seconds != 0:
+		c.JSON(http.StatusBadRequest, ErrorResponse(errTimeEntryFields))
+		return
+	case entry.Stopped == nil:
+		stopped := entry.Started.Add(time.Duration(entry.Seconds) * time.Second)
+		entry.Stopped = &stopped
+	}
+
+	if !entry.Stopped.After(entry.Started) {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errTimeRange))
+		return
+	}
+	entry.Seconds = int64(entry.Stopped.Sub(entry.Started) / time.Second)
+
+	user := c.Value(ctxUserKey).(User)
+	if _, err := requireTaskRole(s.db, user.ID, activeWorkspace(c), entry.TaskID, RoleEditor); err != nil {
+		switch err {
+		case errTaskMissing:
+			c.JSON(http.StatusBadRequest, ErrorResponse(fmt.Errorf("task %d does not exist", entry.TaskID)))
+		case errForbidden:
+			c.JSON(http.StatusForbidden, ErrorResponse(err))
+		default:
+			logger.Printf("could not find task: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		}
+		return
+	}
+
+	entry.ID = 0
+	entry.UserID = user.ID
+	if err := s.db.Create(&entry).Error; err != nil {
+		logger.Printf("could not create time entry: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, TimeEntryResponse{Success: true, TimeEntry: &entry})
+}
+
+// DeleteTimeEntry removes one of the authenticated user's time entries.
+func (s *API) DeleteTimeEntry(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	query := s.db.Where("id = ? AND user_id = ?", c.Param("id"), user.ID).Delete(&TimeEntry{})
+	if err := query.Error; err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if query.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteTimeEntryResponse{Success: true})
+}
+
+// RunningTimer returns the authenticated user's running timer, if there is one.
+func (s *API) RunningTimer(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	entry, err := runningTimer(s.db, user.ID)
+	if err != nil {
+		logger.Printf("could not fetch running timer: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if entry == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse(errNoRunningTimer))
+		return
+	}
+
+	c.JSON(http.StatusOK, TimeEntryResponse{Success: true, TimeEntry: entry})
+}
+
+// StartTimer starts a timer on the task. Users can only have one running timer, so if
+// another timer is running, it is stopped first and returned in the response.
+func (s *API) StartTimer(c *gin.Context) {
+	var req StartTimerRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	user := c.Value(ctxUserKey).(User)
+	rep := StartTimerResponse{Success: true}
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if _, err = requireTaskRole(tx, user.ID, activeWorkspace(c), req.TaskID, RoleEditor); err != nil {
+			if err == errTaskMissing {
+				return fmt.Errorf("task %d does not exist", req.TaskID)
+			}
+			return err
+		}
+
+		now := time.Now()
+		if rep.Stopped, err = stopTimer(tx, user.ID, now); err != nil && err != errNoRunningTimer {
+			return err
+		}
+
+		rep.TimeEntry = TimeEntry{TaskID: req.TaskID, UserID: user.ID, Started: now, Notes: req.Notes}
+		return tx.Create(&rep.TimeEntry).Error
+	})
+
+	if err != nil {
+		if err == errForbidden {
+			c.JSON(http.StatusForbidden, ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, rep)
+}
+
+// StopTimer stops the authenticated user's running timer.
+func (s *API) StopTimer(c *gin.Context) {
+	var entry *TimeEntry
+	user := c.Value(ctxUserKey).(User)
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		entry, err = stopTimer(tx, user.ID, time.Now())
+		return err
+	})
+
+	if err != nil {
+		if err == errNoRunningTimer {
+			c.JSON(http.StatusNotFound, ErrorResponse(err))
+			return
+		}
+		logger.Printf("could not stop timer: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, TimeEntryResponse{Success: true, TimeEntry: entry})
+}
+
+//===========================================================================
+// TimeEntry Helpers
+//===========================================================================
+
+// Elapsed returns the number of seconds tracked by the entry; if the timer is still
+// running, the seconds elapsed until now are returned.
+func (e TimeEntry) Elapsed(now time.Time) int64 {
+	if e.Stopped == nil {
+		return int64(now.Sub(e.Started) / time.Second)
+	}
+	return e.Seconds
+}
+
+// setTask copies the title and checklist from the preloaded task for reporting.
+func (e *TimeEntry) setTask() {
+	e.TaskTitle = e.Task.Title
+	e.ChecklistID = e.Task.ChecklistID
+}
+
+// runningTimer returns the user's running timer or nil if there is none.
+func runningTimer(db *gorm.DB, userID uint) (_ *TimeEntry, err error) {
+	entry := &TimeEntry{}
+	if err = db.Preload("Task").Where("user_id = ? AND stopped IS NULL", userID).First(entry).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entry.setTask()
+	return entry, nil
+}
+
+// stopTimer stops the user's running timer at the specified time.
+func stopTimer(db *gorm.DB, userID uint, now time.Time) (entry *TimeEntry, err error) {
+	if entry, err = runningTimer(db, userID); err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, errNoRunningTimer
+	}
+
+	entry.Stopped = &now
+	entry.Seconds = entry.Elapsed(now)
+	if err = db.Model(entry).Updates(map[string]interface{}{"stopped": now, "seconds": entry.Seconds}).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// trackedTime returns the total number of seconds tracked on the tasks selected by the
+// query, including the time elapsed on running timers. The query should filter the
+// time_entries table, e.g. by joining tasks to filter by checklist.
+func trackedTime(query *gorm.DB) (total int64, err error) {
+	var row struct{ Total int64 }
+	if err = query.Select("COALESCE(SUM(time_entries.seconds), 0) AS total").Where("time_entries.stopped IS NOT NULL").Scan(&row).Error; err != nil {
+		return 0, err
+	}
+
+	var running []time.Time
+	if err = query.Where("time_entries.stopped IS NULL").Pluck("time_entries.started", &running).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, started := range running {
+		total += TimeEntry{Started: started}.Elapsed(now)
+	}
+	return row.Total + total, nil
+}
@@ -0,0 +1,89 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTimeTracking() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "billable"}, &list))
+
+	ids := make([]uint, 2)
+	for i, title := range []string{"design", "develop"} {
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: title, ChecklistID: &list.ChecklistID}, &rep))
+		ids[i] = rep.TaskID
+	}
+
+	// No timer is running yet
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, "/v1/timer", nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodDelete, "/v1/timer", nil, nil))
+
+	// Starting a timer on another task stops the running timer
+	var started StartTimerResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/timer", StartTimerRequest{TaskID: ids[0]}, &started))
+	require.Nil(s.T(), started.Stopped)
+
+	started = StartTimerResponse{}
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/timer", StartTimerRequest{TaskID: ids[1]}, &started))
+	require.NotNil(s.T(), started.Stopped)
+	require.Equal(s.T(), ids[0], started.Stopped.TaskID)
+	require.NotNil(s.T(), started.Stopped.Stopped)
+
+	var running TimeEntryResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/timer", nil, &running))
+	require.Equal(s.T(), ids[1], running.TimeEntry.TaskID)
+	require.Equal(s.T(), "develop", running.TimeEntry.TaskTitle)
+	require.Nil(s.T(), running.TimeEntry.Stopped)
+
+	var stopped TimeEntryResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, "/v1/timer", nil, &stopped))
+	require.NotNil(s.T(), stopped.TimeEntry.Stopped)
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, "/v1/timer", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/timer", StartTimerRequest{TaskID: 99999}, nil))
+
+	// Manually enter time, either with a stop time or a number of seconds
+	day := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+	end := day.Add(90 * time.Minute)
+	var manual TimeEntryResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: ids[0], Started: day, Stopped: &end}, &manual))
+	require.Equal(s.T(), int64(5400), manual.TimeEntry.Seconds)
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: ids[1], Started: day.Add(2 * time.Hour), Seconds: 1800}, nil))
+
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: ids[0], Started: end, Stopped: &day}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: ids[0], Started: day, Stopped: &end, Seconds: 60}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: ids[0], Seconds: 60}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPost, "/v1/time", TimeEntry{TaskID: 99999, Started: day, Seconds: 60}, nil))
+
+	// Totals are reported on the task and the checklist
+	var task DetailTaskResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", ids[0]), nil, &task))
+	require.True(s.T(), task.Task.TimeTracked >= 5400 && task.Task.TimeTracked < 5460)
+
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, &detail))
+	require.True(s.T(), detail.Checklist.TimeTracked >= 7200 && detail.Checklist.TimeTracked < 7260)
+
+	// List the entries in a time range
+	query := url.Values{}
+	query.Set("checklist", fmt.Sprint(list.ChecklistID))
+	query.Set("from", day.Format(time.RFC3339))
+	query.Set("to", day.Add(24*time.Hour).Format(time.RFC3339))
+
+	var entries ListTimeEntriesResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/time?"+query.Encode(), nil, &entries))
+	require.Len(s.T(), entries.TimeEntries, 2)
+	require.Equal(s.T(), int64(7200), entries.Total)
+	require.Equal(s.T(), list.ChecklistID, *entries.TimeEntries[0].ChecklistID)
+
+	// Delete a time entry
+	path := fmt.Sprintf("/v1/time/%d", manual.TimeEntry.ID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, path, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodDelete, path, nil, nil))
+}
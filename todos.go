@@ -30,6 +30,7 @@ type API struct {
 	srv     *http.Server // handle to a custom http server with specified API defaults
 	router  *gin.Engine  // the http handler and associated middle ware (used for testing)
 	db      *gorm.DB     // connection to the database through GORM
+	storage Storage      // blob storage for the contents of attachments
 	healthy bool         // application state of the server
 	done    chan bool    // synchronize shutdown gracefully
 }
@@ -54,6 +55,11 @@ func New(conf Settings) (api *API, err error) {
 		return nil, err
 	}
 
+	// Connect to the attachments storage
+	if api.storage, err = NewStorage(api.conf); err != nil {
+		return nil, err
+	}
+
 	// Create the router
 	gin.SetMode(api.conf.Mode)
 	api.router = gin.Default()
@@ -84,6 +90,10 @@ func (s *API) Serve() (err error) {
 		go s.TokensCleanupService()
 	}
 
+	if s.conf.TrashCleanup {
+		go s.TrashCleanupService()
+	}
+
 	logger.Printf("todo server listening on %s", s.conf.Endpoint())
 	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return err
@@ -165,13 +175,29 @@ func (s *API) setupRoutes() (err error) {
 
 		// Application routes
 		v1.GET("/", authorize, s.Overview)
+		v1.GET("/search", authorize, s.Search)
 		tasks := v1.Group("/tasks", authorize)
 		{
 			tasks.GET("", s.ListTasks)
 			tasks.POST("", s.CreateTask)
 			tasks.GET("/:id", s.DetailTask)
 			tasks.PUT("/:id", s.UpdateTask)
+			tasks.PATCH("/:id", s.UpdateTask)
 			tasks.DELETE("/:id", s.DeleteTask)
+			tasks.GET("/:id/dependencies", s.ListDependencies)
+			tasks.POST("/:id/dependencies", s.CreateDependency)
+			tasks.DELETE("/:id/dependencies/:blocker", s.DeleteDependency)
+			tasks.PUT("/:id/move", s.MoveTask)
+			tasks.POST("/:id/snooze", s.SnoozeTask)
+			tasks.GET("/:id/history", s.TaskHistory)
+			tasks.GET("/:id/comments", s.ListComments)
+			tasks.POST("/:id/comments", s.CreateComment)
+			tasks.PUT("/:id/comments/:comment", s.UpdateComment)
+			tasks.DELETE("/:id/comments/:comment", s.DeleteComment)
+			tasks.GET("/:id/attachments", s.ListAttachments)
+			tasks.POST("/:id/attachments", s.CreateAttachment)
+			tasks.GET("/:id/attachments/:attachment", s.DownloadAttachment)
+			tasks.DELETE("/:id/attachments/:attachment", s.DeleteAttachment)
 		}
 
 		lists := v1.Group("/lists", authorize)
@@ -180,7 +206,87 @@ func (s *API) setupRoutes() (err error) {
 			lists.POST("", s.CreateChecklist)
 			lists.GET("/:id", s.DetailChecklist)
 			lists.PUT("/:id", s.UpdateChecklist)
+			lists.PATCH("/:id", s.UpdateChecklist)
 			lists.DELETE("/:id", s.DeleteChecklist)
+			lists.PUT("/:id/order", s.ReorderChecklist)
+			lists.GET("/:id/history", s.ChecklistHistory)
+			lists.POST("/:id/template", s.SaveTemplate)
+			lists.GET("/:id/shares", s.ListShares)
+			lists.PUT("/:id/shares", s.ShareChecklist)
+			lists.DELETE("/:id/shares/:username", s.UnshareChecklist)
+			lists.GET("/:id/fields", s.ListFields)
+			lists.POST("/:id/fields", s.CreateField)
+			lists.PUT("/:id/fields/:field", s.UpdateField)
+			lists.DELETE("/:id/fields/:field", s.DeleteField)
+		}
+
+		projects := v1.Group("/projects", authorize)
+		{
+			projects.GET("", s.ListProjects)
+			projects.POST("", s.CreateProject)
+			projects.GET("/:id", s.DetailProject)
+			projects.PUT("/:id", s.UpdateProject)
+			projects.DELETE("/:id", s.DeleteProject)
+		}
+
+		workspaces := v1.Group("/workspaces", authorize)
+		{
+			workspaces.GET("", s.ListWorkspaces)
+			workspaces.POST("", s.CreateWorkspace)
+			workspaces.GET("/:id", s.DetailWorkspace)
+			workspaces.PUT("/:id", s.UpdateWorkspace)
+			workspaces.DELETE("/:id", s.DeleteWorkspace)
+			workspaces.PUT("/:id/members", s.AddMember)
+			workspaces.DELETE("/:id/members/:username", s.RemoveMember)
+		}
+
+		templates := v1.Group("/templates", authorize)
+		{
+			templates.GET("", s.ListTemplates)
+			templates.GET("/:id", s.DetailTemplate)
+			templates.POST("/:id/instantiate", s.InstantiateTemplate)
+			templates.DELETE("/:id", s.DeleteTemplate)
+		}
+
+		entries := v1.Group("/time", authorize)
+		{
+			entries.GET("", s.ListTimeEntries)
+			entries.POST("", s.CreateTimeEntry)
+			entries.DELETE("/:id", s.DeleteTimeEntry)
+		}
+
+		timer := v1.Group("/timer", authorize)
+		{
+			timer.GET("", s.RunningTimer)
+			timer.POST("", s.StartTimer)
+			timer.DELETE("", s.StopTimer)
+		}
+
+		trash := v1.Group("/trash", authorize)
+		{
+			trash.GET("", s.ListTrash)
+			trash.DELETE("", s.EmptyTrash)
+			trash.POST("/tasks/:id/restore", s.RestoreTask)
+			trash.POST("/lists/:id/restore", s.RestoreChecklist)
+		}
+
+		tags := v1.Group("/tags", authorize)
+		{
+			tags.GET("", s.ListTags)
+			tags.POST("", s.CreateTag)
+			tags.GET("/:id", s.DetailTag)
+			tags.PUT("/:id", s.UpdateTag)
+			tags.DELETE("/:id", s.DeleteTag)
+		}
+
+		views := v1.Group("/views", authorize)
+		{
+			views.GET("", s.ListViews)
+			views.POST("", s.CreateView)
+			views.GET("/:id", s.DetailView)
+			views.PUT("/:id", s.UpdateView)
+			views.DELETE("/:id", s.DeleteView)
+			views.GET("/:id/tasks", s.EvaluateView)
 		}
 	}
 
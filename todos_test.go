@@ -3,8 +3,11 @@ package todos_test
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	. "github.com/bbengfort/todos"
@@ -30,21 +33,28 @@ type TodosTestSuite struct {
 	router           http.Handler
 	adminAccessToken string
 	userAccessToken  string
+	workspace        string
 }
 
 func (s *TodosTestSuite) SetupSuite() {
 	var err error
 	gin.SetMode(gin.TestMode)
 
+	// Store attachments in a temporary directory that is removed after the tests
+	storage, err := ioutil.TempDir("", "todos-attachments")
+	s.NoError(err)
+
 	// Create test configuration for mocked database and server
 	s.conf = Settings{
-		Mode:        gin.TestMode,
-		UseTLS:      false,
-		Bind:        "127.0.0.1",
-		Port:        8080,
-		Domain:      "localhost",
-		DatabaseURL: "file::memory:?cache=shared",
-		SecretKey:   "supersecretkey",
+		Mode:          gin.TestMode,
+		UseTLS:        false,
+		Bind:          "127.0.0.1",
+		Port:          8080,
+		Domain:        "localhost",
+		DatabaseURL:   "file::memory:?cache=shared",
+		SecretKey:     "supersecretkey",
+		StorageDir:    storage,
+		MaxUploadSize: 4096,
 	}
 
 	// Create the api, which will setup both the routes and the database
@@ -58,6 +68,10 @@ func (s *TodosTestSuite) SetupSuite() {
 	s.api.SetHealth(true)
 }
 
+func (s *TodosTestSuite) TearDownSuite() {
+	s.NoError(os.RemoveAll(s.conf.StorageDir))
+}
+
 func TestTodos(t *testing.T) {
 	suite.Run(t, new(TodosTestSuite))
 }
@@ -157,3 +171,35 @@ func (s *TodosTestSuite) Login(admin bool) (token string) {
 
 	return access
 }
+
+// Do executes an authenticated JSON request as the test user against the router and
+// decodes the JSON response into rep (if not nil), returning the http status code.
+func (s *TodosTestSuite) Do(method, path string, data interface{}, rep interface{}) int {
+	return s.DoAs(false, method, path, data, rep)
+}
+
+// DoAs executes an authenticated JSON request as the admin user if admin is true or as
+// the test user otherwise, so that requests from two different users can be tested. If
+// the suite has a workspace, it is selected with the workspace header.
+func (s *TodosTestSuite) DoAs(admin bool, method, path string, data interface{}, rep interface{}) int {
+	var body io.Reader = http.NoBody
+	if data != nil {
+		payload, err := json.Marshal(data)
+		s.NoError(err)
+		body = bytes.NewReader(payload)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.Login(admin))
+	if s.workspace != "" {
+		req.Header.Set(WorkspaceHeader, s.workspace)
+	}
+	s.router.ServeHTTP(w, req)
+
+	if rep != nil {
+		s.NoError(json.NewDecoder(w.Result().Body).Decode(rep))
+	}
+	return w.Code
+}
@@ -0,0 +1,407 @@
+package todos
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	errTrashedParent    = errors.New("the parent of the task is in the trash and must be restored first")
+	errTrashedChecklist = errors.New("the checklist of the task is in the trash and must be restored first")
+)
+
+//===========================================================================
+// Viewset for Trash objects
+//===========================================================================
+
+// ListTrash returns the tasks and checklists of the user in the trash of the active
+// workspace, most recently deleted first. Tasks whose parent or checklist is also in the
+// trash are not listed, since they are restored along with it. Editors of a shared
+// checklist can delete its tasks, so the tasks of the checklist in the trash are also
+// listed for them; only the owner can delete the checklist itself.
+func (s *API) ListTrash(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace := activeWorkspace(c)
+	rep := ListTrashResponse{Success: true}
+
+	trashedTasks := s.db.Table("tasks").Select("id").Where("deleted_at IS NOT NULL").SubQuery()
+	trashedLists := s.db.Table("checklists").Select("id").Where("deleted_at IS NOT NULL").SubQuery()
+
+	err := inWorkspace(s.db.Unscoped(), "tasks", workspace).
+		Where("tasks.user_id = ? OR tasks.checklist_id IN ?", user.ID, editableChecklists(s.db, user.ID)).
+		Where("tasks.deleted_at IS NOT NULL").
+		Where("tasks.parent_id IS NULL OR tasks.parent_id NOT IN ?", trashedTasks).
+		Where("tasks.checklist_id IS NULL OR tasks.checklist_id NOT IN ?", trashedLists).
+		Order("tasks.deleted_at DESC").Order("tasks.id").
+		Find(&rep.Tasks).Error
+	if err != nil {
+		logger.Printf("could not fetch trashed tasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	err = inWorkspace(s.db.Unscoped(), "checklists", workspace).
+		Where("checklists.user_id = ? AND checklists.deleted_at IS NOT NULL", user.ID).
+		Order("checklists.deleted_at DESC").Order("checklists.id").
+		Find(&rep.Checklists).Error
+	if err != nil {
+		logger.Printf("could not fetch trashed checklists: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, rep)
+}
+
+// RestoreTask restores the task from the trash along with the subtasks that were moved
+// to the trash with it. A task cannot be restored while its parent or checklist is in
+// the trash; the parent or checklist must be restored instead. Restored tasks are placed
+// at the end of their checklist.
+func (s *API) RestoreTask(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.trashedTask(c, user)
+	if !ok {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var trashed bool
+		if task.ParentID != nil {
+			if trashed, err = isTrashed(tx, &Task{}, *task.ParentID); err != nil {
+				logger.Printf("could not check parent task: %s", err)
+				return errInternal
+			}
+			if trashed {
+				return errTrashedParent
+			}
+		}
+
+		if task.ChecklistID != nil {
+			if trashed, err = isTrashed(tx, &Checklist{}, *task.ChecklistID); err != nil {
+				logger.Printf("could not check checklist: %s", err)
+				return errInternal
+			}
+			if trashed {
+				return errTrashedChecklist
+			}
+		}
+
+		var ids []uint
+		if ids, err = trashedSubtasks(tx, task); err != nil {
+			logger.Printf("could not find trashed subtasks: %s", err)
+			return errInternal
+		}
+
+		ids = append([]uint{task.ID}, ids...)
+		if err = repositionTasks(tx, ids); err != nil {
+			logger.Printf("could not position restored tasks: %s", err)
+			return errInternal
+		}
+
+		if err = restoreTasks(tx, user.ID, ids); err != nil {
+			logger.Printf("could not restore tasks: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		switch err {
+		case errTrashedParent, errTrashedChecklist:
+			c.JSON(http.StatusConflict, ErrorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, RestoreTrashResponse{Success: true})
+}
+
+// RestoreChecklist restores the checklist from the trash along with the tasks that were
+// moved to the trash with it.
+func (s *API) RestoreChecklist(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	list, ok := s.trashedChecklist(c, user)
+	if !ok {
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var ids []uint
+		if err = tx.Unscoped().Model(&Task{}).Where("checklist_id = ? AND deleted_at = ?", list.ID, *list.DeletedAt).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if err = tx.Unscoped().Model(&list).UpdateColumn("deleted_at", gorm.Expr("NULL")).Error; err != nil {
+			return err
+		}
+
+		var after snapshot
+		if after, err = checklistSnapshot(tx, list.ID); err != nil {
+			return err
+		}
+
+		if err = recordHistory(tx, HistoryChecklist, list.ID, user.ID, HistoryRestore, nil, after); err != nil {
+			return err
+		}
+		return restoreTasks(tx, user.ID, ids)
+	})
+
+	if err != nil {
+		logger.Printf("could not restore checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, RestoreTrashResponse{Success: true})
+}
+
+// EmptyTrash permanently deletes the tasks and checklists of the user in the trash of
+// the active workspace, including their comments, time entries, and attachments. Only
+// the owner of a task can purge it, the tasks that editors have moved to the trash from
+// a shared checklist are kept until the owner empties the trash or they expire.
+func (s *API) EmptyTrash(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace := activeWorkspace(c)
+	rep := EmptyTrashResponse{Success: true}
+
+	var blobs []string
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		rep.Tasks, rep.Checklists, blobs, err = purgeTrash(tx, func(db *gorm.DB, table string) *gorm.DB {
+			return inWorkspace(db, table, workspace).Where(table+".user_id = ?", user.ID)
+		})
+		return err
+	})
+
+	if err != nil {
+		logger.Printf("could not empty trash: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	s.deleteBlobs(blobs...)
+	c.JSON(http.StatusOK, rep)
+}
+
+//===========================================================================
+// Trash Helpers
+//===========================================================================
+
+// trashedTask fetches the task specified by the id url parameter from the trash of the
+// active workspace if it belongs to the user or to a checklist the user can edit. If the
+// task is not in the trash, the not found response is written and false is returned.
+func (s *API) trashedTask(c *gin.Context, user User) (task Task, ok bool) {
+	query := inWorkspace(s.db.Unscoped(), "tasks", activeWorkspace(c)).
+		Where("tasks.user_id = ? OR tasks.checklist_id IN ?", user.ID, editableChecklists(s.db, user.ID))
+	if err := query.Where("id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&task).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return task, false
+		}
+		logger.Printf("could not find trashed task: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return task, false
+	}
+	return task, true
+}
+
+// trashedChecklist fetches the checklist specified by the id url parameter from the
+// trash of the active workspace if it belongs to the user. If the checklist is not in
+// the trash, the not found response is written and false is returned.
+func (s *API) trashedChecklist(c *gin.Context, user User) (list Checklist, ok bool) {
+	query := inWorkspace(s.db.Unscoped(), "checklists", activeWorkspace(c))
+	if err := query.Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", c.Param("id"), user.ID).First(&list).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return list, false
+		}
+		logger.Printf("could not find trashed checklist: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return list, false
+	}
+	return list, true
+}
+
+// isTrashed returns true if the task or checklist with the id is in the trash.
+func isTrashed(db *gorm.DB, model interface{}, id uint) (_ bool, err error) {
+	var count int
+	if err = db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// trashTasks moves the tasks to the trash. Tasks that are moved to the trash together
+// share the same deleted timestamp so that they can be restored together.
+func trashTasks(db *gorm.DB, ids []uint, now time.Time) error {
+	return db.Model(&Task{}).Where("id IN (?)", ids).UpdateColumn("deleted_at", now).Error
+}
+
+// trashChecklist moves the checklist and all of its tasks to the trash.
+func trashChecklist(db *gorm.DB, checklistID uint, now time.Time) (err error) {
+	if err = db.Model(&Task{}).Where("checklist_id = ?", checklistID).UpdateColumn("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return db.Model(&Checklist{}).Where("id = ?", checklistID).UpdateColumn("deleted_at", now).Error
+}
+
+// trashedSubtasks returns the ids of the descendants of the task that were moved to the
+// trash along with it.
+func trashedSubtasks(db *gorm.DB, task Task) (ids []uint, err error) {
+	level := []uint{task.ID}
+	for depth := 1; depth < maxTaskDepth && len(level) > 0; depth++ {
+		var children []uint
+		if err = db.Unscoped().Model(&Task{}).Where("parent_id IN (?) AND deleted_at = ?", level, *task.DeletedAt).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		level = children
+	}
+	return ids, nil
+}
+
+// restoreTasks restores the tasks from the trash and records their restoration in the
+// history of each task.
+func restoreTasks(db *gorm.DB, userID uint, ids []uint) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err = db.Unscoped().Model(&Task{}).Where("id IN (?)", ids).UpdateColumn("deleted_at", gorm.Expr("NULL")).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var after snapshot
+		if after, err = taskSnapshot(db, id); err != nil {
+			return err
+		}
+		if err = recordHistory(db, HistoryTask, id, userID, HistoryRestore, nil, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repositionTasks moves the tasks in the trash to the end of their checklists in order,
+// so that a task that is restored does not return to a position in the middle of tasks
+// that were reordered while it was in the trash.
+func repositionTasks(db *gorm.DB, ids []uint) (err error) {
+	for _, id := range ids {
+		var task Task
+		if err = db.Unscoped().Select("id, checklist_id").Where("id = ?", id).First(&task).Error; err != nil {
+			return err
+		}
+
+		if task.ChecklistID == nil {
+			continue
+		}
+
+		if err = lockChecklist(db, *task.ChecklistID); err != nil {
+			return err
+		}
+
+		var position int64
+		if position, err = nextPosition(db, *task.ChecklistID); err != nil {
+			return err
+		}
+
+		if err = db.Unscoped().Model(&Task{}).Where("id = ?", id).UpdateColumn("position", position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeTrash permanently deletes the checklists and tasks in the trash that are selected
+// by the filter, which is applied to the query of each table. It returns the number of
+// tasks and checklists that were deleted and the keys of their attachments so that the
+// contents can be removed from storage once the transaction is committed.
+func purgeTrash(db *gorm.DB, filter func(db *gorm.DB, table string) *gorm.DB) (tasks, lists int, blobs []string, err error) {
+	var taskIDs, listIDs []uint
+	if err = filter(db.Unscoped().Model(&Checklist{}), "checklists").Where("checklists.deleted_at IS NOT NULL").Pluck("checklists.id", &listIDs).Error; err != nil {
+		return 0, 0, nil, err
+	}
+
+	// Tasks of the checklists are excluded since they are deleted with their checklist
+	query := filter(db.Unscoped().Model(&Task{}), "tasks").Where("tasks.deleted_at IS NOT NULL")
+	if len(listIDs) > 0 {
+		query = query.Where("tasks.checklist_id IS NULL OR tasks.checklist_id NOT IN (?)", listIDs)
+	}
+
+	if err = query.Pluck("tasks.id", &taskIDs).Error; err != nil {
+		return 0, 0, nil, err
+	}
+
+	// All tasks of the checklists are deleted with them, as though they had cascaded
+	var listTasks []uint
+	if len(listIDs) > 0 {
+		if err = db.Unscoped().Model(&Task{}).Where("checklist_id IN (?)", listIDs).Pluck("id", &listTasks).Error; err != nil {
+			return 0, 0, nil, err
+		}
+
+		if blobs, err = purgeTasks(db, listTasks); err != nil {
+			return 0, 0, nil, err
+		}
+
+		if err = db.Where("checklist_id IN (?)", listIDs).Delete(&ChecklistShare{}).Error; err != nil {
+			return 0, 0, nil, err
+		}
+
+		fields := db.Table("custom_fields").Select("id").Where("checklist_id IN (?)", listIDs)
+		if err = db.Where("field_id IN ?", fields.SubQuery()).Delete(&FieldValue{}).Error; err != nil {
+			return 0, 0, nil, err
+		}
+
+		if err = db.Where("checklist_id IN (?)", listIDs).Delete(&CustomField{}).Error; err != nil {
+			return 0, 0, nil, err
+		}
+
+		if err = db.Unscoped().Where("id IN (?)", listIDs).Delete(&Checklist{}).Error; err != nil {
+			return 0, 0, nil, err
+		}
+	}
+
+	var taskBlobs []string
+	if taskBlobs, err = purgeTasks(db, taskIDs); err != nil {
+		return 0, 0, nil, err
+	}
+	return len(listTasks) + len(taskIDs), len(listIDs), append(blobs, taskBlobs...), nil
+}
+
+// purgeTasks permanently deletes the tasks along with their tags, dependencies,
+// comments, time entries, custom field values, and attachments, returning the keys of
+// the attachments.
+func purgeTasks(db *gorm.DB, ids []uint) (blobs []string, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if err = db.Model(&Attachment{}).Where("task_id IN (?)", ids).Pluck("key", &blobs).Error; err != nil {
+		return nil, err
+	}
+
+	for _, model := range []interface{}{&Attachment{}, &Comment{}, &TimeEntry{}, &FieldValue{}} {
+		if err = db.Where("task_id IN (?)", ids).Delete(model).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err = db.Where("task_id IN (?) OR blocker_id IN (?)", ids, ids).Delete(&Dependency{}).Error; err != nil {
+		return nil, err
+	}
+
+	if err = db.Exec("DELETE FROM task_tags WHERE task_id IN (?)", ids).Error; err != nil {
+		return nil, err
+	}
+
+	if err = db.Unscoped().Where("id IN (?)", ids).Delete(&Task{}).Error; err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
@@ -0,0 +1,178 @@
+package todos_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestTrash() {
+	// Start with an empty trash so that the tasks deleted by other tests are not listed
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, "/v1/trash", nil, nil))
+
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "garden"}, &list))
+
+	var weeds, seeds CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "pull weeds", ChecklistID: &list.ChecklistID}, &weeds))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "plant seeds", ChecklistID: &list.ChecklistID}, &seeds))
+
+	var parent, child CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "compost"}, &parent))
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "turn pile", ParentID: &parent.TaskID}, &child))
+
+	parentPath := fmt.Sprintf("/v1/tasks/%d", parent.TaskID)
+	childPath := fmt.Sprintf("/v1/tasks/%d", child.TaskID)
+	listPath := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+
+	// Deleted tasks are moved to the trash with their subtasks and are no longer visible
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, parentPath+"?cascade=true", nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, parentPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, childPath, nil, nil))
+
+	var trash ListTrashResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/trash", nil, &trash))
+	require.Equal(s.T(), []uint{parent.TaskID}, taskIDs(trash.Tasks))
+	require.NotNil(s.T(), trash.Tasks[0].DeletedAt)
+
+	// Subtasks cannot be restored without their parent, which restores them both
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", child.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", parent.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", parent.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, childPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", parent.TaskID), nil, nil))
+
+	// Deleted checklists are moved to the trash with their tasks
+	weedsPath := fmt.Sprintf("/v1/tasks/%d", weeds.TaskID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, weedsPath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, listPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, listPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", seeds.TaskID), nil, nil))
+
+	trash = ListTrashResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/trash", nil, &trash))
+	require.Empty(s.T(), trash.Tasks)
+	require.Len(s.T(), trash.Checklists, 1)
+	require.Equal(s.T(), list.ChecklistID, trash.Checklists[0].ID)
+
+	// Restoring the checklist only restores the tasks that were moved to the trash with it
+	require.Equal(s.T(), http.StatusConflict, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", seeds.TaskID), nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/lists/%d/restore", list.ChecklistID), nil, nil))
+
+	var detail DetailChecklistResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, listPath, nil, &detail))
+	require.Equal(s.T(), []uint{seeds.TaskID}, taskIDs(detail.Checklist.Tasks))
+
+	trash = ListTrashResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/trash", nil, &trash))
+	require.Equal(s.T(), []uint{weeds.TaskID}, taskIDs(trash.Tasks))
+
+	// Items are purged from the trash once they are older than the retention period
+	storage, err := NewLocalStorage(s.conf.StorageDir)
+	require.NoError(s.T(), err)
+
+	rows, err := TrashCleanup(s.api.DB(), storage, time.Hour)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), rows)
+
+	rows, err = TrashCleanup(s.api.DB(), storage, 0)
+	require.NoError(s.T(), err)
+	require.NotZero(s.T(), rows)
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", weeds.TaskID), nil, nil))
+
+	// Emptying the trash permanently deletes the checklist and all of its tasks
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, parentPath+"?cascade=true", nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, listPath, nil, nil))
+
+	var empty EmptyTrashResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, "/v1/trash", nil, &empty))
+	require.Equal(s.T(), 3, empty.Tasks)
+	require.Equal(s.T(), 1, empty.Checklists)
+
+	trash = ListTrashResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/trash", nil, &trash))
+	require.Empty(s.T(), trash.Tasks)
+	require.Empty(s.T(), trash.Checklists)
+
+	var count int
+	require.NoError(s.T(), s.api.DB().Unscoped().Model(&Task{}).Where("id IN (?)", []uint{seeds.TaskID, parent.TaskID, child.TaskID}).Count(&count).Error)
+	require.Zero(s.T(), count)
+}
+
+func (s *TodosTestSuite) TestTrashPositions() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "errands"}, &list))
+
+	create := func(title string) uint {
+		var rep CreateTaskResponse
+		require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: title, ChecklistID: &list.ChecklistID}, &rep))
+		return rep.TaskID
+	}
+
+	order := func() []uint {
+		var rep DetailChecklistResponse
+		require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, &rep))
+		return taskIDs(rep.Checklist.Tasks)
+	}
+
+	bank, mail := create("go to the bank"), create("mail letters")
+
+	// The position of a task in the trash is not reused by a new task in the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", mail), nil, nil))
+	groceries := create("buy groceries")
+	require.Equal(s.T(), []uint{bank, groceries}, order())
+
+	// Reordering and moving tasks does not collide with the tasks in the trash
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/lists/%d/order", list.ChecklistID), ReorderChecklistRequest{Tasks: []uint{groceries, bank}}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, fmt.Sprintf("/v1/tasks/%d/move", bank), MoveTaskRequest{Before: groceries}, nil))
+	require.Equal(s.T(), []uint{bank, groceries}, order())
+
+	// Restored tasks are placed at the end of their checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, fmt.Sprintf("/v1/trash/tasks/%d/restore", mail), nil, nil))
+	require.Equal(s.T(), []uint{bank, groceries, mail}, order())
+	pharmacy := create("pick up prescription")
+	require.Equal(s.T(), []uint{bank, groceries, mail, pharmacy}, order())
+
+	// Clean up so that other tests are not affected by the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", list.ChecklistID), nil, nil))
+}
+
+func (s *TodosTestSuite) TestSharedTrash() {
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "office"}, &list))
+	listPath := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "order toner", ChecklistID: &list.ChecklistID}, &task))
+	restorePath := fmt.Sprintf("/v1/trash/tasks/%d/restore", task.TaskID)
+
+	// Viewers cannot see the tasks of the checklist in the trash
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, listPath+"/shares", ShareChecklistRequest{Username: "admin", Role: RoleViewer}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", task.TaskID), nil, nil))
+
+	var trash ListTrashResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/trash", nil, &trash))
+	require.NotContains(s.T(), taskIDs(trash.Tasks), task.TaskID)
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodPost, restorePath, nil, nil))
+
+	// Editors can see and restore the tasks of the checklist that are in the trash
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, listPath+"/shares", ShareChecklistRequest{Username: "admin", Role: RoleEditor}, nil))
+	trash = ListTrashResponse{}
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/trash", nil, &trash))
+	require.Contains(s.T(), taskIDs(trash.Tasks), task.TaskID)
+	require.Empty(s.T(), trash.Checklists)
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodPost, restorePath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, fmt.Sprintf("/v1/tasks/%d", task.TaskID), nil, nil))
+
+	// Only the owner can empty the trash of the tasks that editors deleted
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", task.TaskID), nil, nil))
+
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodDelete, "/v1/trash", nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPost, restorePath, nil, nil))
+
+	// Clean up so that other tests are not affected by the checklist
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, listPath, nil, nil))
+}
@@ -2,6 +2,8 @@ package todos
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
@@ -9,28 +11,73 @@ import (
 
 // Context keys for middleware lookups
 const (
-	ctxUserKey = "user"
+	ctxUserKey      = "user"
+	ctxWorkspaceKey = "workspace"
 )
 
 // Overview returns statistics for the authenticated user, e.g. how many tasks and lists
-// are currently open, completed, and archived. Although this is the root view of the
-// API, this view requires an authenticated user in the context.
+// are currently open, completed, and archived in the active workspace. Although this is
+// the root view of the API, this view requires an authenticated user in the context.
 func (s *API) Overview(c *gin.Context) {
 	user := c.Value(ctxUserKey).(User)
 	rep := OverviewResponse{Success: true, User: user.Username}
 
-	if err := s.db.Where("user_id = ?", user.ID).Find(&user.Tasks).Count(&rep.Tasks).Error; err != nil {
+	workspace := activeWorkspace(c)
+	if workspace != nil {
+		var active Workspace
+		if err := s.db.Select("name").Where("id = ?", *workspace).First(&active).Error; err != nil {
+			logger.Printf("could not fetch active workspace: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		rep.Workspace = active.Name
+	}
+
+	if err := inWorkspace(s.db, "tasks", workspace).Where("user_id = ?", user.ID).Find(&user.Tasks).Count(&rep.Tasks).Error; err != nil {
 		logger.Printf("could not count tasks for user: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
 
-	if err := s.db.Where("user_id = ?", user.ID).Find(&user.Lists).Count(&rep.Checklists).Error; err != nil {
+	if err := inWorkspace(s.db, "checklists", workspace).Where("user_id = ?", user.ID).Find(&user.Lists).Count(&rep.Checklists).Error; err != nil {
 		logger.Printf("could not count checklists for user: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
 
+	var err error
+	if rep.Assigned, err = assignedTasks(s.db, user.ID, workspace); err != nil {
+		logger.Printf("could not count assigned tasks for user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if rep.OpenEffort, err = openEffort(s.db, user.ID, workspace); err != nil {
+		logger.Printf("could not compute open effort for user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	var views []SavedView
+	if err = s.db.Where("user_id = ?", user.ID).Find(&views).Error; err != nil {
+		logger.Printf("could not fetch saved views for user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err = countViews(s.db, user.ID, workspace, views, time.Now()); err != nil {
+		logger.Printf("could not count saved view tasks for user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if len(views) > 0 {
+		rep.Views = make(map[string]int, len(views))
+		for _, view := range views {
+			rep.Views[view.Name] = view.Count
+		}
+	}
+
 	c.JSON(http.StatusOK, rep)
 }
 
@@ -38,10 +85,15 @@ func (s *API) Overview(c *gin.Context) {
 // Viewset for Task objects
 //===========================================================================
 
-// ListTasks returns all tasks for the authenticated user, sorted and filtered by the
-// specified input parameters (e.g. by list or by most recent).
-// TODO: add filtering by list
-// TODO: add pagination
+// ListTasks returns all tasks for the authenticated user, including the tasks of the
+// checklists shared with them, sorted and filtered by the specified input parameters
+// (e.g. by list or by most recent). By default tasks are ordered by priority with the
+// most urgent tasks first, then by deadline with the nearest deadlines first; tasks
+// without deadlines are ordered last. If a checklist is specified, only its tasks are
+// returned in the order of their position in the list. Tasks that are deferred until a
+// future start date are hidden unless requested and tasks can be filtered by status,
+// dates, title, assignment, or the values of their custom fields. Tasks are paginated
+// by page number or by cursor.
 func (s *API) ListTasks(c *gin.Context) {
 	var req ListTasksRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -49,18 +101,95 @@ func (s *API) ListTasks(c *gin.Context) {
 		return
 	}
 
+	s.listTasks(c, req)
+}
+
+// listTasks responds with the page of tasks that match the list tasks request so that
+// saved views return their tasks in the same way as the task list.
+func (s *API) listTasks(c *gin.Context, req ListTasksRequest) {
 	user := c.Value(ctxUserKey).(User)
+	query, err := filterTaskList(s.db, visibleTasks(s.db.Preload("Tags"), user.ID, activeWorkspace(c)), user.ID, req, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// Tasks are sorted by the requested keys or by the default order of the list
+	order, err := parseTaskOrder(req.Sort, req.Checklist > 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	pages, err := newPagination(req.Page, req.PerPage, req.Cursor, order.String())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if query, err = order.Apply(query, pages.Cursor()); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if query, err = pages.Apply(query, &Task{}); err != nil {
+		logger.Printf("could not count tasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
 	var tasks []Task
-	if err := s.db.Where("user_id = ?", user.ID).Find(&tasks).Error; err != nil {
+	if err := query.Find(&tasks).Error; err != nil {
 		logger.Printf("could not fetch tasks: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, ListTasksResponse{Success: true, Tasks: tasks})
+	var next string
+	if pages.More(len(tasks)) {
+		if len(tasks) > pages.PerPage {
+			tasks = tasks[:pages.PerPage]
+		}
+		next = order.Cursor(tasks[len(tasks)-1]).Encode()
+	}
+
+	if err := subtaskRollups(s.db, tasks); err != nil {
+		logger.Printf("could not compute subtask rollups: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadBlockers(s.db, tasks); err != nil {
+		logger.Printf("could not fetch dependencies: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := commentCounts(s.db, tasks); err != nil {
+		logger.Printf("could not count comments: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadAssignees(s.db, tasks); err != nil {
+		logger.Printf("could not fetch assignees: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadFieldValues(s.db, tasks); err != nil {
+		logger.Printf("could not fetch custom field values: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	pages.Link(c, next)
+	c.JSON(http.StatusOK, ListTasksResponse{Success: true, Tasks: tasks, Page: pages.Page, NumPages: pages.NumPages, NextCursor: next})
 }
 
-// CreateTask creates a new task assigned to the authenticated user in the database.
+// CreateTask creates a new task owned by the authenticated user in the database. The
+// task can be assigned by username to any user who can see it and can store values for
+// the custom fields of its checklist by field name.
 func (s *API) CreateTask(c *gin.Context) {
 	// Parse the user input
 	task := Task{}
@@ -69,30 +198,165 @@ func (s *API) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Add the user to the task
+	// Validate the task input
+	if err := task.Priority.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if task.Repeat != "" {
+		rule, err := ParseRecurrence(task.Repeat)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse(err))
+			return
+		}
+		task.Repeat = rule.String()
+	}
+
+	// Add the user and the active workspace to the task
 	user := c.Value(ctxUserKey).(User)
 	task.UserID = user.ID
+	task.WorkspaceID = activeWorkspace(c)
+
+	// Subtasks must be created individually, referencing their parent
+	task.Children = nil
+
+	// New tasks are always positioned at the end of their checklist by the server
+	task.Position = 0
+
+	// The assignee is specified by username and validated once the checklist is known
+	assignee := task.Assignee
+	task.AssigneeID = nil
+
+	// Custom field values are validated against the fields of the checklist
+	fields := task.Fields
+	task.Fields = nil
+
+	// Completion, archive, and trash timestamps are set by the server
+	task.CompletedAt, task.ArchivedAt = closedAt(task.Completed), closedAt(task.Archived)
+	task.DeletedAt = nil
+
+	// Create the task and its tags in the database
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		// New tasks are positioned at the end of their checklist by the create hook. Tasks
+		// created by editors of a shared checklist belong to the owner of the checklist.
+		if task.ChecklistID != nil {
+			var list Checklist
+			if list, err = requireChecklistRole(tx, user.ID, task.WorkspaceID, *task.ChecklistID, RoleEditor); err != nil {
+				if err == errChecklistMissing || err == errForbidden {
+					return err
+				}
+				logger.Printf("could not find checklist: %s", err)
+				return errInternal
+			}
+			task.UserID = list.UserID
+		}
 
-	// Create the task in the database
-	if err := s.db.Create(&task).Error; err != nil {
-		logger.Printf("could not create task: %s", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		if task.ParentID != nil {
+			if err = validateParent(tx, &task, *task.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if err = assignTask(tx, &task, assignee); err != nil {
+			return err
+		}
+
+		var tags []Tag
+		if tags, err = resolveTags(tx, task.UserID, task.Tags); err != nil {
+			return err
+		}
+
+		task.Tags = nil
+		if err = tx.Create(&task).Error; err != nil {
+			logger.Printf("could not create task: %s", err)
+			return errInternal
+		}
+
+		if len(tags) > 0 {
+			if err = tx.Model(&task).Association("Tags").Replace(tags).Error; err != nil {
+				logger.Printf("could not tag task: %s", err)
+				return errInternal
+			}
+		}
+
+		if err = setFieldValues(tx, task, fields); err != nil {
+			return err
+		}
+
+		if err = recordTaskCreate(tx, task.ID, user.ID); err != nil {
+			logger.Printf("could not record task history: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		switch err {
+		case errInternal:
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		case errForbidden:
+			c.JSON(http.StatusForbidden, ErrorResponse(err))
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		}
 		return
 	}
 
 	c.JSON(http.StatusCreated, CreateTaskResponse{Success: true, TaskID: task.ID})
 }
 
-// DetailTask returns as much information about the task as possible.
-// TODO: ensure that the task belongs to the user!
+// DetailTask returns as much information about the task as possible, including the
+// tree of subtasks nested beneath it and the tasks that block it. The task must belong
+// to the user or be in a checklist that is shared with the user.
 func (s *API) DetailTask(c *gin.Context) {
-	var task Task
-	if err := s.db.Where("id = ?", c.Param("id")).First(&task).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
-			return
-		}
-		logger.Printf("could not find task: %s", err)
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Model(&task).Association("Tags").Find(&task.Tags).Error; err != nil {
+		logger.Printf("could not fetch task tags: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadSubtasks(s.db, &task, 1); err != nil {
+		logger.Printf("could not load subtasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	detail := []Task{task}
+	if err := loadBlockers(s.db, detail); err != nil {
+		logger.Printf("could not fetch dependencies: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := commentCounts(s.db, detail); err != nil {
+		logger.Printf("could not count comments: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadAssignees(s.db, detail); err != nil {
+		logger.Printf("could not fetch assignees: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadFieldValues(s.db, detail); err != nil {
+		logger.Printf("could not fetch custom field values: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+	task = detail[0]
+
+	var err error
+	if task.TimeTracked, err = trackedTime(s.db.Table("time_entries").Where("time_entries.task_id = ?", task.ID)); err != nil {
+		logger.Printf("could not compute tracked time: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
@@ -100,52 +364,289 @@ func (s *API) DetailTask(c *gin.Context) {
 	c.JSON(http.StatusOK, DetailTaskResponse{Success: true, Task: task})
 }
 
-// UpdateTask allows the user to modify a task.
-// TODO: ensure that the task belongs to the user!
+// UpdateTask allows the user to modify a task with a JSON merge patch (RFC 7396): only
+// the fields in the patch are modified, null clears fields such as the deadline or the
+// checklist, and fields that cannot be modified are rejected. The task can be moved in
+// the subtask hierarchy by specifying a new parent (or null to make it a top level
+// task). When a task is archived, all of its subtasks are archived along with it;
+// however restoring a task from the archive does not restore its subtasks. Completing a
+// repeating task creates the next occurrence of the task, whose id is returned in the
+// response. A task cannot be completed while any of the tasks that block it are still
+// open. The time the task is completed or archived is recorded and cleared when it is
+// reopened. Only owners and editors of the task's checklist can update it.
 func (s *API) UpdateTask(c *gin.Context) {
 	// Fetch the task to update
-	task := Task{}
-	if err := s.db.Where("id = ?", c.Param("id")).First(&task).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Parse the merge patch, only the fields in the allow-list of tasks can be updated
+	patch, err := bindMergePatch(c)
+	if err != nil {
+		if err == errMergePatchType {
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse(err))
 			return
 		}
-		logger.Printf("could not find task: %s", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
 
-	// Parse the user input
-	// In order to set zero values (e.g. completed/archived as false) input needs to be a map not a struct
-	var input map[string]interface{}
-	if err := c.ShouldBind(&input); err != nil {
+	input, err := taskPatch.Parse(patch)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
 
-	if err := s.db.Model(&task).Update(input).Error; err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+	// Validate the parent when moving the task in the hierarchy; null removes the parent
+	updateParent := false
+	var parentID *uint
+	if val, ok := input["parent_id"]; ok {
+		parentID, updateParent = val.(*uint), true
+		delete(input, "parent_id")
+	}
+
+	// Moving the task to another checklist places it at the end of that list; positions
+	// within a checklist can only be changed by moving or reordering tasks.
+	updateChecklist := false
+	var checklistID *uint
+	if val, ok := input["checklist_id"]; ok {
+		checklistID, updateChecklist = val.(*uint), true
+		delete(input, "checklist_id")
+	}
+
+	// The assignee is specified by username, null unassigns the task
+	updateAssignee := false
+	var assignee string
+	if val, ok := input["assignee"]; ok {
+		assignee, updateAssignee = val.(string), true
+		delete(input, "assignee")
+	}
+
+	// Tags are an association and must be replaced rather than updated as a field
+	var tags []Tag
+	updateTags := false
+	if val, ok := input["tags"]; ok {
+		tags, updateTags = val.([]Tag), true
+		delete(input, "tags")
+	}
+
+	// Custom field values are validated once the checklist of the task is known
+	var fields map[string]interface{}
+	if val, ok := input["fields"]; ok {
+		fields = val.(map[string]interface{})
+		delete(input, "fields")
+	}
+
+	// Completion and archive timestamps are set by the server when the state changes
+	if completed, ok := input["completed"].(bool); ok && completed != task.Completed {
+		input["completed_at"] = closedAt(completed)
+	}
+
+	if archived, ok := input["archived"].(bool); ok && archived != task.Archived {
+		input["archived_at"] = closedAt(archived)
+	}
+
+	// Tasks cannot be completed while the tasks they depend on are still open
+	var next *Task
+	wasCompleted := task.Completed
+	if completed, ok := input["completed"].(bool); ok && completed && !wasCompleted {
+		blocked, err := isBlocked(s.db, task.ID)
+		if err != nil {
+			logger.Printf("could not check dependencies: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+
+		if blocked {
+			c.JSON(http.StatusConflict, ErrorResponse(errBlockedTask))
+			return
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) (err error) {
+		// Capture the fields of the task before the update to record the changes
+		var before snapshot
+		if before, err = taskSnapshot(tx, task.ID); err != nil {
+			logger.Printf("could not snapshot task: %s", err)
+			return errInternal
+		}
+
+		if updateTags {
+			if tags, err = resolveTags(tx, task.UserID, tags); err != nil {
+				return err
+			}
+
+			if err = tx.Model(&task).Association("Tags").Replace(tags).Error; err != nil {
+				logger.Printf("could not tag task: %s", err)
+				return errInternal
+			}
+		}
+
+		if updateParent {
+			if parentID != nil {
+				if err = validateParent(tx, &task, *parentID); err != nil {
+					return err
+				}
+			}
+
+			if err = tx.Model(&task).Update("parent_id", parentID).Error; err != nil {
+				logger.Printf("could not update task parent: %s", err)
+				return errInternal
+			}
+		}
+
+		if updateChecklist && !sameChecklist(task.ChecklistID, checklistID) {
+			if checklistID != nil {
+				if _, err = requireChecklistRole(tx, user.ID, task.WorkspaceID, *checklistID, RoleEditor); err != nil {
+					if err == errChecklistMissing || err == errForbidden {
+						return err
+					}
+					logger.Printf("could not find checklist: %s", err)
+					return errInternal
+				}
+			}
+
+			if err = moveToChecklist(tx, &task, checklistID); err != nil {
+				if err == errChecklistMissing {
+					return err
+				}
+				logger.Printf("could not move task to checklist: %s", err)
+				return errInternal
+			}
+
+			// Values of fields that are not defined by the new checklist are removed
+			if err = clearFieldValues(tx, task.ID, task.ChecklistID); err != nil {
+				logger.Printf("could not clear field values: %s", err)
+				return errInternal
+			}
+		}
+
+		if err = setFieldValues(tx, task, fields); err != nil {
+			return err
+		}
+
+		// The assignee must be able to see the task once it has been moved
+		if updateAssignee {
+			if err = assignTask(tx, &task, assignee); err != nil {
+				return err
+			}
+			input["assignee_id"] = task.AssigneeID
+		}
+
+		if len(input) > 0 {
+			if err = tx.Model(&task).Update(input).Error; err != nil {
+				logger.Printf("could not update task: %s", err)
+				return errInternal
+			}
+		}
+
+		// Create the next occurrence when a repeating task is completed
+		if completed, ok := input["completed"].(bool); ok && completed && !wasCompleted {
+			if next, err = nextOccurrence(tx, task.ID); err != nil {
+				logger.Printf("could not create next occurrence: %s", err)
+				return errInternal
+			}
+
+			if next != nil {
+				if err = recordTaskCreate(tx, next.ID, user.ID); err != nil {
+					logger.Printf("could not record task history: %s", err)
+					return errInternal
+				}
+			}
+		}
+
+		// Cascade archiving the task to all of its subtasks
+		if archived, ok := input["archived"].(bool); ok && archived {
+			if err = archiveSubtasks(tx, task.ID, user.ID); err != nil {
+				logger.Printf("could not archive subtasks: %s", err)
+				return errInternal
+			}
+		}
+
+		var after snapshot
+		if after, err = taskSnapshot(tx, task.ID); err != nil {
+			logger.Printf("could not snapshot task: %s", err)
+			return errInternal
+		}
+
+		if err = recordHistory(tx, HistoryTask, task.ID, user.ID, HistoryUpdate, before, after); err != nil {
+			logger.Printf("could not record task history: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		switch err {
+		case errInternal:
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		case errForbidden:
+			c.JSON(http.StatusForbidden, ErrorResponse(err))
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, UpdateTaskResponse{Success: true})
+	rep := UpdateTaskResponse{Success: true}
+	if next != nil {
+		rep.NextTaskID = next.ID
+	}
+	c.JSON(http.StatusOK, rep)
 }
 
-// DeleteTask removes the task from the database.
-// TODO: ensure that the task belongs to the user!
+// DeleteTask moves the task to the trash, from which it can be restored until it is
+// purged. If the task has subtasks, the delete is refused with a conflict unless the
+// cascade query parameter is set to true, in which case the task and all of its
+// subtasks are moved to the trash together. Only owners and editors of the task's
+// checklist can delete it.
 func (s *API) DeleteTask(c *gin.Context) {
-	var task Task
-	if err := s.db.Where("id = ?", c.Param("id")).First(&task).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
+	user := c.Value(ctxUserKey).(User)
+	task, ok := s.userTask(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	cascade := false
+	if param := c.Query("cascade"); param != "" {
+		var err error
+		if cascade, err = strconv.ParseBool(param); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse(err))
 			return
 		}
-		logger.Printf("could not find task: %s", err)
+	}
+
+	ids, err := descendants(s.db, task.ID)
+	if err != nil {
+		logger.Printf("could not find subtasks: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
 
-	if err := s.db.Delete(&task).Error; err != nil {
+	if len(ids) > 0 && !cascade {
+		c.JSON(http.StatusConflict, ErrorResponse(errHasSubtasks))
+		return
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) (err error) {
+		// The tags, dependencies, comments, time entries, and attachments of the tasks are
+		// kept so that they are restored along with the tasks.
+		ids = append([]uint{task.ID}, ids...)
+		for _, id := range ids {
+			var before snapshot
+			if before, err = taskSnapshot(tx, id); err != nil {
+				return err
+			}
+			if err = recordHistory(tx, HistoryTask, id, user.ID, HistoryDelete, before, nil); err != nil {
+				return err
+			}
+		}
+		return trashTasks(tx, ids, time.Now())
+	})
+
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
@@ -157,8 +658,9 @@ func (s *API) DeleteTask(c *gin.Context) {
 // Viewset for List objects
 //===========================================================================
 
-// ListChecklists returns all checklists that belong to the authenticated user.
-// TODO: add pagination
+// ListChecklists returns all checklists that belong to the authenticated user or are
+// shared with them along with their progress, optionally filtered by project. The
+// checklists are ordered by id and paginated by page number or by cursor.
 func (s *API) ListChecklists(c *gin.Context) {
 	var req ListChecklistsRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -168,14 +670,56 @@ func (s *API) ListChecklists(c *gin.Context) {
 
 	var lists []Checklist
 	user := c.Value(ctxUserKey).(User)
+	query := visibleChecklists(s.db, user.ID, activeWorkspace(c))
 
-	if err := s.db.Where("user_id = ?", user.ID).Find(&lists).Error; err != nil {
+	if req.Project > 0 {
+		query = query.Where("project_id = ?", req.Project)
+	}
+
+	pages, err := newPagination(req.Page, req.PerPage, req.Cursor, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if cursor := pages.Cursor(); cursor != nil {
+		query = query.Where("checklists.id > ?", cursor.ID)
+	}
+
+	if query, err = pages.Apply(query.Order("checklists.id"), &Checklist{}); err != nil {
+		logger.Printf("could not count checklists: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := query.Find(&lists).Error; err != nil {
 		logger.Printf("could not fetch checklists: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, ListChecklistsResponse{Success: true, Checklists: lists})
+	var next string
+	if pages.More(len(lists)) {
+		if len(lists) > pages.PerPage {
+			lists = lists[:pages.PerPage]
+		}
+		next = pageCursor{Order: "id", ID: lists[len(lists)-1].ID}.Encode()
+	}
+
+	if err := checklistProgress(s.db, lists); err != nil {
+		logger.Printf("could not compute checklist progress: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := checklistRoles(s.db, user.ID, lists); err != nil {
+		logger.Printf("could not fetch checklist roles: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	pages.Link(c, next)
+	c.JSON(http.StatusOK, ListChecklistsResponse{Success: true, Checklists: lists, Page: pages.Page, NumPages: pages.NumPages, NextCursor: next})
 }
 
 // CreateChecklist creates a new grouping of tasks for the user.
@@ -187,12 +731,40 @@ func (s *API) CreateChecklist(c *gin.Context) {
 		return
 	}
 
-	// Add the user to the list
+	// Add the user and the active workspace to the list
 	user := c.Value(ctxUserKey).(User)
 	list.UserID = user.ID
+	list.WorkspaceID = activeWorkspace(c)
+	list.DeletedAt = nil
+
+	// Custom fields must be defined individually so that they are validated
+	list.Fields = nil
+
+	if list.ProjectID != nil {
+		if err := validateProject(s.db, user.ID, list.WorkspaceID, *list.ProjectID); err != nil {
+			if err == errInternal {
+				c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+				return
+			}
+			c.JSON(http.StatusBadRequest, ErrorResponse(err))
+			return
+		}
+	}
 
 	// Create the checklist in the database
-	if err := s.db.Create(&list).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Create(&list).Error; err != nil {
+			return err
+		}
+
+		var after snapshot
+		if after, err = checklistSnapshot(tx, list.ID); err != nil {
+			return err
+		}
+		return recordHistory(tx, HistoryChecklist, list.ID, user.ID, HistoryCreate, nil, after)
+	})
+
+	if err != nil {
 		logger.Printf("could not create list: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
@@ -201,16 +773,55 @@ func (s *API) CreateChecklist(c *gin.Context) {
 	c.JSON(http.StatusCreated, CreateChecklistResponse{Success: true, ChecklistID: list.ID})
 }
 
-// DetailChecklist gives as many details about the checklist as possible.
-// TODO: ensure that the list belongs to the user!
+// DetailChecklist gives as many details about the checklist as possible, including its
+// custom fields, its tasks in order, and the estimated effort remaining to complete
+// them. The checklist must belong to the user or be shared with them.
 func (s *API) DetailChecklist(c *gin.Context) {
-	var list Checklist
-	if err := s.db.Where("id = ?", c.Param("id")).First(&list).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
-			return
-		}
-		logger.Printf("could not find list: %s", err)
+	user := c.Value(ctxUserKey).(User)
+	list, role, ok := s.userChecklist(c, user, RoleViewer)
+	if !ok {
+		return
+	}
+	list.Role = role
+
+	if err := s.db.Where("checklist_id = ?", list.ID).Order("id").Find(&list.Fields).Error; err != nil {
+		logger.Printf("could not fetch custom fields: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := s.db.Where("checklist_id = ?", list.ID).Order("position").Order("id").Find(&list.Tasks).Error; err != nil {
+		logger.Printf("could not fetch checklist tasks: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadAssignees(s.db, list.Tasks); err != nil {
+		logger.Printf("could not fetch assignees: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	if err := loadFieldValues(s.db, list.Tasks); err != nil {
+		logger.Printf("could not fetch custom field values: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	detail := []Checklist{list}
+	if err := checklistProgress(s.db, detail); err != nil {
+		logger.Printf("could not compute checklist progress: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+	list = detail[0]
+
+	var err error
+	tracked := s.db.Table("time_entries").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.checklist_id = ?", list.ID)
+	if list.TimeTracked, err = trackedTime(tracked); err != nil {
+		logger.Printf("could not compute tracked time: %s", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
 		return
 	}
@@ -218,30 +829,78 @@ func (s *API) DetailChecklist(c *gin.Context) {
 	c.JSON(http.StatusOK, DetailChecklistResponse{Success: true, Checklist: list})
 }
 
-// UpdateChecklist modifies the database checklist.
-// TODO: ensure that the list belongs to the user!
+// UpdateChecklist modifies the database checklist with a JSON merge patch (RFC 7396),
+// in the same manner as tasks. Owners and editors can update the checklist but only the
+// owner can move it to another project.
 func (s *API) UpdateChecklist(c *gin.Context) {
 	// Fetch the list to update
-	list := Checklist{}
-	if err := s.db.Where("id = ?", c.Param("id")).First(&list).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
+	user := c.Value(ctxUserKey).(User)
+	list, role, ok := s.userChecklist(c, user, RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Parse the merge patch, only the fields in the allow-list of checklists can be updated
+	patch, err := bindMergePatch(c)
+	if err != nil {
+		if err == errMergePatchType {
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse(err))
 			return
 		}
-		logger.Printf("could not find checklist: %s", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
 
-	// Parse user input
-	// In order to set zero values, input needs to be a map, not a struct
-	var input map[string]interface{}
-	if err := c.ShouldBind(&input); err != nil {
+	input, err := checklistPatch.Parse(patch)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
 
-	if err := s.db.Model(&list).Update(input).Error; err != nil {
+	// Move the checklist to another project or remove it from its project
+	project, updateProject := input["project_id"]
+	if updateProject && role != RoleOwner {
+		c.JSON(http.StatusForbidden, ErrorResponse(errForbidden))
+		return
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var before, after snapshot
+		if before, err = checklistSnapshot(tx, list.ID); err != nil {
+			logger.Printf("could not snapshot checklist: %s", err)
+			return errInternal
+		}
+
+		if projectID, _ := project.(*uint); projectID != nil {
+			if err = validateProject(tx, user.ID, list.WorkspaceID, *projectID); err != nil {
+				return err
+			}
+		}
+
+		if len(input) > 0 {
+			if err = tx.Model(&list).Update(input).Error; err != nil {
+				logger.Printf("could not update checklist: %s", err)
+				return errInternal
+			}
+		}
+
+		if after, err = checklistSnapshot(tx, list.ID); err != nil {
+			logger.Printf("could not snapshot checklist: %s", err)
+			return errInternal
+		}
+
+		if err = recordHistory(tx, HistoryChecklist, list.ID, user.ID, HistoryUpdate, before, after); err != nil {
+			logger.Printf("could not record checklist history: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		if err == errInternal {
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
@@ -249,21 +908,46 @@ func (s *API) UpdateChecklist(c *gin.Context) {
 	c.JSON(http.StatusOK, UpdateChecklistResponse{Success: true})
 }
 
-// DeleteChecklist removes the checklist from the database and all associated tasks.
-// TODO: ensure the list belongs to the user!
+// DeleteChecklist moves the checklist and all of its tasks to the trash, from which they
+// can be restored together until they are purged. Only the owner of the checklist can
+// delete it.
 func (s *API) DeleteChecklist(c *gin.Context) {
-	var list Checklist
-	if err := s.db.Where("id = ?", c.Param("id")).First(&list).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			c.JSON(http.StatusNotFound, notFound)
-			return
-		}
-		logger.Printf("could not find checklist: %s", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+	user := c.Value(ctxUserKey).(User)
+	list, _, ok := s.userChecklist(c, user, RoleOwner)
+	if !ok {
 		return
 	}
 
-	if err := s.db.Delete(&list).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		var before snapshot
+		if before, err = checklistSnapshot(tx, list.ID); err != nil {
+			return err
+		}
+
+		if err = recordHistory(tx, HistoryChecklist, list.ID, user.ID, HistoryDelete, before, nil); err != nil {
+			return err
+		}
+
+		// The tasks moved to the trash with the checklist are deleted in their history
+		var ids []uint
+		if err = tx.Model(&Task{}).Where("checklist_id = ?", list.ID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if before, err = taskSnapshot(tx, id); err != nil {
+				return err
+			}
+			if err = recordHistory(tx, HistoryTask, id, user.ID, HistoryDelete, before, nil); err != nil {
+				return err
+			}
+		}
+
+		// Shares are kept so that the checklist is shared again when it is restored
+		return trashChecklist(tx, list.ID, time.Now())
+	})
+
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse(err))
 		return
 	}
@@ -0,0 +1,460 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+)
+
+// RoleMember is the role of users that can work in a workspace but cannot manage it;
+// workspace owners have the RoleOwner role.
+const RoleMember = "member"
+
+// WorkspaceHeader selects the active workspace of a request, overriding the workspace
+// selected by the token. A workspace id of 0 selects the personal space of the user.
+const WorkspaceHeader = "X-Workspace"
+
+var (
+	errNotMember           = errors.New("you are not a member of this workspace")
+	errInvalidWorkspace    = errors.New("workspace must be a valid workspace id")
+	errInvalidMemberRole   = errors.New("role must be owner or member")
+	errLastWorkspaceOwner  = errors.New("workspaces must have at least one owner")
+	errWorkspaceNotEmpty   = errors.New("workspaces with tasks or checklists cannot be deleted")
+	errCollaboratorMissing = errors.New("checklists in a workspace can only be shared with its members")
+)
+
+//===========================================================================
+// Viewset for Workspace objects
+//===========================================================================
+
+// ListWorkspaces returns the workspaces the authenticated user is a member of with
+// their role in each. Admin users are global server admins, so all workspaces are
+// returned to them.
+func (s *API) ListWorkspaces(c *gin.Context) {
+	var workspaces []Workspace
+	user := c.Value(ctxUserKey).(User)
+	query := s.db.Order("name").Order("id")
+	if !user.IsAdmin {
+		members := s.db.Table("workspace_members").Select("workspace_id").Where("user_id = ?", user.ID)
+		query = query.Where("id IN ?", members.SubQuery())
+	}
+
+	if err := query.Find(&workspaces).Error; err != nil {
+		logger.Printf("could not fetch workspaces: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	for i := range workspaces {
+		role, err := workspaceRole(s.db, user.ID, workspaces[i].ID)
+		if err != nil {
+			logger.Printf("could not fetch workspace role: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		workspaces[i].Role = role
+	}
+
+	c.JSON(http.StatusOK, ListWorkspacesResponse{Success: true, Workspaces: workspaces})
+}
+
+// CreateWorkspace creates a new workspace with the authenticated user as its owner.
+func (s *API) CreateWorkspace(c *gin.Context) {
+	// Parse the user input
+	workspace := Workspace{}
+	if err := c.ShouldBind(&workspace); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	// Members are added to the workspace by its owners
+	user := c.Value(ctxUserKey).(User)
+	workspace.ID = 0
+	workspace.Members = nil
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		if err = tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: RoleOwner}).Error
+	})
+
+	if err != nil {
+		logger.Printf("could not create workspace: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWorkspaceResponse{Success: true, WorkspaceID: workspace.ID})
+}
+
+// DetailWorkspace returns the workspace with its members.
+func (s *API) DetailWorkspace(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace, ok := s.userWorkspace(c, user, RoleMember)
+	if !ok {
+		return
+	}
+
+	if err := s.db.Preload("User").Where("workspace_id = ?", workspace.ID).Order("created_at").Find(&workspace.Members).Error; err != nil {
+		logger.Printf("could not fetch workspace members: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	for i := range workspace.Members {
+		workspace.Members[i].Username = workspace.Members[i].User.Username
+	}
+
+	c.JSON(http.StatusOK, DetailWorkspaceResponse{Success: true, Workspace: workspace})
+}
+
+// UpdateWorkspace renames the workspace. Only owners of the workspace can update it.
+func (s *API) UpdateWorkspace(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace, ok := s.userWorkspace(c, user, RoleOwner)
+	if !ok {
+		return
+	}
+
+	// Parse user input, only the name of the workspace can be modified
+	patch, err := bindMergePatch(c)
+	if err != nil {
+		if err == errMergePatchType {
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	var values map[string]interface{}
+	if values, err = workspacePatch.Parse(patch); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if len(values) > 0 {
+		if err = s.db.Model(&workspace).Update(values).Error; err != nil {
+			logger.Printf("could not update workspace: %s", err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, UpdateWorkspaceResponse{Success: true})
+}
+
+// DeleteWorkspace removes the workspace and its memberships. Only owners can delete the
+// workspace and only once its tasks and checklists have been deleted, so that the work
+// of the team is not lost by accident; the trash of the workspace is emptied with it.
+func (s *API) DeleteWorkspace(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace, ok := s.userWorkspace(c, user, RoleOwner)
+	if !ok {
+		return
+	}
+
+	var blobs []string
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		for _, model := range []interface{}{&Task{}, &Checklist{}} {
+			var count int
+			if err = tx.Model(model).Where("workspace_id = ?", workspace.ID).Count(&count).Error; err != nil {
+				logger.Printf("could not count workspace contents: %s", err)
+				return errInternal
+			}
+
+			if count > 0 {
+				return errWorkspaceNotEmpty
+			}
+		}
+
+		if _, _, blobs, err = purgeTrash(tx, func(db *gorm.DB, table string) *gorm.DB {
+			return inWorkspace(db, table, &workspace.ID)
+		}); err != nil {
+			logger.Printf("could not empty workspace trash: %s", err)
+			return errInternal
+		}
+
+		// Projects only group the checklists of the workspace, so they are deleted with it
+		if err = tx.Where("workspace_id = ?", workspace.ID).Delete(&Project{}).Error; err != nil {
+			logger.Printf("could not delete workspace projects: %s", err)
+			return errInternal
+		}
+
+		// Tokens that select the workspace return to the personal space of their user
+		if err = tx.Model(&Token{}).Where("workspace_id = ?", workspace.ID).Update("workspace_id", nil).Error; err != nil {
+			logger.Printf("could not reset token workspaces: %s", err)
+			return errInternal
+		}
+
+		if err = tx.Where("workspace_id = ?", workspace.ID).Delete(&WorkspaceMember{}).Error; err != nil {
+			logger.Printf("could not delete workspace members: %s", err)
+			return errInternal
+		}
+
+		if err = tx.Delete(&workspace).Error; err != nil {
+			logger.Printf("could not delete workspace: %s", err)
+			return errInternal
+		}
+		return nil
+	})
+
+	if err != nil {
+		if err == errInternal {
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	s.deleteBlobs(blobs...)
+	c.JSON(http.StatusOK, DeleteWorkspaceResponse{Success: true})
+}
+
+// AddMember adds a user to the workspace as an owner or member. If the user is already
+// a member of the workspace, their role is changed. Only owners can manage members.
+func (s *API) AddMember(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	workspace, ok := s.userWorkspace(c, user, RoleOwner)
+	if !ok {
+		return
+	}
+
+	var req AddMemberRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if req.Role != RoleOwner && req.Role != RoleMember {
+		c.JSON(http.StatusBadRequest, ErrorResponse(errInvalidMemberRole))
+		return
+	}
+
+	var member User
+	if err := s.db.Where("username = ?", req.Username).First(&member).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusBadRequest, ErrorResponse(fmt.Errorf("user %q does not exist", req.Username)))
+			return
+		}
+		logger.Printf("could not find user: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		membership := WorkspaceMember{WorkspaceID: workspace.ID, UserID: member.ID}
+		if err = tx.Where(membership).Assign(WorkspaceMember{Role: req.Role}).FirstOrCreate(&membership).Error; err != nil {
+			logger.Printf("could not add workspace member: %s", err)
+			return errInternal
+		}
+		return requireWorkspaceOwner(tx, workspace.ID)
+	})
+
+	if err != nil {
+		if err == errInternal {
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, AddMemberResponse{Success: true})
+}
+
+// RemoveMember removes the user in the URL from the workspace. Owners can remove any
+// member and members can remove themselves to leave the workspace, but the last owner
+// of a workspace cannot be removed.
+func (s *API) RemoveMember(c *gin.Context) {
+	user := c.Value(ctxUserKey).(User)
+	required := RoleOwner
+	if c.Param("username") == user.Username {
+		required = RoleMember
+	}
+
+	workspace, ok := s.userWorkspace(c, user, required)
+	if !ok {
+		return
+	}
+
+	var removed int64
+	err := s.db.Transaction(func(tx *gorm.DB) (err error) {
+		users := tx.Table("users").Select("id").Where("username = ?", c.Param("username"))
+		query := tx.Where("workspace_id = ? AND user_id IN ?", workspace.ID, users.SubQuery()).Delete(&WorkspaceMember{})
+		if err = query.Error; err != nil {
+			logger.Printf("could not remove workspace member: %s", err)
+			return errInternal
+		}
+
+		if removed = query.RowsAffected; removed == 0 {
+			return nil
+		}
+		return requireWorkspaceOwner(tx, workspace.ID)
+	})
+
+	if err != nil {
+		if err == errInternal {
+			c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse(err))
+		return
+	}
+
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, notFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, RemoveMemberResponse{Success: true})
+}
+
+//===========================================================================
+// Workspace Helpers
+//===========================================================================
+
+// userWorkspace fetches the workspace specified by the id url parameter if the user has
+// at least the required role in it. Admin users can manage any workspace. If the
+// workspace cannot be found or the user is not a member, the error response is
+// written and false is returned.
+func (s *API) userWorkspace(c *gin.Context, user User, required string) (workspace Workspace, ok bool) {
+	if err := s.db.Where("id = ?", c.Param("id")).First(&workspace).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, notFound)
+			return workspace, false
+		}
+		logger.Printf("could not find workspace: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return workspace, false
+	}
+
+	var err error
+	if workspace.Role, err = workspaceRole(s.db, user.ID, workspace.ID); err != nil {
+		logger.Printf("could not check workspace role: %s", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse(nil))
+		return workspace, false
+	}
+
+	if user.IsAdmin {
+		return workspace, true
+	}
+
+	switch {
+	case workspace.Role == "":
+		c.JSON(http.StatusNotFound, notFound)
+		return workspace, false
+	case required == RoleOwner && workspace.Role != RoleOwner:
+		c.JSON(http.StatusForbidden, ErrorResponse(errForbidden))
+		return workspace, false
+	}
+	return workspace, true
+}
+
+// workspaceRole returns the role of the user in the workspace or an empty string if
+// the user is not a member of the workspace.
+func workspaceRole(db *gorm.DB, userID, workspaceID uint) (_ string, err error) {
+	var member WorkspaceMember
+	if err = db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// requireWorkspaceOwner ensures that the workspace still has an owner after its members
+// have been modified so that it can continue to be managed.
+func requireWorkspaceOwner(db *gorm.DB, workspaceID uint) (err error) {
+	var count int
+	if err = db.Model(&WorkspaceMember{}).Where("workspace_id = ? AND role = ?", workspaceID, RoleOwner).Count(&count).Error; err != nil {
+		logger.Printf("could not count workspace owners: %s", err)
+		return errInternal
+	}
+
+	if count == 0 {
+		return errLastWorkspaceOwner
+	}
+	return nil
+}
+
+// selectWorkspace returns the active workspace of the request, which is specified by
+// the workspace header or otherwise by the token; nil is the personal space of the
+// user. The user must be a member of the workspace unless they are an admin.
+func selectWorkspace(c *gin.Context, db *gorm.DB, user User, token Token) (workspace *uint, err error) {
+	workspace = token.WorkspaceID
+	if header := c.GetHeader(WorkspaceHeader); header != "" {
+		var id uint64
+		if id, err = strconv.ParseUint(header, 10, 64); err != nil {
+			return nil, errInvalidWorkspace
+		}
+
+		workspace = nil
+		if id > 0 {
+			wid := uint(id)
+			workspace = &wid
+		}
+	}
+
+	if workspace == nil {
+		return nil, nil
+	}
+
+	if err = checkMember(db, user, *workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// checkMember returns errNotMember if the user is not a member of the workspace. Admin
+// users can work in any workspace that exists.
+func checkMember(db *gorm.DB, user User, workspaceID uint) (err error) {
+	query := db.Model(&Workspace{}).Where("id = ?", workspaceID)
+	if !user.IsAdmin {
+		members := db.Table("workspace_members").Select("workspace_id").Where("user_id = ?", user.ID)
+		query = query.Where("id IN ?", members.SubQuery())
+	}
+
+	var count int
+	if err = query.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errNotMember
+	}
+	return nil
+}
+
+// activeWorkspace returns the workspace selected by the authorize middleware or nil if
+// the request is in the personal space of the user.
+func activeWorkspace(c *gin.Context) *uint {
+	if val, ok := c.Get(ctxWorkspaceKey); ok {
+		return val.(*uint)
+	}
+	return nil
+}
+
+// inWorkspace filters the query to the rows of the table that are in the workspace or
+// in the personal space of their users if the workspace is nil.
+func inWorkspace(db *gorm.DB, table string, workspace *uint) *gorm.DB {
+	if workspace == nil {
+		return db.Where(table + ".workspace_id IS NULL")
+	}
+	return db.Where(table+".workspace_id = ?", *workspace)
+}
+
+// sameWorkspace returns true if both ids refer to the same workspace or both are nil.
+func sameWorkspace(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
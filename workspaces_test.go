@@ -0,0 +1,146 @@
+package todos_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/bbengfort/todos"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *TodosTestSuite) TestWorkspaces() {
+	var created CreateWorkspaceResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/workspaces", Workspace{Name: "acme"}, &created))
+	workspacePath := fmt.Sprintf("/v1/workspaces/%d", created.WorkspaceID)
+
+	var workspaces ListWorkspacesResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/workspaces", nil, &workspaces))
+	require.Len(s.T(), workspaces.Workspaces, 1)
+	require.Equal(s.T(), RoleOwner, workspaces.Workspaces[0].Role)
+
+	// Only members can view the workspace or select it
+	require.Equal(s.T(), http.StatusNotFound, s.DoAs(true, http.MethodGet, workspacePath, nil, nil))
+	s.workspace = fmt.Sprint(created.WorkspaceID)
+	defer func() { s.workspace = "" }()
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodGet, "/v1/", nil, nil))
+
+	// Tasks and checklists are created in the active workspace
+	var list CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "roadmap"}, &list))
+
+	var task CreateTaskResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/tasks", Task{Title: "launch", ChecklistID: &list.ChecklistID}, &task))
+
+	var project CreateProjectResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/projects", Project{Title: "q3 goals"}, &project))
+	projectPath := fmt.Sprintf("/v1/projects/%d", project.ProjectID)
+
+	listPath := fmt.Sprintf("/v1/lists/%d", list.ChecklistID)
+	taskPath := fmt.Sprintf("/v1/tasks/%d", task.TaskID)
+
+	var overview OverviewResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/", nil, &overview))
+	require.Equal(s.T(), "acme", overview.Workspace)
+	require.Equal(s.T(), 1, overview.Tasks)
+	require.Equal(s.T(), 1, overview.Checklists)
+
+	var tasks ListTasksResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks", nil, &tasks))
+	require.Equal(s.T(), []uint{task.TaskID}, taskIDs(tasks.Tasks))
+
+	// The tasks and checklists of the workspace are not in the personal space
+	s.workspace = "0"
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, taskPath, nil, nil))
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, listPath, nil, nil))
+
+	tasks = ListTasksResponse{}
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/tasks", nil, &tasks))
+	require.NotContains(s.T(), taskIDs(tasks.Tasks), task.TaskID)
+
+	// Projects are also scoped to the workspace and only group its checklists
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, projectPath, nil, nil))
+
+	var projects ListProjectsResponse
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodGet, "/v1/projects", nil, &projects))
+	for _, p := range projects.Projects {
+		require.NotEqual(s.T(), project.ProjectID, p.ID)
+	}
+
+	var personal CreateChecklistResponse
+	require.Equal(s.T(), http.StatusCreated, s.Do(http.MethodPost, "/v1/lists", Checklist{Title: "errands"}, &personal))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, fmt.Sprintf("/v1/lists/%d", personal.ChecklistID), map[string]interface{}{"project": project.ProjectID}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, fmt.Sprintf("/v1/lists/%d", personal.ChecklistID), nil, nil))
+
+	// Checklists of the workspace can be added to its projects
+	s.workspace = fmt.Sprint(created.WorkspaceID)
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, listPath, map[string]interface{}{"project": project.ProjectID}, nil))
+
+	// Checklists can only be shared with members of the workspace
+	share := ShareChecklistRequest{Username: "admin", Role: RoleViewer}
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, listPath+"/shares", share, nil))
+
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, workspacePath+"/members", AddMemberRequest{Username: "admin", Role: "admin"}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, workspacePath+"/members", AddMemberRequest{Username: "admin", Role: RoleMember}, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, listPath+"/shares", share, nil))
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, listPath, nil, nil))
+
+	var detail DetailWorkspaceResponse
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, workspacePath, nil, &detail))
+	require.Equal(s.T(), RoleMember, detail.Workspace.Role)
+	require.Len(s.T(), detail.Workspace.Members, 2)
+
+	// Members cannot manage the workspace and the last owner cannot be removed
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodPut, workspacePath, map[string]interface{}{"name": "mine"}, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodDelete, workspacePath+"/members/jane", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodDelete, workspacePath+"/members/jane", nil, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, workspacePath+"/members", AddMemberRequest{Username: "jane", Role: RoleMember}, nil))
+
+	// Owners can only rename the workspace
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodPut, workspacePath, map[string]interface{}{"name": "launch team"}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, workspacePath, map[string]interface{}{"created_at": "2000-01-01T00:00:00Z", "owner_id": 2}, nil))
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodPut, workspacePath, map[string]interface{}{"name": ""}, nil))
+
+	// Members can leave the workspace, after which they can no longer select it
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodDelete, workspacePath+"/members/admin", nil, nil))
+	require.Equal(s.T(), http.StatusForbidden, s.DoAs(true, http.MethodGet, listPath, nil, nil))
+
+	// Admin users are global server admins and can work in any workspace
+	db := s.api.DB()
+	require.NoError(s.T(), db.Model(&User{}).Where("username = ?", "admin").Update("is_admin", true).Error)
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, "/v1/", nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.DoAs(true, http.MethodGet, workspacePath, nil, nil))
+	require.NoError(s.T(), db.Model(&User{}).Where("username = ?", "admin").Update("is_admin", false).Error)
+
+	// The workspace can be selected by the token when logging in
+	s.workspace = ""
+	data, err := json.Marshal(map[string]interface{}{"username": "jane", "password": "specialsnowflake", "workspace": created.WorkspaceID, "no_cookie": true})
+	require.NoError(s.T(), err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/v1/login", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	s.router.ServeHTTP(w, req)
+	require.Equal(s.T(), http.StatusOK, w.Code)
+
+	var tokens LoginResponse
+	require.NoError(s.T(), json.NewDecoder(w.Result().Body).Decode(&tokens))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, taskPath, nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	s.router.ServeHTTP(w, req)
+	require.Equal(s.T(), http.StatusOK, w.Code)
+
+	// Workspaces cannot be deleted until their tasks and checklists are deleted
+	s.workspace = fmt.Sprint(created.WorkspaceID)
+	require.Equal(s.T(), http.StatusBadRequest, s.Do(http.MethodDelete, workspacePath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, taskPath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, listPath, nil, nil))
+	require.Equal(s.T(), http.StatusOK, s.Do(http.MethodDelete, workspacePath, nil, nil))
+
+	s.workspace = ""
+	require.Equal(s.T(), http.StatusNotFound, s.Do(http.MethodGet, workspacePath, nil, nil))
+}